@@ -0,0 +1,140 @@
+package rv64
+
+import (
+	"sort"
+)
+
+// Device is the interface implemented by memory-mapped peripherals. The offset is relative to the base address the
+// device is attached at, the size is 1, 2, 4 or 8 bytes and values are little-endian.
+type Device interface {
+	Read(offset uint64, size uint64) (uint64, error)
+	Write(offset uint64, size uint64, value uint64) error
+}
+
+// Ticker is optionally implemented by devices whose state advances with the execution of the CPU. Tick is called
+// after every instruction with the number of cycles it took.
+type Ticker interface {
+	Tick(n uint64)
+}
+
+// Ram adapts a Fasten to the Device interface, so it can be attached to a bus as a RAM region.
+type Ram struct {
+	fasten Fasten
+}
+
+func (r *Ram) Read(offset uint64, size uint64) (uint64, error) {
+	return fastenGet(r.fasten, offset, size)
+}
+
+func (r *Ram) Write(offset uint64, size uint64, value uint64) error {
+	return fastenSet(r.fasten, offset, size, value)
+}
+
+func NewRam(f Fasten) *Ram {
+	return &Ram{fasten: f}
+}
+
+type busRegion struct {
+	base   uint64
+	size   uint64
+	device Device
+}
+
+// Bus is a Fasten that routes every address to the device attached at the range it falls in. Accesses to an address
+// where nothing is attached fail with ErrOutOfMemory.
+type Bus struct {
+	regions []busRegion
+	ram     uint64
+}
+
+// Attach maps a device at [base, base+size). It fails with ErrOverlappingDevice if the range overlaps the range of a
+// device attached earlier.
+func (b *Bus) Attach(base uint64, size uint64, d Device) error {
+	if size == 0 || base+size < base {
+		return ErrOverlappingDevice
+	}
+	for _, r := range b.regions {
+		if base < r.base+r.size && r.base < base+size {
+			return ErrOverlappingDevice
+		}
+	}
+	b.regions = append(b.regions, busRegion{base: base, size: size, device: d})
+	sort.Slice(b.regions, func(i, j int) bool { return b.regions[i].base < b.regions[j].base })
+	return nil
+}
+
+// AttachMemory maps a RAM region of f.Len() bytes at base.
+func (b *Bus) AttachMemory(base uint64, f Fasten) error {
+	if err := b.Attach(base, f.Len(), NewRam(f)); err != nil {
+		return err
+	}
+	if base+f.Len() > b.ram {
+		b.ram = base + f.Len()
+	}
+	return nil
+}
+
+func (b *Bus) find(a uint64, l uint64) (*busRegion, error) {
+	i := sort.Search(len(b.regions), func(i int) bool { return b.regions[i].base+b.regions[i].size > a })
+	if i == len(b.regions) {
+		return nil, ErrOutOfMemory
+	}
+	r := &b.regions[i]
+	if a < r.base || a+l > r.base+r.size {
+		return nil, ErrOutOfMemory
+	}
+	return r, nil
+}
+
+func (b *Bus) GetSized(a uint64, l uint64) (uint64, error) {
+	r, err := b.find(a, l)
+	if err != nil {
+		return 0, err
+	}
+	return r.device.Read(a-r.base, l)
+}
+
+func (b *Bus) SetSized(a uint64, l uint64, v uint64) error {
+	r, err := b.find(a, l)
+	if err != nil {
+		return err
+	}
+	return r.device.Write(a-r.base, l, v)
+}
+
+func (b *Bus) Get(a uint64) (byte, error) {
+	v, err := b.GetSized(a, 1)
+	return byte(v), err
+}
+
+func (b *Bus) Set(a uint64, v byte) error {
+	return b.SetSized(a, 1, uint64(v))
+}
+
+// cacheable reports whether a RAM region is attached at a, the registers of the other devices are not cached.
+func (b *Bus) cacheable(a uint64) bool {
+	r, err := b.find(a, 1)
+	if err != nil {
+		return false
+	}
+	_, ok := r.device.(*Ram)
+	return ok
+}
+
+// Len returns the end address of the highest RAM region.
+func (b *Bus) Len() uint64 {
+	return b.ram
+}
+
+// Tick ticks every attached device implementing Ticker.
+func (b *Bus) Tick(n uint64) {
+	for _, r := range b.regions {
+		if t, ok := r.device.(Ticker); ok {
+			t.Tick(n)
+		}
+	}
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
@@ -0,0 +1,86 @@
+package rv64
+
+import (
+	"testing"
+)
+
+type busTestDevice struct {
+	offset uint64
+	size   uint64
+	value  uint64
+	ticks  uint64
+}
+
+func (d *busTestDevice) Read(offset uint64, size uint64) (uint64, error) {
+	d.offset = offset
+	d.size = size
+	return d.value, nil
+}
+
+func (d *busTestDevice) Write(offset uint64, size uint64, value uint64) error {
+	d.offset = offset
+	d.size = size
+	d.value = value
+	return nil
+}
+
+func (d *busTestDevice) Tick(n uint64) {
+	d.ticks += n
+}
+
+func TestBusOverlap(t *testing.T) {
+	bus := NewBus()
+	if bus.AttachMemory(0x1000, NewLinear(0x1000)) != nil {
+		t.FailNow()
+	}
+	if bus.Attach(0x3000, 0x1000, &busTestDevice{}) != nil {
+		t.FailNow()
+	}
+	for _, e := range [][]uint64{{0x0000, 0x1001}, {0x1fff, 0x1}, {0x2fff, 0x2}, {0x3800, 0x10}, {0x0, 0x10000}} {
+		if bus.Attach(e[0], e[1], &busTestDevice{}) != ErrOverlappingDevice {
+			t.Fatal(e)
+		}
+	}
+	if bus.Attach(0x2000, 0x1000, &busTestDevice{}) != nil {
+		t.FailNow()
+	}
+	if bus.Attach(0xfffffffffffff000, 0x2000, &busTestDevice{}) != ErrOverlappingDevice {
+		t.FailNow()
+	}
+}
+
+func TestBusRoute(t *testing.T) {
+	bus := NewBus()
+	bus.AttachMemory(0x0000, NewLinear(0x1000))
+	d := &busTestDevice{}
+	bus.Attach(0x10000000, 0x100, d)
+	mem := &Memory{Fasten: bus}
+	mem.SetUint32(0x10000010, 0x12345678)
+	if d.offset != 0x10 || d.size != 4 || d.value != 0x12345678 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint16(0x10000020); v != 0x5678 || d.offset != 0x20 || d.size != 2 {
+		t.FailNow()
+	}
+	mem.SetUint64(0x0ff8, 0x0102030405060708)
+	if v, _ := mem.GetUint8(0x0ff9); v != 0x07 {
+		t.FailNow()
+	}
+	if bus.Len() != 0x1000 {
+		t.FailNow()
+	}
+	// Unmapped addresses and accesses crossing the end of a region.
+	if _, err := mem.GetUint8(0x1000); err != ErrOutOfMemory {
+		t.FailNow()
+	}
+	if _, err := mem.GetUint32(0x0ffe); err != ErrOutOfMemory {
+		t.FailNow()
+	}
+	if _, err := mem.GetUint32(0x100000fe); err != ErrOutOfMemory {
+		t.FailNow()
+	}
+	bus.Tick(3)
+	if d.ticks != 3 {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,387 @@
+package rv64
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CacheStats counts the accesses to a cache.
+type CacheStats struct {
+	Hits       uint64
+	Misses     uint64
+	Writebacks uint64 // Dirty lines evicted to the next level
+}
+
+// Cache is a set-associative cache level. Its lines are looked up by physical address, a miss fetches the line from
+// the next level. A write-back cache allocates a line on a write miss and writes dirty lines back when they are
+// evicted, a write-through cache forwards every write to the next level and does not allocate on a write miss.
+type Cache struct {
+	Name        string
+	Size        uint64 // Capacity in bytes
+	Ways        uint64 // Associativity
+	Line        uint64 // Line size in bytes, a power of two
+	Policy      uint64 // One of the CacheReplace constants
+	WriteBack   bool
+	MissPenalty uint64 // Cycles spent on a miss, on top of the penalty of the next level
+	Next        *Cache // Next level, nil for the memory
+
+	Stats   CacheStats
+	Symbols map[string]*CacheStats // Accesses by the function executing them
+
+	sets [][]cacheLine
+	tick uint64
+	seed uint64
+}
+
+type cacheLine struct {
+	addr  uint64
+	valid bool
+	dirty bool
+	used  uint64 // Time of the last access for LRU, of the allocation for FIFO
+}
+
+// NewCache returns a cache using LRU replacement. It fails with ErrCacheGeometry unless the line size and the number
+// of sets, size / ways / line, are powers of two.
+func NewCache(name string, size uint64, ways uint64, line uint64, writeBack bool, missPenalty uint64) (*Cache, error) {
+	c := &Cache{
+		Name:        name,
+		Size:        size,
+		Ways:        ways,
+		Line:        line,
+		Policy:      CacheReplaceLRU,
+		WriteBack:   writeBack,
+		MissPenalty: missPenalty,
+	}
+	return c, c.check()
+}
+
+// check validates the geometry of the cache.
+func (c *Cache) check() error {
+	if c.Line == 0 || c.Line&(c.Line-1) != 0 || c.Ways == 0 || c.Size%(c.Ways*c.Line) != 0 {
+		return ErrCacheGeometry
+	}
+	if n := c.Size / c.Ways / c.Line; n == 0 || n&(n-1) != 0 {
+		return ErrCacheGeometry
+	}
+	if c.Policy > CacheReplaceRandom {
+		return ErrCacheGeometry
+	}
+	return nil
+}
+
+// clone returns an empty cache with the same configuration and next level.
+func (c *Cache) clone() *Cache {
+	if c == nil {
+		return nil
+	}
+	return &Cache{
+		Name:        c.Name,
+		Size:        c.Size,
+		Ways:        c.Ways,
+		Line:        c.Line,
+		Policy:      c.Policy,
+		WriteBack:   c.WriteBack,
+		MissPenalty: c.MissPenalty,
+		Next:        c.Next,
+	}
+}
+
+func (c *Cache) stats(fn string) *CacheStats {
+	if c.Symbols == nil {
+		c.Symbols = map[string]*CacheStats{}
+	}
+	s := c.Symbols[fn]
+	if s == nil {
+		s = &CacheStats{}
+		c.Symbols[fn] = s
+	}
+	return s
+}
+
+// victim returns the line of the set to replace.
+func (c *Cache) victim(set []cacheLine) *cacheLine {
+	for i := range set {
+		if !set[i].valid {
+			return &set[i]
+		}
+	}
+	if c.Policy == CacheReplaceRandom {
+		// A xorshift generator, so that the runs are reproducible.
+		c.seed ^= c.seed << 13
+		c.seed ^= c.seed >> 7
+		c.seed ^= c.seed << 17
+		return &set[c.seed%uint64(len(set))]
+	}
+	r := &set[0]
+	for i := range set {
+		if set[i].used < r.used {
+			r = &set[i]
+		}
+	}
+	return r
+}
+
+// access reads or writes the line holding a on behalf of the function fn, and returns the cycles spent.
+func (c *Cache) access(a uint64, write bool, fn string) uint64 {
+	if c.sets == nil {
+		c.sets = make([][]cacheLine, c.Size/c.Line/c.Ways)
+		for i := range c.sets {
+			c.sets[i] = make([]cacheLine, c.Ways)
+		}
+		c.seed = 0x9e3779b97f4a7c15
+	}
+	c.tick++
+	a &^= c.Line - 1
+	set := c.sets[a/c.Line%uint64(len(c.sets))]
+	s := c.stats(fn)
+	for i := range set {
+		e := &set[i]
+		if !e.valid || e.addr != a {
+			continue
+		}
+		c.Stats.Hits++
+		s.Hits++
+		if c.Policy == CacheReplaceLRU {
+			e.used = c.tick
+		}
+		if write && c.WriteBack {
+			e.dirty = true
+		}
+		if write && !c.WriteBack && c.Next != nil {
+			return c.Next.access(a, true, fn)
+		}
+		return 0
+	}
+	c.Stats.Misses++
+	s.Misses++
+	r := c.MissPenalty
+	if write && !c.WriteBack {
+		if c.Next != nil {
+			r += c.Next.access(a, true, fn)
+		}
+		return r
+	}
+	if c.Next != nil {
+		r += c.Next.access(a, false, fn)
+	}
+	e := c.victim(set)
+	if e.valid && e.dirty {
+		c.Stats.Writebacks++
+		s.Writebacks++
+		if c.Next != nil {
+			r += c.Next.access(e.addr, true, fn)
+		}
+	}
+	*e = cacheLine{addr: a, valid: true, dirty: write, used: c.tick}
+	return r
+}
+
+// manage applies a cache-block management operation to the line holding a, in the cache and its next levels, and
+// returns the cycles spent. A clean writes the line back if it is dirty, an invalidation drops it.
+func (c *Cache) manage(a uint64, clean bool, inval bool, fn string) uint64 {
+	var r uint64
+	if c.sets != nil {
+		a &^= c.Line - 1
+		set := c.sets[a/c.Line%uint64(len(c.sets))]
+		for i := range set {
+			e := &set[i]
+			if !e.valid || e.addr != a {
+				continue
+			}
+			if clean && e.dirty {
+				c.Stats.Writebacks++
+				c.stats(fn).Writebacks++
+				e.dirty = false
+				if c.Next != nil {
+					r += c.Next.access(a, true, fn)
+				}
+			}
+			if inval {
+				*e = cacheLine{}
+			}
+		}
+	}
+	if c.Next != nil {
+		r += c.Next.manage(a, clean, inval, fn)
+	}
+	return r
+}
+
+// Symbol is a function of the program, covering the addresses [Addr, Addr+Size).
+type Symbol struct {
+	Name string
+	Addr uint64
+	Size uint64
+}
+
+// Caches is the cache hierarchy of a CPU. Instruction fetches go to I and loads and stores to D, which usually share
+// their next level. Page table walks and the registers of the devices attached to a Bus bypass the caches. Accesses
+// are attributed to the function of Symbols holding the pc, or to the empty name.
+//
+// The I and D caches are private to a hart, the harts cloned by a Machine and the forks of a CPU start with empty
+// copies of them, and share the next levels.
+type Caches struct {
+	I       *Cache
+	D       *Cache
+	Symbols []Symbol
+	// Cycles adds the miss penalties to the cycles spent by the instructions. They are only known once an instruction
+	// executed, so they may carry the cycle counter past the cycle limit.
+	Cycles bool
+
+	penalty uint64
+}
+
+// NewCaches returns split 16 KiB 4-way L1 caches sharing a 256 KiB 8-way L2, all with 64-byte lines. The L1 data
+// cache and the L2 are write-back. An L1 miss costs 10 cycles, an L2 miss 100 more.
+func NewCaches() *Caches {
+	l2, _ := NewCache("L2", 256*1024, 8, 64, true, 100)
+	i, _ := NewCache("L1I", 16*1024, 4, 64, false, 10)
+	d, _ := NewCache("L1D", 16*1024, 4, 64, true, 10)
+	i.Next = l2
+	d.Next = l2
+	return &Caches{I: i, D: d}
+}
+
+// check validates the geometry of every level.
+func (h *Caches) check() error {
+	for _, c := range []*Cache{h.I, h.D} {
+		for ; c != nil; c = c.Next {
+			if err := c.check(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// clone returns caches with empty copies of the I and D caches, sharing the next levels.
+func (h *Caches) clone() *Caches {
+	n := &Caches{I: h.I.clone(), D: h.D.clone(), Symbols: h.Symbols, Cycles: h.Cycles}
+	if h.D == h.I {
+		n.D = n.I
+	}
+	return n
+}
+
+// SetSymbols sets the functions accesses are attributed to.
+func (h *Caches) SetSymbols(s []Symbol) {
+	h.Symbols = append([]Symbol{}, s...)
+	sort.Slice(h.Symbols, func(i, j int) bool { return h.Symbols[i].Addr < h.Symbols[j].Addr })
+}
+
+func (h *Caches) symbol(pc uint64) string {
+	i := sort.Search(len(h.Symbols), func(i int) bool { return h.Symbols[i].Addr > pc }) - 1
+	if i < 0 || pc >= h.Symbols[i].Addr+h.Symbols[i].Size {
+		return ""
+	}
+	return h.Symbols[i].Name
+}
+
+// observe accesses the l bytes at a in the cache c, line by line.
+func (h *Caches) observe(c *Cache, pc uint64, a uint64, l uint64, write bool) {
+	if c == nil || l == 0 {
+		return
+	}
+	fn := h.symbol(pc)
+	for e := a &^ (c.Line - 1); e < a+l; e += c.Line {
+		h.penalty += c.access(e, write, fn)
+	}
+}
+
+// manage applies a cache-block management operation to the n bytes at the physical address a.
+func (h *Caches) manage(pc uint64, a uint64, n uint64, clean bool, inval bool) {
+	c := h.D
+	if c == nil {
+		return
+	}
+	fn := h.symbol(pc)
+	for e := a &^ (c.Line - 1); e < a+n; e += c.Line {
+		h.penalty += c.manage(e, clean, inval, fn)
+	}
+}
+
+// take returns the penalties accumulated since the last call, if they count.
+func (h *Caches) take() uint64 {
+	r := h.penalty
+	h.penalty = 0
+	if !h.Cycles {
+		return 0
+	}
+	return r
+}
+
+// Report writes the statistics of every level, and of the functions by decreasing number of misses.
+func (h *Caches) Report(w io.Writer) {
+	seen := map[*Cache]bool{}
+	levels := []*Cache{h.I, h.D}
+	for i := 0; i < len(levels); i++ {
+		c := levels[i]
+		if c == nil || seen[c] {
+			continue
+		}
+		seen[c] = true
+		levels = append(levels, c.Next)
+		fmt.Fprintf(w, "%s: hits %d misses %d writebacks %d\n", c.Name, c.Stats.Hits, c.Stats.Misses, c.Stats.Writebacks)
+		names := []string{}
+		for k := range c.Symbols {
+			names = append(names, k)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			a, b := c.Symbols[names[i]], c.Symbols[names[j]]
+			if a.Misses != b.Misses {
+				return a.Misses > b.Misses
+			}
+			return names[i] < names[j]
+		})
+		for _, k := range names {
+			s := c.Symbols[k]
+			fmt.Fprintf(w, "    %-24s hits %d misses %d writebacks %d\n", k, s.Hits, s.Misses, s.Writebacks)
+		}
+	}
+}
+
+// cacheable is implemented by a Fasten mapping devices, it reports whether the address a is memory.
+type cacheable interface {
+	cacheable(a uint64) bool
+}
+
+// fastenCacheable reports whether the address a of f goes through the caches.
+func fastenCacheable(f Fasten, a uint64) bool {
+	if m, ok := f.(cacheable); ok {
+		return m.cacheable(a)
+	}
+	return true
+}
+
+// cacheFasten is the physical memory seen through the data cache of a CPU.
+type cacheFasten struct {
+	Fasten
+	cpu *CPU
+}
+
+func (f *cacheFasten) observe(a uint64, l uint64, write bool) {
+	if fastenCacheable(f.Fasten, a) {
+		f.cpu.caches.observe(f.cpu.caches.D, f.cpu.GetPC(), a, l, write)
+	}
+}
+
+func (f *cacheFasten) Get(a uint64) (byte, error) {
+	f.observe(a, 1, false)
+	return f.Fasten.Get(a)
+}
+
+func (f *cacheFasten) Set(a uint64, v byte) error {
+	f.observe(a, 1, true)
+	return f.Fasten.Set(a, v)
+}
+
+func (f *cacheFasten) GetSized(a uint64, l uint64) (uint64, error) {
+	f.observe(a, l, false)
+	return fastenGet(f.Fasten, a, l)
+}
+
+func (f *cacheFasten) SetSized(a uint64, l uint64, v uint64) error {
+	f.observe(a, l, true)
+	return fastenSet(f.Fasten, a, l, v)
+}
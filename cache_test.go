@@ -0,0 +1,186 @@
+package rv64
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCacheReplace(t *testing.T) {
+	for _, e := range []struct {
+		policy uint64
+		hits   uint64
+	}{
+		{CacheReplaceLRU, 1},
+		{CacheReplaceFIFO, 2},
+	} {
+		// Two ways of 4 sets, 0x00, 0x40 and 0x80 share the first set.
+		c, _ := NewCache("L1", 128, 2, 16, true, 1)
+		c.Policy = e.policy
+		for _, a := range []uint64{0x00, 0x40, 0x00, 0x80, 0x40, 0x00} {
+			c.access(a, false, "")
+		}
+		if c.Stats.Hits != e.hits || c.Stats.Misses != 6-e.hits {
+			t.Fatal(c.Stats)
+		}
+	}
+}
+
+func TestCacheWrite(t *testing.T) {
+	// A write-back cache allocates on a write miss, and writes the dirty line back on eviction.
+	l2, _ := NewCache("L2", 1024, 4, 16, true, 100)
+	l1, _ := NewCache("L1", 32, 1, 16, true, 10)
+	l1.Next = l2
+	if n := l1.access(0x00, true, ""); n != 110 {
+		t.Fatal(n)
+	}
+	if n := l1.access(0x04, true, ""); n != 0 {
+		t.Fatal(n)
+	}
+	l1.access(0x20, false, "")
+	if l1.Stats.Writebacks != 1 || l2.Stats.Hits != 1 || l2.Stats.Misses != 2 {
+		t.Fatal(l1.Stats, l2.Stats)
+	}
+	// A write-through cache forwards the writes, and does not allocate on a write miss.
+	l2, _ = NewCache("L2", 1024, 4, 16, true, 100)
+	l1, _ = NewCache("L1", 32, 1, 16, false, 10)
+	l1.Next = l2
+	l1.access(0x00, true, "")
+	l1.access(0x00, false, "")
+	l1.access(0x00, true, "")
+	if l1.Stats.Hits != 1 || l1.Stats.Misses != 2 || l1.Stats.Writebacks != 0 || l2.Stats.Hits != 2 {
+		t.Fatal(l1.Stats, l2.Stats)
+	}
+}
+
+func TestCaches(t *testing.T) {
+	c := newCostCPU([]uint64{
+		encodeI(0b0000011, 0b010, 0, Rt2, Ra1),     // 0x00 lw t2, 0(a1)
+		addi(Ra1, Ra1, 4),                          // 0x04 addi a1, a1, 4
+		addi(Rt1, Rt1, 0xfff),                      // 0x08 addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -12),            // 0x0c bnez t1, 0x00
+		addi(Ra7, Rzero, sysExitGroup),             // 0x10 li a7, exit_group
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero), // 0x14 ecall
+	}, []uint64{4, 4, 4, 4, 4, 4})
+	c.SetRegister(Rt1, 16)
+	h := NewCaches()
+	h.Cycles = true
+	h.SetSymbols([]Symbol{{Name: "main", Addr: 0x00, Size: 0x10}})
+	c.SetCaches(h)
+	c.Run()
+	if h.D.Stats.Hits != 15 || h.D.Stats.Misses != 1 {
+		t.Fatal(h.D.Stats)
+	}
+	if s := h.I.Symbols["main"]; s.Hits != 63 || s.Misses != 1 {
+		t.Fatal(s)
+	}
+	if s := h.I.Symbols[""]; s.Hits != 2 || s.Misses != 0 {
+		t.Fatal(s)
+	}
+	// Each of the two lines missed in both levels.
+	if n := c.GetCSR().Get(CSRcycle); n != 66+2*110 {
+		t.Fatal(n)
+	}
+	w := &bytes.Buffer{}
+	h.Report(w)
+	for _, e := range []string{
+		"L1I: hits 65 misses 1 writebacks 0\n",
+		"L1D: hits 15 misses 1 writebacks 0\n",
+		"L2: hits 0 misses 2 writebacks 0\n",
+	} {
+		if !strings.Contains(w.String(), e) {
+			t.Fatal(w.String())
+		}
+	}
+}
+
+func TestCacheGeometry(t *testing.T) {
+	for _, e := range [][3]uint64{
+		{1024, 4, 0},
+		{1024, 4, 48},
+		{1024, 0, 16},
+		{0, 4, 16},
+		{3 * 4 * 16, 4, 16},
+	} {
+		if _, err := NewCache("L1", e[0], e[1], e[2], true, 1); err != ErrCacheGeometry {
+			t.Fatal(e)
+		}
+	}
+	// The ways need not be a power of two.
+	if _, err := NewCache("L1", 48*1024, 12, 64, true, 1); err != nil {
+		t.Fatal(err)
+	}
+	c := newVectorCPU()
+	h := NewCaches()
+	h.D.Next.Line = 0
+	if err := c.SetCaches(h); err != ErrCacheGeometry || c.GetCaches() != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheManage(t *testing.T) {
+	cbo := func(op uint64) uint64 { return encodeI(0b0001111, 0b010, op, Rzero, Ra1) }
+	ld := encodeI(0b0000011, 0b011, 0, Ra0, Ra1)
+	sd := encodeS(0b011, Ra1, Ra0, 0)
+	c := newVectorCPU()
+	h := NewCaches()
+	c.SetCaches(h)
+	c.SetRegister(Ra1, 0x808)
+	for _, e := range []struct {
+		i      uint64
+		stats  CacheStats
+		writes uint64
+	}{
+		{sd, CacheStats{0, 1, 0}, 0},
+		{cbo(0b001), CacheStats{0, 1, 1}, 1}, // cbo.clean writes the line back and keeps it.
+		{ld, CacheStats{1, 1, 1}, 1},
+		{cbo(0b000), CacheStats{1, 1, 1}, 1}, // cbo.inval drops it.
+		{ld, CacheStats{1, 2, 1}, 1},
+		{sd, CacheStats{2, 2, 1}, 1},
+		{cbo(0b010), CacheStats{2, 2, 2}, 2}, // cbo.flush does both.
+		{ld, CacheStats{2, 3, 2}, 2},
+	} {
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(err)
+		}
+		if h.D.Stats != e.stats || h.D.Next.Stats.Writebacks != e.writes {
+			t.Fatalf("%#08x: %v %v", e.i, h.D.Stats, h.D.Next.Stats)
+		}
+	}
+}
+
+func TestCacheDevice(t *testing.T) {
+	// The registers of a device bypass the caches.
+	bus := NewBus()
+	bus.AttachMemory(0, NewLinear(0x1000))
+	bus.Attach(0x2000, 0x100, &busTestDevice{})
+	c := newVectorCPU()
+	c.SetFasten(bus)
+	h := NewCaches()
+	c.SetCaches(h)
+	c.GetMemory().GetUint64(0x2000)
+	c.GetMemory().SetUint64(0x2008, 1)
+	if h.D.Stats != (CacheStats{}) {
+		t.Fatal(h.D.Stats)
+	}
+	c.GetMemory().GetUint64(0x800)
+	if h.D.Stats.Misses != 1 {
+		t.Fatal(h.D.Stats)
+	}
+}
+
+func TestCachePrivate(t *testing.T) {
+	// Every hart has its own L1, the L2 is shared.
+	m := NewMachine(1)
+	m.SetFasten(NewLinear(0x1000))
+	h := NewCaches()
+	m.GetHart(0).SetCaches(h)
+	n := m.Clone(m.GetHart(0)).GetCaches()
+	if n.I == h.I || n.D == h.D || n.I.Next != h.I.Next || n.D.Next != h.D.Next {
+		t.FailNow()
+	}
+	n.D.access(0x100, false, "")
+	if h.D.Stats.Misses != 0 || h.D.Next.Stats.Misses != 1 {
+		t.FailNow()
+	}
+}
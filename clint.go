@@ -0,0 +1,82 @@
+package rv64
+
+// The core-local interruptor provides the memory-mapped machine software interrupt and timer registers. The layout is
+// the one used by SiFive cores, QEMU virt and OpenSBI.
+//
+// Offset  Width  Name      Description
+// 0x0000  4      msip      Machine software interrupt pending, only bit 0 is writable.
+// 0x4000  8      mtimecmp  Machine timer compare.
+// 0xbff8  8      mtime     Machine timer, it is the time counter advanced by CPU.Run.
+//
+// MSIP is set in mip while msip is 1, MTIP is set in mip while mtime >= mtimecmp.
+
+const (
+	ClintBase     = 0x02000000
+	ClintSize     = 0x00010000
+	ClintMsip     = 0x0000
+	ClintMtimecmp = 0x4000
+	ClintMtime    = 0xbff8
+)
+
+// Clint is the core-local interruptor device of a CPU.
+type Clint struct {
+	cpu      *CPU
+	msip     uint64
+	mtimecmp uint64
+}
+
+func (l *Clint) Read(offset uint64, size uint64) (uint64, error) {
+	var r uint64
+	var n uint64
+	switch {
+	case offset >= ClintMsip && offset < ClintMsip+4:
+		r, n = l.msip, offset-ClintMsip
+	case offset >= ClintMtimecmp && offset < ClintMtimecmp+8:
+		r, n = l.mtimecmp, offset-ClintMtimecmp
+	case offset >= ClintMtime && offset < ClintMtime+8:
+		r, n = l.cpu.GetCSR().Get(CSRtime), offset-ClintMtime
+	default:
+		return 0, nil
+	}
+	return r >> (8 * n) & (0xffffffffffffffff >> (64 - 8*size)), nil
+}
+
+func (l *Clint) Write(offset uint64, size uint64, value uint64) error {
+	m := uint64(0xffffffffffffffff) >> (64 - 8*size)
+	switch {
+	case offset >= ClintMsip && offset < ClintMsip+4:
+		n := 8 * (offset - ClintMsip)
+		l.msip = (l.msip&^(m<<n) | value<<n) & 1
+	case offset >= ClintMtimecmp && offset < ClintMtimecmp+8:
+		n := 8 * (offset - ClintMtimecmp)
+		l.mtimecmp = l.mtimecmp&^(m<<n) | value<<n
+	case offset >= ClintMtime && offset < ClintMtime+8:
+		n := 8 * (offset - ClintMtime)
+		t := l.cpu.GetCSR().Get(CSRtime)
+		l.cpu.GetCSR().Set(CSRtime, t&^(m<<n)|value<<n)
+	}
+	l.Update()
+	return nil
+}
+
+func (l *Clint) Tick(n uint64) {
+	l.Update()
+}
+
+// Update drives MSIP and MTIP of mip from the current state of the CLINT.
+func (l *Clint) Update() {
+	l.cpu.SetInterruptPending(InterruptMSI, int(l.msip))
+	if l.cpu.GetCSR().Get(CSRtime) >= l.mtimecmp {
+		l.cpu.SetInterruptPending(InterruptMTI, 1)
+	} else {
+		l.cpu.SetInterruptPending(InterruptMTI, 0)
+	}
+}
+
+// NewClint returns a CLINT driving the interrupts of the CPU. It should be attached to the bus at ClintBase.
+func NewClint(c *CPU) *Clint {
+	return &Clint{
+		cpu:      c,
+		mtimecmp: 0xffffffffffffffff,
+	}
+}
@@ -0,0 +1,77 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func TestClintTimerInterrupt(t *testing.T) {
+	c := NewCPU()
+	c.SetSystem(NewSystemStandard())
+	c.SetCSR(NewCSRStandard())
+	bus := NewBus()
+	bus.AttachMemory(0, NewLinear(1024*1024))
+	bus.Attach(ClintBase, ClintSize, NewClint(c))
+	c.SetFasten(bus)
+	mem := c.GetMemory()
+	// 0x1000: j .
+	mem.SetUint32(0x1000, 0x0000006f)
+	// 0x2000: li a7, 93; li a0, 7; ecall
+	mem.SetUint32(0x2000, 0x05d00893)
+	mem.SetUint32(0x2004, 0x00700513)
+	mem.SetUint32(0x2008, 0x00000073)
+	mem.SetUint64(ClintBase+ClintMtimecmp, 10)
+	c.GetCSR().Set(CSRmtvec, 0x2000)
+	c.GetCSR().Set(CSRmie, 1<<InterruptMTI)
+	c.GetCSR().Set(CSRmstatus, MstatusMIE)
+	c.SetPC(0x1000)
+	if r, err := c.Run(); r != 7 || err != nil {
+		t.Fatal(r, err)
+	}
+	if c.GetCSR().Get(CSRmcause) != 1<<63|InterruptMTI {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmepc) != 0x1000 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRtime) < 10 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmstatus)&(MstatusMIE|MstatusMPIE) != MstatusMPIE {
+		t.FailNow()
+	}
+}
+
+func TestClintSoftwareInterrupt(t *testing.T) {
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	bus := NewBus()
+	bus.AttachMemory(0, NewLinear(1024*1024))
+	bus.Attach(ClintBase, ClintSize, NewClint(c))
+	c.SetFasten(bus)
+	c.GetCSR().Set(CSRmtvec, 0x2001)
+	c.GetCSR().Set(CSRmie, 1<<InterruptMSI)
+	c.SetPC(0x1000)
+	c.GetMemory().SetUint32(ClintBase+ClintMsip, 0xffffffff)
+	if v, _ := c.GetMemory().GetUint32(ClintBase + ClintMsip); v != 1 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 1<<InterruptMSI {
+		t.FailNow()
+	}
+	// Interrupts are globally disabled.
+	if c.Interrupt() {
+		t.FailNow()
+	}
+	c.GetCSR().Set(CSRmstatus, MstatusMIE)
+	if !c.Interrupt() {
+		t.FailNow()
+	}
+	// Vectored mode.
+	if c.GetPC() != 0x2000+4*InterruptMSI {
+		t.FailNow()
+	}
+	c.GetMemory().SetUint32(ClintBase+ClintMsip, 0)
+	if c.GetCSR().Get(CSRmip) != 0 {
+		t.FailNow()
+	}
+}
@@ -78,6 +78,18 @@ func testRiscvTests() {
 	if err != nil {
 		log.Panicln(err)
 	}
+	p, err := filepath.Glob(filepath.Join("res", "riscv-tests", "isa", "rv64u[imafdc]-p-*"))
+	if err != nil {
+		log.Panicln(err)
+	}
+	m = append(m, p...)
+	for _, e := range []string{"rv32u[imafdc]-u-*", "rv32u[imafdc]-p-*"} {
+		r, err := filepath.Glob(filepath.Join("res", "riscv-tests", "isa", e))
+		if err != nil {
+			log.Panicln(err)
+		}
+		m = append(m, r...)
+	}
 	for _, e := range m {
 		if strings.HasSuffix(e, ".dump") {
 			continue
@@ -3,14 +3,23 @@ package main
 import (
 	"debug/elf"
 	"flag"
+	"io"
 	"log"
 	"os"
 
 	"github.com/mohanson/rv64"
 )
 
+const (
+	cDramBase = 0x80000000
+	cElfRVE   = 0x0008 // EF_RISCV_RVE, the program targets the embedded base
+)
+
 var (
-	flDebug = flag.Bool("d", false, "Debug")
+	flDebug   = flag.Bool("d", false, "Debug")
+	flUartIn  = flag.String("uart-in", "", "Read UART input from the file instead of stdin")
+	flUartOut = flag.String("uart-out", "", "Write UART output to the file instead of stdout")
+	flCache   = flag.Bool("cache", false, "Simulate the caches and print their statistics on exit")
 )
 
 func prog() []string {
@@ -24,21 +33,92 @@ func prog() []string {
 	return os.Args[i+1:]
 }
 
-func main() {
-	args := prog()
-	if *flDebug {
-		rv64.LogLevel = 1
+// elfFlags returns the processor-specific e_flags of the ELF header, the debug/elf package does not expose it.
+func elfFlags(name string, f *elf.File) uint32 {
+	r, err := os.Open(name)
+	if err != nil {
+		log.Panicln(err)
+	}
+	defer r.Close()
+	off := int64(0x30)
+	if f.Class == elf.ELFCLASS32 {
+		off = 0x24
+	}
+	b := make([]byte, 4)
+	if _, err := r.ReadAt(b, off); err != nil {
+		log.Panicln(err)
+	}
+	return f.ByteOrder.Uint32(b)
+}
+
+// setup returns a machine running the program args[0] with the arguments args, as the command line asks, and a function
+// closing the files it opened once the machine stopped.
+func setup(args []string) (*rv64.Machine, func()) {
+	// The program starts on hart 0, the threads it creates run on new harts.
+	machine := rv64.NewMachine(1)
+	cpu := machine.GetHart(0)
+	bus := rv64.NewBus()
+	machine.SetFasten(bus)
+	machine.SetSystem(rv64.NewSystemStandard())
+	if err := bus.AttachMemory(0, rv64.NewLinear(4*1024*1024)); err != nil {
+		log.Panicln(err)
+	}
+	if err := bus.Attach(rv64.ClintBase, rv64.ClintSize, rv64.NewClint(cpu)); err != nil {
+		log.Panicln(err)
+	}
+	plic := rv64.NewPlic(cpu)
+	if err := bus.Attach(rv64.PlicBase, rv64.PlicSize, plic); err != nil {
+		log.Panicln(err)
+	}
+	files := []*os.File{}
+	cleanup := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	uartIn := io.Reader(os.Stdin)
+	if *flUartIn != "" {
+		f, err := os.Open(*flUartIn)
+		if err != nil {
+			log.Panicln(err)
+		}
+		files = append(files, f)
+		uartIn = f
+	}
+	uartOut := io.Writer(os.Stdout)
+	if *flUartOut != "" {
+		f, err := os.Create(*flUartOut)
+		if err != nil {
+			log.Panicln(err)
+		}
+		files = append(files, f)
+		uartOut = f
+	}
+	if err := bus.Attach(rv64.UartBase, rv64.UartSize, rv64.NewUart(uartIn, uartOut, plic, rv64.UartIrq)); err != nil {
+		log.Panicln(err)
 	}
-	cpu := rv64.NewCPU()
-	cpu.SetFasten(rv64.NewLinear(4 * 1024 * 1024))
-	cpu.SetSystem(rv64.NewSystemStandard())
-	cpu.SetCSR(rv64.NewCSRStandard())
 
 	f, err := elf.Open(args[0])
 	if err != nil {
 		log.Panicln(err)
 	}
 	defer f.Close()
+	if f.Class == elf.ELFCLASS32 {
+		cpu.SetXLEN(32)
+	}
+	if elfFlags(args[0], f)&cElfRVE != 0 {
+		cpu.SetISA(cpu.GetISA() | rv64.ISAE)
+	}
+	// Bare-metal programs, such as the riscv-tests "-p-" environment, are linked at the DRAM base address of Spike
+	// and QEMU virt.
+	for _, p := range f.Progs {
+		if p.ProgHeader.Type == elf.PT_LOAD && p.Vaddr >= cDramBase {
+			if err := bus.AttachMemory(cDramBase, rv64.NewLinear(4*1024*1024)); err != nil {
+				log.Panicln(err)
+			}
+			break
+		}
+	}
 	for _, p := range f.Progs {
 		// Specifies a loadable segment, described by p_filesz and p_memsz. The bytes from the file are mapped to the
 		// beginning of the memory segment. If the segment's memory size (p_memsz) is larger than the file size
@@ -51,6 +131,26 @@ func main() {
 			cpu.GetMemory().SetByte(p.Vaddr, mem)
 		}
 	}
+	// The cache statistics are reported per function.
+	funcs := []rv64.Symbol{}
+	// Programs talking to the host through the HTIF define the tohost and fromhost symbols.
+	if syms, err := f.Symbols(); err == nil {
+		var tohost, fromhost uint64
+		for _, e := range syms {
+			switch e.Name {
+			case "tohost":
+				tohost = e.Value
+			case "fromhost":
+				fromhost = e.Value
+			}
+			if elf.ST_TYPE(e.Info) == elf.STT_FUNC {
+				funcs = append(funcs, rv64.Symbol{Name: e.Name, Addr: e.Value, Size: e.Size})
+			}
+		}
+		if tohost != 0 {
+			machine.SetSystem(rv64.NewHtif(cpu, tohost, fromhost, os.Stdin, os.Stdout))
+		}
+	}
 	cpu.SetPC(f.Entry)
 	cpu.SetRegister(rv64.Rsp, cpu.GetMemory().Len())
 
@@ -72,14 +172,24 @@ func main() {
 	envPtrs := []uint64{}
 	argPtrs := []uint64{}
 
+	// Pointers and argc are XLEN bits wide.
+	wordSize := cpu.GetXLEN() / 8
+	pushWord := func(v uint64) {
+		if wordSize == 4 {
+			cpu.PushUint32(uint32(v))
+		} else {
+			cpu.PushUint64(v)
+		}
+	}
+
 	// Stack pointer must be aligned to 16-byte boundary.
 	rLength := func() uint64 {
 		var r uint64 = 0
-		r += 8
-		r += 8 * uint64(len(argList))
-		r += 8
-		r += 8 * uint64(len(envList))
-		r += 8
+		r += wordSize
+		r += wordSize * uint64(len(argList))
+		r += wordSize
+		r += wordSize * uint64(len(envList))
+		r += wordSize
 		for _, e := range argList {
 			r += uint64(len(e)) + 1
 		}
@@ -101,19 +211,44 @@ func main() {
 		cpu.PushString(argList[i])
 		argPtrs = append(argPtrs, cpu.GetRegister(rv64.Rsp))
 	}
-	cpu.PushUint64(0)
+	pushWord(0)
 	for i := 0; i < len(envPtrs); i++ {
-		cpu.PushUint64(envPtrs[i])
+		pushWord(envPtrs[i])
 	}
-	cpu.PushUint64(0)
+	pushWord(0)
 	for i := 0; i < len(argPtrs); i++ {
-		cpu.PushUint64(argPtrs[i])
+		pushWord(argPtrs[i])
 	}
-	cpu.PushUint64(uint64(len(argList)))
+	pushWord(uint64(len(argList)))
 
 	if cpu.GetRegister(rv64.Rsp)%16 != 0 {
 		rv64.Panicln("unreachable")
 	}
 
-	os.Exit(int(cpu.Run()))
+	// The caches observe the program only, not its loading.
+	if *flCache {
+		caches := rv64.NewCaches()
+		caches.SetSymbols(funcs)
+		if err := cpu.SetCaches(caches); err != nil {
+			log.Panicln(err)
+		}
+	}
+	return machine, cleanup
+}
+
+func main() {
+	args := prog()
+	if *flDebug {
+		rv64.LogLevel = 1
+	}
+	machine, cleanup := setup(args)
+	code, err := machine.Run()
+	cleanup()
+	if err != nil {
+		log.Panicln(err)
+	}
+	if c := machine.GetHart(0).GetCaches(); c != nil {
+		c.Report(os.Stderr)
+	}
+	os.Exit(int(code))
 }
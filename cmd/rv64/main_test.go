@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestELF writes a riscv-tests "-p-" style program: the instructions at the DRAM base, in a segment of 8 KiB
+// holding tohost at +0x1000 and fromhost at +0x1040, both defined in the symbol table.
+func writeTestELF(name string, prog []uint32) error {
+	text := make([]byte, 4*len(prog))
+	for i, e := range prog {
+		binary.LittleEndian.PutUint32(text[4*i:], e)
+	}
+	strtab := []byte("\x00tohost\x00fromhost\x00")
+	shstrtab := []byte("\x00.text\x00.symtab\x00.strtab\x00.shstrtab\x00")
+	symtab := &bytes.Buffer{}
+	for _, e := range []elf.Sym64{
+		{},
+		{Name: 1, Info: byte(elf.STB_GLOBAL)<<4 | byte(elf.STT_OBJECT), Shndx: 1, Value: cDramBase + 0x1000, Size: 8},
+		{Name: 8, Info: byte(elf.STB_GLOBAL)<<4 | byte(elf.STT_OBJECT), Shndx: 1, Value: cDramBase + 0x1040, Size: 8},
+	} {
+		binary.Write(symtab, binary.LittleEndian, e)
+	}
+	// The headers, then the contents of the sections, then the section headers.
+	offText := uint64(64 + 56)
+	offSymtab := offText + uint64(len(text))
+	offStrtab := offSymtab + uint64(symtab.Len())
+	offShstrtab := offStrtab + uint64(len(strtab))
+	offSections := offShstrtab + uint64(len(shstrtab))
+	h := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_RISCV),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     cDramBase,
+		Phoff:     64,
+		Shoff:     offSections,
+		Ehsize:    64,
+		Phentsize: 56,
+		Phnum:     1,
+		Shentsize: 64,
+		Shnum:     5,
+		Shstrndx:  4,
+	}
+	copy(h.Ident[:], elf.ELFMAG)
+	h.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	h.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	h.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	p := elf.Prog64{
+		Type:   uint32(elf.PT_LOAD),
+		Flags:  uint32(elf.PF_R | elf.PF_W | elf.PF_X),
+		Off:    offText,
+		Vaddr:  cDramBase,
+		Paddr:  cDramBase,
+		Filesz: uint64(len(text)),
+		Memsz:  0x2000,
+	}
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_EXECINSTR | elf.SHF_WRITE),
+			Addr: cDramBase, Off: offText, Size: uint64(len(text)), Addralign: 4},
+		{Name: 7, Type: uint32(elf.SHT_SYMTAB), Off: offSymtab, Size: uint64(symtab.Len()), Link: 3, Info: 1,
+			Addralign: 8, Entsize: 24},
+		{Name: 15, Type: uint32(elf.SHT_STRTAB), Off: offStrtab, Size: uint64(len(strtab)), Addralign: 1},
+		{Name: 23, Type: uint32(elf.SHT_STRTAB), Off: offShstrtab, Size: uint64(len(shstrtab)), Addralign: 1},
+	}
+	b := &bytes.Buffer{}
+	binary.Write(b, binary.LittleEndian, h)
+	binary.Write(b, binary.LittleEndian, p)
+	b.Write(text)
+	b.Write(symtab.Bytes())
+	b.Write(strtab)
+	b.Write(shstrtab)
+	for _, e := range sections {
+		binary.Write(b, binary.LittleEndian, e)
+	}
+	return os.WriteFile(name, b.Bytes(), 0644)
+}
+
+func TestHtifProgram(t *testing.T) {
+	for _, e := range []struct {
+		tohost uint32
+		code   uint8
+	}{
+		{1, 0},        // Pass
+		{3<<1 | 1, 3}, // Test 3 failed
+	} {
+		name := filepath.Join(t.TempDir(), "rv64ui-p-test")
+		err := writeTestELF(name, []uint32{
+			0x00001297,                // auipc t0, 0x1
+			e.tohost<<20 | 0x00000313, // li t1, tohost
+			0x0062b023,                // sd t1, 0(t0)
+			0x0000006f,                // j .
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		machine, cleanup := setup([]string{name})
+		code, err := machine.Run()
+		cleanup()
+		if code != e.code || err != nil {
+			t.Fatal(code, err)
+		}
+	}
+}
+
+func TestUartOut(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "uart")
+	err := writeTestELF(name, []uint32{
+		0x00001297, // auipc t0, 0x1
+		0x100003b7, // lui t2, 0x10000
+		0x04800313, // li t1, 'H'
+		0x00638023, // sb t1, 0(t2)
+		0x06900313, // li t1, 'i'
+		0x00638023, // sb t1, 0(t2)
+		0x00100313, // li t1, 1
+		0x0062b023, // sd t1, 0(t0)
+		0x0000006f, // j .
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	*flUartOut = out
+	defer func() { *flUartOut = "" }()
+	machine, cleanup := setup([]string{name})
+	code, err := machine.Run()
+	cleanup()
+	if code != 0 || err != nil {
+		t.Fatal(code, err)
+	}
+	if b, err := os.ReadFile(out); string(b) != "Hi" || err != nil {
+		t.Fatal(string(b), err)
+	}
+}
@@ -6,12 +6,28 @@ package rv64
 // 0x001  Read/write fflags   Floating-Point Accrued Exceptions.
 // 0x002  Read/write frm      Floating-Point Dynamic Rounding Mode.
 // 0x003  Read/write fcsr     Floating-Point Control and Status Register (frm + fflags).
+// 0x008  Read/write vstart   Vector start position.
+// 0x009  Read/write vxsat    Fixed-Point Saturate Flag.
+// 0x00A  Read/write vxrm     Fixed-Point Rounding Mode.
+// 0x00F  Read/write vcsr     Vector control and status register (vxrm + vxsat).
+// 0x180  Read/write satp     Supervisor address translation and protection.
+// 0x300  Read/write mstatus  Machine status register.
+// 0x304  Read/write mie      Machine interrupt-enable register.
+// 0x305  Read/write mtvec    Machine trap-handler base address.
+// 0x341  Read/write mepc     Machine exception program counter.
+// 0x342  Read/write mcause   Machine trap cause.
+// 0x343  Read/write mtval    Machine bad address or instruction.
+// 0x344  Read/write mip      Machine interrupt pending.
+// 0xF14  Read-only  mhartid  Hardware thread ID.
 // 0xC00  Read-only  cycle    Cycle counter for RDCYCLE instruction.
 // 0xC01  Read-only  time     Timer for RDTIME instruction.
 // 0xC02  Read-only  instret  Instructions-retired counter for RDINSTRET instruction.
 // 0xC80  Read-only  cycleh   Upper 32 bits of cycle, RV32I only.
 // 0xC81  Read-only  timeh    Upper 32 bits of time, RV32I only.
 // 0xC82  Read-only  instreth Upper 32 bits of instret, RV32I only.
+// 0xC20  Read-only  vl       Vector length.
+// 0xC21  Read-only  vtype    Vector data type register.
+// 0xC22  Read-only  vlenb    VLEN/8 (vector register length in bytes).
 
 type CSR interface {
 	Get(uint64) uint64
@@ -28,6 +44,10 @@ func (c *CSRStandard) Get(i uint64) uint64 {
 		return c.m[CSRfcsr] & 0x1f
 	case i == CSRfrm:
 		return c.m[CSRfcsr] & 0xe0 >> 5
+	case i == CSRvxsat:
+		return c.m[CSRvcsr] & 0x01
+	case i == CSRvxrm:
+		return c.m[CSRvcsr] & 0x06 >> 1
 	case i == i:
 		return c.m[i]
 	}
@@ -44,11 +64,56 @@ func (c *CSRStandard) Set(i uint64, u uint64) {
 	case i == CSRfrm:
 		c.m[i] = u & 0x07
 		c.m[CSRfcsr] = c.m[CSRfcsr]&0xffffffffffffff1f | ((u & 0x07) << 5)
+	case i == CSRvcsr:
+		c.m[i] = u & 0x07
+	case i == CSRvxsat:
+		c.m[CSRvcsr] = c.m[CSRvcsr]&0x06 | (u & 0x01)
+	case i == CSRvxrm:
+		c.m[CSRvcsr] = c.m[CSRvcsr]&0x01 | ((u & 0x03) << 1)
 	case i == i:
 		c.m[i] = u
 	}
 }
 
+// ReadCSR reads a CSR on behalf of the Zicsr instructions. Registers describing the CPU configuration are not stored
+// in the CSR.
+func (c *CPU) ReadCSR(i uint64) uint64 {
+	switch i {
+	case CSRvlenb:
+		return c.GetVLEN() / 8
+	case CSRmhartid:
+		return c.GetHartID()
+	case CSRcycleh, CSRtimeh, CSRinstreth:
+		if c.GetXLEN() == 32 {
+			return c.GetCSR().Get(i-0x80) >> 32
+		}
+	}
+	return c.GetCSR().Get(i)
+}
+
+// WriteCSR writes a CSR on behalf of the Zicsr instructions. Fields whose legal values depend on the CPU configuration
+// are WARL (Write Any values, Reads Legal values), an illegal write leaves the register unchanged.
+func (c *CPU) WriteCSR(i uint64, u uint64) {
+	switch i {
+	case CSRsatp:
+		if c.GetPagingModes()&(1<<InstructionPart(u, 60, 63)) == 0 {
+			return
+		}
+	case CSRmip:
+		// MEIP, MTIP and MSIP are read-only, they are driven by the interrupt controllers.
+		m := uint64(1<<InterruptSSI | 1<<InterruptSTI | 1<<InterruptSEI)
+		u = c.GetCSR().Get(CSRmip)&^m | u&m
+	case CSRvl, CSRvtype, CSRvlenb:
+		// Only changed by the vset{i}vl{i} instructions.
+		return
+	case CSRmhartid:
+		return
+	case CSRvstart:
+		u = u & (c.GetVLEN() - 1)
+	}
+	c.GetCSR().Set(i, u)
+}
+
 func NewCSRStandard() CSR {
 	return &CSRStandard{}
 }
@@ -79,12 +79,31 @@ const (
 )
 
 const (
-	CSRfflags  = 0x001 // Floating-Point Accrued Exceptions.
-	CSRfrm     = 0x002 // Floating-Point Dynamic Rounding Mode.
-	CSRfcsr    = 0x003 // Floating-Point Control and Status Register (frm + fflags).
-	CSRcycle   = 0xc00 // Cycle counter for RDCYCLE instruction.
-	CSRtime    = 0xc01 // Timer for RDTIME instruction.
-	CSRinstret = 0xc02 // Instructions-retired counter for RDINSTRET instruction.
+	CSRfflags   = 0x001 // Floating-Point Accrued Exceptions.
+	CSRfrm      = 0x002 // Floating-Point Dynamic Rounding Mode.
+	CSRfcsr     = 0x003 // Floating-Point Control and Status Register (frm + fflags).
+	CSRvstart   = 0x008 // Vector start position.
+	CSRvxsat    = 0x009 // Fixed-Point Saturate Flag.
+	CSRvxrm     = 0x00a // Fixed-Point Rounding Mode.
+	CSRvcsr     = 0x00f // Vector control and status register (vxrm + vxsat).
+	CSRsatp     = 0x180 // Supervisor address translation and protection.
+	CSRmstatus  = 0x300 // Machine status register.
+	CSRmie      = 0x304 // Machine interrupt-enable register.
+	CSRmtvec    = 0x305 // Machine trap-handler base address.
+	CSRmepc     = 0x341 // Machine exception program counter.
+	CSRmcause   = 0x342 // Machine trap cause.
+	CSRmtval    = 0x343 // Machine bad address or instruction.
+	CSRmip      = 0x344 // Machine interrupt pending.
+	CSRmhartid  = 0xf14 // Hardware thread ID.
+	CSRcycle    = 0xc00 // Cycle counter for RDCYCLE instruction.
+	CSRtime     = 0xc01 // Timer for RDTIME instruction.
+	CSRinstret  = 0xc02 // Instructions-retired counter for RDINSTRET instruction.
+	CSRcycleh   = 0xc80 // Upper 32 bits of cycle, RV32I only.
+	CSRtimeh    = 0xc81 // Upper 32 bits of time, RV32I only.
+	CSRinstreth = 0xc82 // Upper 32 bits of instret, RV32I only.
+	CSRvl       = 0xc20 // Vector length.
+	CSRvtype    = 0xc21 // Vector data type register.
+	CSRvlenb    = 0xc22 // VLEN/8 (vector register length in bytes).
 )
 
 const (
@@ -134,6 +153,63 @@ const (
 	FFlagsNX uint64 = 0x01
 )
 
+// Rounding modes, encoded in the rm field of floating-point instructions and in the frm CSR.
+const (
+	FRoundRNE uint64 = 0b000 // Round to Nearest, ties to Even
+	FRoundRTZ uint64 = 0b001 // Round towards Zero
+	FRoundRDN uint64 = 0b010 // Round Down (towards -infinity)
+	FRoundRUP uint64 = 0b011 // Round Up (towards +infinity)
+	FRoundRMM uint64 = 0b100 // Round to Nearest, ties to Max Magnitude
+	FRoundDYN uint64 = 0b111 // In instruction's rm field, selects dynamic rounding mode; In Rounding Mode register, Invalid
+)
+
+// Execution status of a CPU.
+const (
+	StatusRunning  uint64 = 0 // Executing instructions
+	StatusExit     uint64 = 1 // The program exited, the exit code is kept by the System
+	StatusHalt     uint64 = 2 // The hart stopped, the other harts of the Machine go on
+	StatusOutOfGas uint64 = 3 // The cycle limit was reached before the program exited
+	StatusWait     uint64 = 4 // The hart waits on a futex, the Machine does not schedule it until it is woken
+)
+
+// Branch predictors of the Timing model.
+const (
+	PredictorStatic  uint64 = 0 // Backward branches taken, forward branches not taken
+	PredictorBimodal uint64 = 1 // 2-bit saturating counters indexed by the pc
+	PredictorGshare  uint64 = 2 // 2-bit saturating counters indexed by the pc xor the global branch history
+)
+
+// Replacement policies of a Cache.
+const (
+	CacheReplaceLRU    uint64 = 0 // Least recently used
+	CacheReplaceFIFO   uint64 = 1 // First in, first out
+	CacheReplaceRandom uint64 = 2 // Pseudo-random, from a fixed seed
+)
+
+// Optional extensions which can be enabled or disabled per CPU. The instructions of a disabled extension are not
+// decoded.
+const (
+	ISAZba    uint64 = 1 << 0  // Address generation
+	ISAZbb    uint64 = 1 << 1  // Basic bit-manipulation
+	ISAZbs    uint64 = 1 << 2  // Single-bit instructions
+	ISAV      uint64 = 1 << 3  // Vector
+	ISAZfh    uint64 = 1 << 4  // Half-precision floating-point
+	ISAZfhmin uint64 = 1 << 5  // Minimal half-precision floating-point, loads, stores and conversions only
+	ISAQ      uint64 = 1 << 6  // Quad-precision floating-point
+	ISAE      uint64 = 1 << 7  // Embedded base, only x0-x15 exist and naming x16-x31 is illegal
+	ISAZbkb   uint64 = 1 << 8  // Bit-manipulation for cryptography
+	ISAZbkc   uint64 = 1 << 9  // Carry-less multiplication for cryptography
+	ISAZbkx   uint64 = 1 << 10 // Crossbar permutations
+	ISAZknd   uint64 = 1 << 11 // NIST suite: AES decryption
+	ISAZkne   uint64 = 1 << 12 // NIST suite: AES encryption
+	ISAZknh   uint64 = 1 << 13 // NIST suite: hash function instructions
+	ISAZicond uint64 = 1 << 14 // Integer conditional operations
+	ISAZicbom uint64 = 1 << 15 // Cache-block management
+	ISAZicboz uint64 = 1 << 16 // Cache-block zero
+	ISAZacas  uint64 = 1 << 17 // Atomic compare-and-swap
+	ISAZabha  uint64 = 1 << 18 // Byte and halfword atomic memory operations
+)
+
 var (
 	ErrAbnormalEcall              = errors.New("Abnormal ecall")
 	ErrAbnormalInstruction        = errors.New("Abnormal instruction")
@@ -141,11 +217,25 @@ var (
 	ErrOutOfMemory                = errors.New("Out of memory")
 	ErrReservedInstruction        = errors.New("Reserved instruction")
 	ErrHint                       = errors.New("Hint")
+	ErrInstructionPageFault       = errors.New("Instruction page fault")
+	ErrLoadPageFault              = errors.New("Load page fault")
+	ErrStorePageFault             = errors.New("Store/AMO page fault")
+	ErrOverlappingDevice          = errors.New("Overlapping device")
+	ErrSnapshotFormat             = errors.New("Invalid snapshot")
+	ErrSnapshotUnsupported        = errors.New("Snapshot unsupported")
+	ErrForkUnsupported            = errors.New("Fork unsupported")
+	ErrSymbolNotFound             = errors.New("Symbol not found")
+	ErrCacheBlockSize             = errors.New("Invalid cache block size")
+	ErrDeadlock                   = errors.New("Deadlock")
+	ErrOutOfGas                   = errors.New("Out of gas")
+	ErrCacheGeometry              = errors.New("Invalid cache geometry")
 )
 
 var (
-	NaN32 uint32 = 0x7fc00000
-	NaN64 uint64 = 0x7ff8000000000000
+	NaN16  uint16 = 0x7e00
+	NaN32  uint32 = 0x7fc00000
+	NaN64  uint64 = 0x7ff8000000000000
+	NaN128        = Float128{Hi: 0x7fff800000000000, Lo: 0x0000000000000000}
 )
 
 var (
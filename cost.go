@@ -0,0 +1,152 @@
+package rv64
+
+// CostModel prices the instructions executed by a CPU. The price of an instruction is known before it executes, so that
+// a CPU with a cycle limit never starts an instruction it can not pay for. To keep the executions reproducible, the
+// price must only depend on the instruction and the architectural state of the CPU.
+type CostModel interface {
+	// Cost returns the cycles spent executing the instruction i, which is l bytes long.
+	Cost(c *CPU, i uint64, l uint64) uint64
+}
+
+// CostCloner is a CostModel holding the state of a single hart. The harts cloned by a Machine and the forks of a CPU
+// each get their own copy of it, so that their cycles do not depend on one another.
+type CostCloner interface {
+	CostModel
+	// Clone returns a copy of the model and of its state.
+	Clone() CostModel
+}
+
+// CostTable is a CostModel built from a price per opcode and surcharges. The price of an instruction is the price of
+// its funct fields when set in Funct, or else of its opcode, plus Memory per byte loaded or stored, plus Syscall for an
+// ecall.
+type CostTable struct {
+	// Opcode holds the price of the 32-bit instructions, indexed by their major opcode, bits 0 to 6.
+	Opcode [128]uint64
+	// Funct overrides the price of the 32-bit instructions by their major opcode, funct3 and funct7, keyed by CostKey.
+	// It tells mul, div and rem from add in OP and OP-32, or fdiv and fsqrt from fadd in OP-FP. The key holds funct7
+	// for OP, OP-32 and OP-FP only, the funct5 of AMO, and funct3 except in OP-FP where it is the rounding mode.
+	Funct map[uint64]uint64
+	// Compressed holds the price of the 16-bit instructions, indexed by their quadrant and funct3, op << 3 | funct3.
+	Compressed [32]uint64
+	// Memory is charged per byte accessed by the loads, stores and atomic memory operations, scalar or vector.
+	Memory uint64
+	// Syscall is charged for an ecall, on top of the price of the SYSTEM opcode.
+	Syscall uint64
+}
+
+func (t *CostTable) Cost(c *CPU, i uint64, l uint64) uint64 {
+	if l == 2 {
+		k := InstructionPart(i, 0, 1)<<3 | InstructionPart(i, 13, 15)
+		return t.Compressed[k] + t.Memory*costCompressedBytes(c, i)
+	}
+	r := t.Opcode[InstructionPart(i, 0, 6)]
+	if p, ok := t.Funct[costKey(i)]; ok {
+		r = p
+	}
+	r += t.Memory * costBytes(c, i)
+	if i == 0x00000073 {
+		r += t.Syscall
+	}
+	return r
+}
+
+// CostKey returns the key in CostTable.Funct of the instructions with the major opcode op, funct3 f3 and funct7 f7.
+// Fields the key does not hold must be 0.
+func CostKey(op uint64, f3 uint64, f7 uint64) uint64 {
+	return f7<<10 | f3<<7 | op
+}
+
+// costKey returns the key in CostTable.Funct of the 32-bit instruction i.
+func costKey(i uint64) uint64 {
+	op := InstructionPart(i, 0, 6)
+	f3 := InstructionPart(i, 12, 14)
+	f7 := InstructionPart(i, 25, 31)
+	switch op {
+	case 0b0110011, 0b0111011:
+		return CostKey(op, f3, f7)
+	case 0b1010011:
+		return CostKey(op, 0, f7)
+	case 0b0101111:
+		// The aq and rl bits do not change the operation.
+		return CostKey(op, f3, f7&^0b11)
+	}
+	return CostKey(op, f3, 0)
+}
+
+// costBytes returns the number of bytes accessed in memory by the 32-bit instruction i.
+func costBytes(c *CPU, i uint64) uint64 {
+	funct3 := InstructionPart(i, 12, 14)
+	switch InstructionPart(i, 0, 6) {
+	case 0b0000011, 0b0100011:
+		return 1 << (funct3 & 0b11)
+	case 0b0101111:
+		return 1 << funct3
+	case 0b0000111, 0b0100111:
+		switch funct3 {
+		case 0b001, 0b010, 0b011, 0b100:
+			return 1 << funct3
+		}
+		return costVectorBytes(c, i)
+	}
+	return 0
+}
+
+// costVectorBytes returns the number of bytes accessed in memory by the vector load or store i. The width field of the
+// indexed accesses is the EEW of the indices, their data elements are SEW wide.
+func costVectorBytes(c *CPU, i uint64) uint64 {
+	eew := uint64(1)
+	switch InstructionPart(i, 12, 14) {
+	case 0b101:
+		eew = 2
+	case 0b110:
+		eew = 4
+	case 0b111:
+		eew = 8
+	}
+	if m := InstructionPart(i, 26, 27); m == 0b01 || m == 0b11 {
+		if sew, _, err := c.vtype(); err == nil {
+			eew = sew / 8
+		}
+	}
+	nf := InstructionPart(i, 29, 31) + 1
+	vl := c.GetCSR().Get(CSRvl)
+	if InstructionPart(i, 26, 27) == 0b00 {
+		switch InstructionPart(i, 20, 24) {
+		case 0b01000:
+			return nf * c.GetVLEN() / 8
+		case 0b01011:
+			return (vl + 7) / 8
+		}
+	}
+	return vl * eew * nf
+}
+
+// costCompressedBytes returns the number of bytes accessed in memory by the 16-bit instruction i. The loads and stores
+// of quadrants 0 and 2 share the same funct3.
+func costCompressedBytes(c *CPU, i uint64) uint64 {
+	op := InstructionPart(i, 0, 1)
+	if op != 0b00 && op != 0b10 {
+		return 0
+	}
+	switch InstructionPart(i, 13, 15) {
+	case 0b001, 0b101:
+		return 8
+	case 0b010, 0b110:
+		return 4
+	case 0b011, 0b111:
+		return c.GetXLEN() / 8
+	}
+	return 0
+}
+
+// NewCostTable returns a CostTable where every instruction costs one cycle, as reported by the instruction handlers.
+func NewCostTable() *CostTable {
+	t := &CostTable{Funct: map[uint64]uint64{}}
+	for i := range t.Opcode {
+		t.Opcode[i] = 1
+	}
+	for i := range t.Compressed {
+		t.Compressed[i] = 1
+	}
+	return t
+}
@@ -0,0 +1,137 @@
+package rv64
+
+import (
+	"testing"
+)
+
+// newCostCPU returns a standalone CPU running the program prog, made of instructions of the given lengths, from
+// address 0.
+func newCostCPU(prog []uint64, size []uint64) *CPU {
+	c := NewCPU()
+	c.SetFasten(NewLinear(0x1000))
+	c.SetCSR(NewCSRStandard())
+	c.SetSystem(NewSystemStandard())
+	a := uint64(0)
+	for i, e := range prog {
+		if size[i] == 2 {
+			c.GetMemory().SetUint16(a, uint16(e))
+		} else {
+			c.GetMemory().SetUint32(a, uint32(e))
+		}
+		a += size[i]
+	}
+	c.SetRegister(Ra1, 0x800)
+	return c
+}
+
+func TestCostTable(t *testing.T) {
+	c := newCostCPU([]uint64{
+		addi(Ra0, Rzero, 5),                        // 0x00 li a0, 5
+		encodeI(0b0000011, 0b011, 0, Ra0, Ra1),     // 0x04 ld a0, 0(a1)
+		encodeS(0b011, Ra1, Ra0, 8),                // 0x08 sd a0, 8(a1)
+		0x4188,                                     // 0x0c c.lw a0, 0(a1)
+		addi(Ra7, Rzero, sysExitGroup),             // 0x0e li a7, exit_group
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero), // 0x12 ecall
+	}, []uint64{4, 4, 4, 2, 4, 4})
+	m := NewCostTable()
+	m.Opcode[0b0010011] = 2
+	m.Compressed[0b00_010] = 5
+	m.Memory = 3
+	m.Syscall = 100
+	c.SetCostModel(m)
+	c.Run()
+	if c.GetStatus() != StatusExit {
+		t.FailNow()
+	}
+	// 2 + (1 + 8*3) + (1 + 8*3) + (5 + 4*3) + 2 + (1 + 100)
+	if n := c.GetCSR().Get(CSRcycle); n != 172 {
+		t.Fatal(n)
+	}
+	if n := c.GetCSR().Get(CSRinstret); n != 6 {
+		t.Fatal(n)
+	}
+}
+
+func TestCostTableFunct(t *testing.T) {
+	c := newCostCPU(nil, nil)
+	m := NewCostTable()
+	m.Funct[CostKey(0b0110011, 0b100, 0b0000001)] = 40
+	m.Funct[CostKey(0b1010011, 0, 0b0001101)] = 20
+	m.Funct[CostKey(0b0101111, 0b011, 0b00001<<2)] = 8
+	for _, e := range []struct {
+		i uint64
+		r uint64
+	}{
+		{encodeR(0b0110011, 0b100, 0b0000001, Ra0, Ra1, Ra2), 40},        // div
+		{encodeR(0b0110011, 0b000, 0b0000000, Ra0, Ra1, Ra2), 1},         // add
+		{encodeR(0b0110011, 0b000, 0b0000001, Ra0, Ra1, Ra2), 1},         // mul
+		{encodeR(0b1010011, FRoundRNE, 0b0001101, Ra0, Ra1, Ra2), 20},    // fdiv.d
+		{encodeR(0b1010011, FRoundDYN, 0b0001101, Ra0, Ra1, Ra2), 20},    // fdiv.d, dynamic rounding
+		{encodeR(0b1010011, FRoundDYN, 0b0000001, Ra0, Ra1, Ra2), 1},     // fadd.d
+		{encodeR(0b0101111, 0b011, 0b00001<<2|0b11, Rzero, Ra1, Ra2), 8}, // amoswap.d.aqrl
+		{encodeI(0b0010011, 0b100, 0x7f, Ra0, Ra1), 1},                   // xori
+	} {
+		if r := m.Cost(c, e.i, 4); r != e.r {
+			t.Fatalf("%#08x: %d", e.i, r)
+		}
+	}
+}
+
+func TestCostTableVector(t *testing.T) {
+	c := newCostCPU(nil, nil)
+	vsetvli(t, c, 4, 64, 64)
+	m := NewCostTable()
+	m.Memory = 1
+	for _, e := range []struct {
+		i uint64
+		r uint64
+	}{
+		{encodeVMem(0b0000111, 0, 0b00, 1, 0, Ra0, 0b000, 1), 1 + 4*1},   // vle8.v
+		{encodeVMem(0b0000111, 0, 0b10, 1, Ra2, Ra0, 0b110, 1), 1 + 4*4}, // vlse32.v
+		{encodeVMem(0b0000111, 0, 0b01, 1, 2, Ra0, 0b000, 1), 1 + 4*8},   // vluxei8.v, the data is SEW wide
+		{encodeVMem(0b0100111, 1, 0b11, 1, 2, Ra0, 0b101, 1), 1 + 2*4*8}, // vsoxseg2ei16.v
+	} {
+		if r := m.Cost(c, e.i, 4); r != e.r {
+			t.Fatalf("%#08x: %d", e.i, r)
+		}
+	}
+}
+
+func TestCycleLimit(t *testing.T) {
+	loop := []uint64{encodeJ(Rzero, 0)} // j 0
+	c := newCostCPU(loop, []uint64{4})
+	c.SetCycleLimit(10)
+	if _, err := c.Run(); err != ErrOutOfGas {
+		t.Fatal(err)
+	}
+	if c.GetStatus() != StatusOutOfGas || c.GetCSR().Get(CSRcycle) != 10 || c.GetCSR().Get(CSRinstret) != 10 {
+		t.FailNow()
+	}
+	// The instruction which would exceed the limit is not executed.
+	c = newCostCPU(loop, []uint64{4})
+	m := NewCostTable()
+	m.Opcode[0b1101111] = 3
+	c.SetCostModel(m)
+	c.SetCycleLimit(10)
+	if _, err := c.Run(); err != ErrOutOfGas {
+		t.Fatal(err)
+	}
+	if c.GetStatus() != StatusOutOfGas || c.GetCSR().Get(CSRcycle) != 9 || c.GetCSR().Get(CSRinstret) != 3 {
+		t.FailNow()
+	}
+	// A hart running out of gas stops the machine.
+	for _, p := range []bool{false, true} {
+		mach := NewMachine(2)
+		mach.SetFasten(NewLinear(0x1000))
+		mach.SetSystem(NewSystemStandard())
+		mach.SetParallel(p)
+		mach.GetHart(0).GetMemory().SetUint32(0, uint32(loop[0]))
+		mach.GetHart(1).SetCycleLimit(50)
+		if _, err := mach.Run(); err != ErrOutOfGas {
+			t.Fatal(err)
+		}
+		if mach.GetHart(1).GetStatus() != StatusOutOfGas || mach.GetHart(1).GetCSR().Get(CSRcycle) != 50 {
+			t.FailNow()
+		}
+	}
+}
@@ -12,9 +12,22 @@ type CPU struct {
 	csr    CSR
 	reg0   [32]uint64
 	reg1   [32]uint64
+	reg1h  [32]uint64
 	pc     uint64
 	lraddr uint64
 	status uint64
+	paging uint64
+	isa    uint64
+	xlen   uint64
+	vlen   uint64
+	vreg   []byte
+	cbsize uint64
+	hartid uint64
+	mach   *Machine
+	cost   CostModel
+	limit  uint64
+	caches *Caches
+	origin *CPU // State right after Fork
 }
 
 func (c *CPU) GetCSR() CSR    { return c.csr }
@@ -23,11 +36,125 @@ func (c *CPU) SetCSR(csr CSR) { c.csr = csr }
 func (c *CPU) GetLoadReservation() uint64  { return c.lraddr }
 func (c *CPU) SetLoadReservation(a uint64) { c.lraddr = a }
 
-func (c *CPU) GetMemory() *Memory { return &Memory{Fasten: c.fasten} }
+func (c *CPU) GetMemory() *Memory {
+	if c.isPaging() {
+		return &Memory{Fasten: &Paging{cpu: c}}
+	}
+	if c.xlen == 32 {
+		return &Memory{Fasten: &Wrap32{Fasten: c.physical(false)}}
+	}
+	return &Memory{Fasten: c.physical(false)}
+}
+func (c *CPU) GetMemoryFetch() *Memory {
+	if c.isPaging() {
+		return &Memory{Fasten: &Paging{cpu: c, fetch: true}}
+	}
+	if c.xlen == 32 {
+		return &Memory{Fasten: &Wrap32{Fasten: c.fasten}}
+	}
+	return &Memory{Fasten: c.fasten}
+}
 func (c *CPU) SetFasten(f Fasten) { c.fasten = f }
 
-func (c *CPU) GetPC() uint64  { return c.pc }
-func (c *CPU) SetPC(i uint64) { c.pc = i }
+// physical returns the physical memory, seen through the data cache for loads and stores. Fetches are observed once
+// per instruction by Step.
+func (c *CPU) physical(fetch bool) Fasten {
+	if fetch || c.caches == nil {
+		return c.fasten
+	}
+	return &cacheFasten{Fasten: c.fasten, cpu: c}
+}
+
+// GetCaches returns the simulated cache hierarchy, or nil when the caches are not simulated.
+func (c *CPU) GetCaches() *Caches { return c.caches }
+
+// SetCaches sets the simulated cache hierarchy, nil for none. It fails with ErrCacheGeometry if the geometry of a
+// level is not valid, see NewCache.
+func (c *CPU) SetCaches(h *Caches) error {
+	if h != nil {
+		if err := h.check(); err != nil {
+			return err
+		}
+	}
+	c.caches = h
+	return nil
+}
+
+// GetPagingModes returns a bitmap of the satp MODE values supported by the CPU, bit n stands for MODE n.
+func (c *CPU) GetPagingModes() uint64  { return c.paging }
+func (c *CPU) SetPagingModes(m uint64) { c.paging = m | 1<<SatpModeBare }
+
+func (c *CPU) isPaging() bool {
+	return c.csr != nil && SatpLevels(InstructionPart(c.csr.Get(CSRsatp), 60, 63)) != 0
+}
+
+// GetISA returns a bitmap of the optional extensions enabled on the CPU, see the ISA constants.
+func (c *CPU) GetISA() uint64  { return c.isa }
+func (c *CPU) SetISA(i uint64) { c.isa = i }
+
+// GetXLEN returns the width of the integer registers, 32 or 64.
+func (c *CPU) GetXLEN() uint64 { return c.xlen }
+
+// SetXLEN switches the CPU between RV64 and RV32. On RV32 the integer registers hold sign-extended 32-bit values, the
+// pc and all addresses wrap at 32 bits, and the RV64-only instructions are illegal. Paging is not available on RV32, it
+// is disabled.
+func (c *CPU) SetXLEN(n uint64) {
+	c.xlen = n
+	if n == 32 {
+		c.paging = 1 << SatpModeBare
+	}
+}
+
+// GetHartID returns the mhartid of the CPU, harts of a Machine are numbered from 0.
+func (c *CPU) GetHartID() uint64  { return c.hartid }
+func (c *CPU) SetHartID(i uint64) { c.hartid = i }
+
+// GetMachine returns the machine hosting the CPU, or nil for a standalone CPU.
+func (c *CPU) GetMachine() *Machine { return c.mach }
+
+// GetCostModel returns the model pricing the instructions, or nil when every instruction costs the cycles reported by
+// its handler.
+func (c *CPU) GetCostModel() CostModel  { return c.cost }
+func (c *CPU) SetCostModel(m CostModel) { c.cost = m }
+
+// GetCycleLimit returns the value the cycle CSR may not exceed, 0 for no limit. An instruction which would exceed it is
+// not executed, and the CPU stops with StatusOutOfGas.
+func (c *CPU) GetCycleLimit() uint64  { return c.limit }
+func (c *CPU) SetCycleLimit(n uint64) { c.limit = n }
+
+// GetCacheBlockSize returns the size in bytes of the naturally aligned block cleared by cbo.zero, a power of two. It
+// is a property of the hart rather than of its Memory, which is only a view built over the Fasten on each access.
+func (c *CPU) GetCacheBlockSize() uint64 { return c.cbsize }
+
+// SetCacheBlockSize sets the size of the cbo.zero block, a power of two from 16 bytes to a page.
+func (c *CPU) SetCacheBlockSize(n uint64) error {
+	if n < 16 || n > 4096 || n&(n-1) != 0 {
+		return ErrCacheBlockSize
+	}
+	c.cbsize = n
+	return nil
+}
+
+// GetVLEN returns the number of bits in a vector register.
+func (c *CPU) GetVLEN() uint64 { return c.vlen }
+
+// SetVLEN sets the number of bits in a vector register, it must be a power of 2 between 64 and 65536. The vector
+// registers are cleared.
+func (c *CPU) SetVLEN(n uint64) {
+	c.vlen = n
+	c.vreg = make([]byte, 32*n/8)
+}
+
+func (c *CPU) GetRegisterVector(i uint64) []byte    { return c.vreg[i*c.vlen/8 : (i+1)*c.vlen/8] }
+func (c *CPU) SetRegisterVector(i uint64, b []byte) { copy(c.GetRegisterVector(i), b) }
+
+func (c *CPU) GetPC() uint64 { return c.pc }
+func (c *CPU) SetPC(i uint64) {
+	if c.xlen == 32 {
+		i &= 0xffffffff
+	}
+	c.pc = i
+}
 
 func (c *CPU) GetStatus() uint64  { return c.status }
 func (c *CPU) SetStatus(i uint64) { c.status = i }
@@ -39,6 +166,9 @@ func (c *CPU) SetRegister(i uint64, u uint64) {
 	if i == Rzero {
 		return
 	}
+	if c.xlen == 32 {
+		u = SignExtend(u, 31)
+	}
 	c.reg0[i] = u
 }
 func (c *CPU) GetRegister(i uint64) uint64 {
@@ -48,11 +178,40 @@ func (c *CPU) GetRegister(i uint64) uint64 {
 	return c.reg0[i]
 }
 
-func (c *CPU) SetRegisterFloat(i uint64, f uint64) { c.reg1[i] = f }
-func (c *CPU) GetRegisterFloat(i uint64) uint64    { return c.reg1[i] }
+// GetRegisterUnsigned returns the register zero-extended from XLEN bits.
+func (c *CPU) GetRegisterUnsigned(i uint64) uint64 {
+	if c.xlen == 32 {
+		return c.GetRegister(i) & 0xffffffff
+	}
+	return c.GetRegister(i)
+}
+
+// The f registers are 128 bits wide, the upper 64 bits are only visible with the Q extension. Values of 64 bits and
+// narrower are NaN-boxed into them, and reading them as a double checks the boxing only if FLEN is 128.
+
+func (c *CPU) SetRegisterFloat(i uint64, f uint64) {
+	c.reg1[i] = f
+	c.reg1h[i] = 0xffffffffffffffff
+}
+func (c *CPU) GetRegisterFloat(i uint64) uint64 { return c.reg1[i] }
 
-func (c *CPU) SetRegisterFloatAsFloat64(i uint64, f float64) { c.reg1[i] = math.Float64bits(f) }
-func (c *CPU) GetRegisterFloatAsFloat64(i uint64) float64    { return math.Float64frombits(c.reg1[i]) }
+func (c *CPU) SetRegisterFloatAsFloat64(i uint64, f float64) {
+	c.SetRegisterFloat(i, math.Float64bits(f))
+}
+func (c *CPU) GetRegisterFloatAsFloat64(i uint64) float64 {
+	if c.isa&ISAQ != 0 && c.reg1h[i] != 0xffffffffffffffff {
+		return math.Float64frombits(NaN64)
+	}
+	return math.Float64frombits(c.reg1[i])
+}
+
+func (c *CPU) SetRegisterFloatAsFloat128(i uint64, f Float128) {
+	c.reg1[i] = f.Lo
+	c.reg1h[i] = f.Hi
+}
+func (c *CPU) GetRegisterFloatAsFloat128(i uint64) Float128 {
+	return Float128{Hi: c.reg1h[i], Lo: c.reg1[i]}
+}
 
 func (c *CPU) SetRegisterFloatAsFloat32(i uint64, f float32) {
 	c.SetRegisterFloatAsFloat64(i, NaNBoxing(f))
@@ -61,6 +220,13 @@ func (c *CPU) GetRegisterFloatAsFloat32(i uint64) float32 {
 	return NaNGnixob(c.GetRegisterFloatAsFloat64(i))
 }
 
+func (c *CPU) SetRegisterFloatAsFloat16(i uint64, h uint16) {
+	c.SetRegisterFloatAsFloat64(i, NaNBoxing16(h))
+}
+func (c *CPU) GetRegisterFloatAsFloat16(i uint64) uint16 {
+	return NaNGnixob16(c.GetRegisterFloatAsFloat64(i))
+}
+
 func (c *CPU) SetFloatFlag(flag uint64, b int) {
 	if b == 0 {
 		flag = ^flag
@@ -73,6 +239,19 @@ func (c *CPU) ClrFloatFlag() {
 	c.csr.Set(CSRfcsr, c.csr.Get(CSRfcsr)&0xffffffffffffffe0)
 }
 
+// GetRoundingMode returns the rounding mode of a floating-point instruction. The dynamic rounding mode is read from
+// frm. Reserved rounding modes are illegal.
+func (c *CPU) GetRoundingMode(i uint64) (uint64, error) {
+	rm := InstructionPart(i, 12, 14)
+	if rm == FRoundDYN {
+		rm = c.csr.Get(CSRfrm)
+	}
+	if rm > FRoundRMM {
+		return 0, ErrAbnormalInstruction
+	}
+	return rm, nil
+}
+
 func (c *CPU) PushString(s string) {
 	b := append([]byte(s), 0x00)
 	c.SetRegister(Rsp, c.GetRegister(Rsp)-uint64(len(b)))
@@ -86,6 +265,13 @@ func (c *CPU) PushUint64(v uint64) {
 	c.GetMemory().SetByte(c.GetRegister(Rsp), mem)
 }
 
+func (c *CPU) PushUint32(v uint32) {
+	c.SetRegister(Rsp, c.GetRegister(Rsp)-4)
+	mem := make([]byte, 4)
+	binary.LittleEndian.PutUint32(mem, v)
+	c.GetMemory().SetByte(c.GetRegister(Rsp), mem)
+}
+
 func (c *CPU) PushUint8(v uint8) {
 	c.SetRegister(Rsp, c.GetRegister(Rsp)-1)
 	c.GetMemory().SetUint8(c.GetRegister(Rsp), 0)
@@ -100,5 +286,12 @@ func (c *CPU) LogF(n uint64) string {
 }
 
 func NewCPU() *CPU {
-	return &CPU{}
+	c := &CPU{
+		paging: 1<<SatpModeBare | 1<<SatpModeSv39 | 1<<SatpModeSv48 | 1<<SatpModeSv57,
+		isa:    ISAZba | ISAZbb | ISAZbs | ISAV | ISAZfh | ISAZfhmin | ISAQ | ISAZbkb | ISAZbkc | ISAZbkx | ISAZknd | ISAZkne | ISAZknh | ISAZicond | ISAZicbom | ISAZicboz | ISAZacas | ISAZabha,
+		xlen:   64,
+		cbsize: 64,
+	}
+	c.SetVLEN(128)
+	return c
 }
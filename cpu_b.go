@@ -0,0 +1,464 @@
+package rv64
+
+// https://github.com/riscv/riscv-bitmanip/releases/download/1.0.0/bitmanip-1.0.0-38-g865e7a7.pdf
+//
+// Zba: Address generation instructions.
+// Zbb: Basic bit-manipulation.
+// Zbs: Single-bit instructions.
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+type isaZba struct{}
+
+func (_ *isaZba) adduw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "add.uw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+uint64(uint32(a)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh1add(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh1add", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+a<<1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh2add(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh2add", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+a<<2)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh3add(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh3add", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+a<<3)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh1adduw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh1add.uw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+uint64(uint32(a))<<1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh2adduw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh2add.uw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+uint64(uint32(a))<<2)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) sh3adduw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sh3add.uw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b+uint64(uint32(a))<<3)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZba) slliuw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "slli.uw", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, uint64(uint32(a))<<shamt)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZbb struct{}
+
+func (_ *isaZbb) andn(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "andn", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a&^b)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) orn(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "orn", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a|^b)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) xnor(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "xnor", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, ^(a ^ b))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) clz(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "clz", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.LeadingZeros32(uint32(a))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, uint64(bits.LeadingZeros64(a)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) clzw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "clzw", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, uint64(bits.LeadingZeros32(uint32(a))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) ctz(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "ctz", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.TrailingZeros32(uint32(a))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, uint64(bits.TrailingZeros64(a)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) ctzw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "ctzw", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, uint64(bits.TrailingZeros32(uint32(a))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) cpop(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "cpop", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.OnesCount32(uint32(a))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, uint64(bits.OnesCount64(a)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) cpopw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "cpopw", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, uint64(bits.OnesCount32(uint32(a))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) max(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "max", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if int64(a) > int64(b) {
+		c.SetRegister(rd, a)
+	} else {
+		c.SetRegister(rd, b)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) maxu(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "maxu", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if a > b {
+		c.SetRegister(rd, a)
+	} else {
+		c.SetRegister(rd, b)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) min(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "min", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if int64(a) < int64(b) {
+		c.SetRegister(rd, a)
+	} else {
+		c.SetRegister(rd, b)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) minu(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "minu", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if a < b {
+		c.SetRegister(rd, a)
+	} else {
+		c.SetRegister(rd, b)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) sextb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sext.b", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, SignExtend(a&0xff, 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) sexth(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sext.h", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, SignExtend(a&0xffff, 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) zexth(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "zext.h", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, a&0xffff)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) rol(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "rol", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.RotateLeft32(uint32(a), int(b&0x1f))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, bits.RotateLeft64(a, int(b&0x3f)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) rolw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "rolw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(uint32(a), int(b&0x1f))), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) ror(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "ror", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.RotateLeft32(uint32(a), -int(b&0x1f))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, bits.RotateLeft64(a, -int(b&0x3f)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) rorw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "rorw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(uint32(a), -int(b&0x1f))), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) rori(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "rori", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.RotateLeft32(uint32(a), -int(shamt))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, bits.RotateLeft64(a, -int(shamt)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) roriw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 4)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "roriw", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(uint32(a), -int(shamt))), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) orcb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "orc.b", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	var r uint64 = 0
+	for j := 0; j < 64; j += 8 {
+		if a>>j&0xff != 0 {
+			r |= 0xff << j
+		}
+	}
+	c.SetRegister(rd, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbb) rev8(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "rev8", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	if c.GetXLEN() == 32 {
+		c.SetRegister(rd, uint64(bits.ReverseBytes32(uint32(a))))
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	c.SetRegister(rd, bits.ReverseBytes64(a))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZbs struct{}
+
+func (_ *isaZbs) bclr(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "bclr", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a&^(1<<(b&(c.GetXLEN()-1))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) bclri(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "bclri", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, a&^(1<<shamt))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) bext(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "bext", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a>>(b&(c.GetXLEN()-1))&1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) bexti(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "bexti", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, a>>shamt&1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) binv(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "binv", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a^(1<<(b&(c.GetXLEN()-1))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) binvi(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "binvi", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, a^(1<<shamt))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) bset(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "bset", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, a|(1<<(b&(c.GetXLEN()-1))))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbs) bseti(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	shamt := InstructionPart(imm, 0, 5)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "bseti", c.LogI(rd), c.LogI(rs1), shamt))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, a|(1<<shamt))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
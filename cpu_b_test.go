@@ -0,0 +1,139 @@
+package rv64
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func encodeR(opcode uint64, funct3 uint64, funct7 uint64, rd uint64, rs1 uint64, rs2 uint64) uint64 {
+	return funct7<<25 | rs2<<20 | rs1<<15 | funct3<<12 | rd<<7 | opcode
+}
+
+func encodeI(opcode uint64, funct3 uint64, imm uint64, rd uint64, rs1 uint64) uint64 {
+	return (imm&0xfff)<<20 | rs1<<15 | funct3<<12 | rd<<7 | opcode
+}
+
+func execute(c *CPU, i uint64) error {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(i))
+	_, err := c.PipelineExecute(data)
+	return err
+}
+
+func TestBitManipulation(t *testing.T) {
+	const (
+		op    = 0b0110011
+		op32  = 0b0111011
+		opi   = 0b0010011
+		opi32 = 0b0011011
+	)
+	r := func(opcode, funct3, funct7 uint64) uint64 { return encodeR(opcode, funct3, funct7, Ra0, Ra1, Ra2) }
+	u := func(opcode, funct3, funct7, rs2 uint64) uint64 { return encodeR(opcode, funct3, funct7, Ra0, Ra1, rs2) }
+	i := func(opcode, funct3, imm uint64) uint64 { return encodeI(opcode, funct3, imm, Ra0, Ra1) }
+	for _, e := range []struct {
+		name string
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		// Zba
+		{"add.uw", r(op32, 0b000, 0b0000100), 0xffffffff80000000, 0x1, 0x80000001},
+		{"add.uw", r(op32, 0b000, 0b0000100), 0xffffffffffffffff, 0xffffffffffffffff, 0xfffffffe},
+		{"sh1add", r(op, 0b010, 0b0010000), 0x8000000000000001, 0x10, 0x12},
+		{"sh2add", r(op, 0b100, 0b0010000), 0x3, 0x10, 0x1c},
+		{"sh3add", r(op, 0b110, 0b0010000), 0xffffffffffffffff, 0x0, 0xfffffffffffffff8},
+		{"sh1add.uw", r(op32, 0b010, 0b0010000), 0xffffffff80000000, 0x1, 0x100000001},
+		{"sh2add.uw", r(op32, 0b100, 0b0010000), 0xffffffff80000000, 0x1, 0x200000001},
+		{"sh3add.uw", r(op32, 0b110, 0b0010000), 0xffffffffffffffff, 0x0, 0x7fffffff8},
+		{"slli.uw", i(opi32, 0b001, 0b000010<<6|0), 0xffffffff80000000, 0, 0x80000000},
+		{"slli.uw", i(opi32, 0b001, 0b000010<<6|32), 0xffffffff80000001, 0, 0x8000000100000000},
+		{"slli.uw", i(opi32, 0b001, 0b000010<<6|63), 0x1, 0, 0x8000000000000000},
+		// Zbb
+		{"andn", r(op, 0b111, 0b0100000), 0xff00ff00ff00ff00, 0xf0f0f0f0f0f0f0f0, 0x0f000f000f000f00},
+		{"orn", r(op, 0b110, 0b0100000), 0x0, 0xffffffff00000000, 0x00000000ffffffff},
+		{"xnor", r(op, 0b100, 0b0100000), 0xff00ff00ff00ff00, 0xf0f0f0f0f0f0f0f0, 0xf00ff00ff00ff00f},
+		{"clz", u(opi, 0b001, 0b0110000, 0b00000), 0x0, 0, 64},
+		{"clz", u(opi, 0b001, 0b0110000, 0b00000), 0x8000000000000000, 0, 0},
+		{"clz", u(opi, 0b001, 0b0110000, 0b00000), 0x0000000000010000, 0, 47},
+		{"clzw", u(opi32, 0b001, 0b0110000, 0b00000), 0xffffffff00000000, 0, 32},
+		{"clzw", u(opi32, 0b001, 0b0110000, 0b00000), 0x0000000000000001, 0, 31},
+		{"ctz", u(opi, 0b001, 0b0110000, 0b00001), 0x0, 0, 64},
+		{"ctz", u(opi, 0b001, 0b0110000, 0b00001), 0x8000000000000000, 0, 63},
+		{"ctzw", u(opi32, 0b001, 0b0110000, 0b00001), 0xffffffff00000000, 0, 32},
+		{"ctzw", u(opi32, 0b001, 0b0110000, 0b00001), 0x0000000080000000, 0, 31},
+		{"cpop", u(opi, 0b001, 0b0110000, 0b00010), 0xffffffffffffffff, 0, 64},
+		{"cpop", u(opi, 0b001, 0b0110000, 0b00010), 0x0, 0, 0},
+		{"cpopw", u(opi32, 0b001, 0b0110000, 0b00010), 0xffffffff0000000f, 0, 4},
+		{"max", r(op, 0b110, 0b0000101), 0xffffffffffffffff, 0x1, 0x1},
+		{"max", r(op, 0b110, 0b0000101), 0x8000000000000000, 0x7fffffffffffffff, 0x7fffffffffffffff},
+		{"maxu", r(op, 0b111, 0b0000101), 0xffffffffffffffff, 0x1, 0xffffffffffffffff},
+		{"min", r(op, 0b100, 0b0000101), 0xffffffffffffffff, 0x1, 0xffffffffffffffff},
+		{"min", r(op, 0b100, 0b0000101), 0x8000000000000000, 0x7fffffffffffffff, 0x8000000000000000},
+		{"minu", r(op, 0b101, 0b0000101), 0xffffffffffffffff, 0x1, 0x1},
+		{"sext.b", u(opi, 0b001, 0b0110000, 0b00100), 0x0000000000000080, 0, 0xffffffffffffff80},
+		{"sext.b", u(opi, 0b001, 0b0110000, 0b00100), 0xffffffffffffff7f, 0, 0x7f},
+		{"sext.h", u(opi, 0b001, 0b0110000, 0b00101), 0x0000000000008000, 0, 0xffffffffffff8000},
+		{"sext.h", u(opi, 0b001, 0b0110000, 0b00101), 0xffffffffffff7fff, 0, 0x7fff},
+		{"zext.h", u(op32, 0b100, 0b0000100, 0b00000), 0xffffffffffff8000, 0, 0x8000},
+		{"rol", r(op, 0b001, 0b0110000), 0x8000000000000001, 0x1, 0x3},
+		{"rol", r(op, 0b001, 0b0110000), 0x8000000000000001, 0x40, 0x8000000000000001},
+		{"rolw", r(op32, 0b001, 0b0110000), 0x0000000080000001, 0x1, 0x3},
+		{"rolw", r(op32, 0b001, 0b0110000), 0x0000000040000000, 0x21, 0xffffffff80000000},
+		{"ror", r(op, 0b101, 0b0110000), 0x8000000000000001, 0x1, 0xc000000000000000},
+		{"ror", r(op, 0b101, 0b0110000), 0x1, 0x7f, 0x2},
+		{"rorw", r(op32, 0b101, 0b0110000), 0x0000000000000001, 0x1, 0xffffffff80000000},
+		{"rorw", r(op32, 0b101, 0b0110000), 0xffffffff00000002, 0x21, 0x1},
+		{"rori", i(opi, 0b101, 0b011000<<6|1), 0x8000000000000001, 0, 0xc000000000000000},
+		{"rori", i(opi, 0b101, 0b011000<<6|63), 0x8000000000000001, 0, 0x0000000000000003},
+		{"roriw", i(opi32, 0b101, 0b0110000<<5|1), 0x0000000000000001, 0, 0xffffffff80000000},
+		{"roriw", i(opi32, 0b101, 0b0110000<<5|31), 0x0000000040000000, 0, 0xffffffff80000000},
+		{"orc.b", i(opi, 0b101, 0b001010000111), 0x0001020000ff0080, 0, 0x00ffff0000ff00ff},
+		{"rev8", i(opi, 0b101, 0b011010111000), 0x0102030405060708, 0, 0x0807060504030201},
+		// Zbs
+		{"bclr", r(op, 0b001, 0b0100100), 0xffffffffffffffff, 0x3f, 0x7fffffffffffffff},
+		{"bclr", r(op, 0b001, 0b0100100), 0xffffffffffffffff, 0x40, 0xfffffffffffffffe},
+		{"bclri", i(opi, 0b001, 0b010010<<6|63), 0xffffffffffffffff, 0, 0x7fffffffffffffff},
+		{"bext", r(op, 0b101, 0b0100100), 0x8000000000000000, 0x3f, 0x1},
+		{"bext", r(op, 0b101, 0b0100100), 0x8000000000000000, 0x7e, 0x0},
+		{"bexti", i(opi, 0b101, 0b010010<<6|63), 0x8000000000000000, 0, 0x1},
+		{"binv", r(op, 0b001, 0b0110100), 0x1, 0x0, 0x0},
+		{"binv", r(op, 0b001, 0b0110100), 0x1, 0x3f, 0x8000000000000001},
+		{"binvi", i(opi, 0b001, 0b011010<<6|32), 0x0, 0, 0x100000000},
+		{"bset", r(op, 0b001, 0b0010100), 0x0, 0x41, 0x2},
+		{"bseti", i(opi, 0b001, 0b001010<<6|63), 0x0, 0, 0x8000000000000000},
+	} {
+		c := NewCPU()
+		c.SetRegister(Ra1, e.a)
+		c.SetRegister(Ra2, e.b)
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if c.GetRegister(Ra0) != e.r {
+			t.Fatalf("%s %#x %#x: %#x != %#x", e.name, e.a, e.b, c.GetRegister(Ra0), e.r)
+		}
+		if c.GetPC() != 4 {
+			t.Fatal(e.name)
+		}
+	}
+}
+
+func TestBitManipulationDisabled(t *testing.T) {
+	for _, e := range []struct {
+		isa uint64
+		i   uint64
+	}{
+		{ISAZba, encodeR(0b0110011, 0b010, 0b0010000, Ra0, Ra1, Ra2)},
+		{ISAZbb, encodeR(0b0010011, 0b001, 0b0110000, Ra0, Ra1, 0)},
+		{ISAZbs, encodeR(0b0110011, 0b001, 0b0010100, Ra0, Ra1, Ra2)},
+	} {
+		c := NewCPU()
+		if err := execute(c, e.i); err != nil {
+			t.FailNow()
+		}
+		c.SetISA(c.GetISA() &^ e.isa)
+		if err := execute(c, e.i); err != ErrAbnormalInstruction {
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rv64
+
+// RV32E and RV64E reduce the integer register file to x0-x15. An instruction naming x16-x31 is illegal, the register
+// fields are checked before it executes so that it has no side effect.
+
+// usesUpperRegister reports whether the instruction i of length l names one of the integer registers x16-x31. Fields
+// holding a floating-point or vector register, or an immediate, are not counted.
+func (c *CPU) usesUpperRegister(i uint64, l int) bool {
+	var (
+		rd  = InstructionPart(i, 7, 11)
+		rs1 = InstructionPart(i, 15, 19)
+		rs2 = InstructionPart(i, 20, 24)
+	)
+	upper := func(r ...uint64) bool {
+		for _, e := range r {
+			if e >= 16 {
+				return true
+			}
+		}
+		return false
+	}
+	if l == 2 {
+		// The 3-bit register fields always name x8-x15.
+		rs2 = InstructionPart(i, 2, 6)
+		switch InstructionPart(i, 0, 1)<<3 | InstructionPart(i, 13, 15) {
+		case 0b01_000, 0b01_010, 0b01_011, 0b10_000, 0b10_010:
+			return upper(rd)
+		case 0b01_001, 0b10_011:
+			// c.addiw and c.ldsp on RV64, c.jal and c.flwsp on RV32.
+			return c.GetXLEN() == 64 && upper(rd)
+		case 0b10_100:
+			return upper(rd, rs2)
+		case 0b10_110:
+			return upper(rs2)
+		case 0b10_111:
+			// c.sdsp on RV64, c.fswsp on RV32.
+			return c.GetXLEN() == 64 && upper(rs2)
+		}
+		return false
+	}
+	funct3 := InstructionPart(i, 12, 14)
+	switch InstructionPart(i, 0, 6) {
+	case 0b0110111, 0b0010111, 0b1101111:
+		return upper(rd)
+	case 0b1100111, 0b0000011, 0b0010011, 0b0011011:
+		return upper(rd, rs1)
+	case 0b1100011, 0b0100011:
+		return upper(rs1, rs2)
+	case 0b0001111:
+		// The cache-block operations take their address from rs1.
+		return funct3 == 0b010 && upper(rs1)
+	case 0b0110011, 0b0111011, 0b0101111:
+		return upper(rd, rs1, rs2)
+	case 0b1110011:
+		switch funct3 {
+		case 0b000:
+			return upper(rs1, rs2)
+		case 0b001, 0b010, 0b011:
+			return upper(rd, rs1)
+		case 0b101, 0b110, 0b111:
+			return upper(rd)
+		}
+	case 0b0000111, 0b0100111:
+		switch funct3 {
+		case 0b001, 0b010, 0b011, 0b100:
+			return upper(rs1)
+		}
+		// Strided vector accesses take the stride from rs2, indexed ones an index vector.
+		if InstructionPart(i, 26, 27) == 0b10 {
+			return upper(rs1, rs2)
+		}
+		return upper(rs1)
+	case 0b1010011:
+		switch InstructionPart(i, 27, 31) {
+		case 0b10100, 0b11000, 0b11100:
+			return upper(rd)
+		case 0b11010, 0b11110:
+			return upper(rs1)
+		}
+	case 0b1010111:
+		switch funct3 {
+		case vOPCFG:
+			switch {
+			case InstructionPart(i, 31, 31) == 0b0:
+				return upper(rd, rs1)
+			case InstructionPart(i, 30, 31) == 0b11:
+				return upper(rd)
+			}
+			return upper(rd, rs1, rs2)
+		case vOPIVX, vOPMVX:
+			return upper(rs1)
+		case vOPMVV:
+			// vmv.x.s, vcpop.m and vfirst.m write an integer register.
+			return InstructionPart(i, 26, 31) == 0b010000 && upper(rd)
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func TestEmbedded(t *testing.T) {
+	c := newRV32CPU()
+	c.SetISA(c.GetISA() | ISAE)
+	c.SetRegister(Ra1, 0x100)
+	c.SetRegister(16, 0x42)
+	for _, e := range []struct {
+		name string
+		i    uint64
+		err  error
+	}{
+		{"add a0, a1, a2", encodeR(0b0110011, 0b000, 0b0000000, Ra0, Ra1, Ra2), nil},
+		{"add a0, a1, a6", encodeR(0b0110011, 0b000, 0b0000000, Ra0, Ra1, 16), ErrAbnormalInstruction},
+		{"addi a7, a1, 1", encodeI(0b0010011, 0b000, 1, Ra7, Ra1), ErrAbnormalInstruction},
+		{"sw a6, 0(a1)", 16<<20 | Ra1<<15 | 0b010<<12 | 0b0100011, ErrAbnormalInstruction},
+		{"csrrwi a0, fflags, 20", encodeI(0b1110011, 0b101, CSRfflags, Ra0, 20), nil},
+		{"csrrw a0, fflags, s2", encodeI(0b1110011, 0b001, CSRfflags, Ra0, 18), ErrAbnormalInstruction},
+		{"fadd.s f16, f17, f31", encodeR(0b1010011, 0b000, 0b0000000, 16, 17, 31), nil},
+		{"fmv.x.w a6, f0", encodeR(0b1010011, 0b000, 0b1110000, 16, 0, 0), ErrAbnormalInstruction},
+		{"fcvt.s.w f0, a6", encodeR(0b1010011, 0b000, 0b1101000, 0, 16, 0), ErrAbnormalInstruction},
+		{"flw f20, 0(a1)", encodeI(0b0000111, 0b010, 0, 20, Ra1), nil},
+	} {
+		if err := execute(c, e.i); err != e.err {
+			t.Fatalf("%s: %v", e.name, err)
+		}
+	}
+	if v, _ := c.GetMemory().GetUint32(0x100); v != 0 {
+		t.FailNow()
+	}
+	for _, e := range []struct {
+		name string
+		i    uint16
+		err  error
+	}{
+		{"c.mv a0, a1", 0x852e, nil},
+		{"c.mv a6, a0", 0x882a, ErrAbnormalInstruction},
+		{"c.fldsp f20, 0(sp)", 0x2a02, nil},
+	} {
+		if _, err := c.PipelineExecute([]byte{byte(e.i), byte(e.i >> 8)}); err != e.err {
+			t.Fatalf("%s: %v", e.name, err)
+		}
+	}
+	// The exit syscall number is passed in t0.
+	s := NewSystemStandard()
+	c.SetSystem(s)
+	c.SetRegister(Rt0, 93)
+	c.SetRegister(Ra0, 7)
+	if _, err := s.HandleCall(c); err != nil || s.Code() != 7 {
+		t.FailNow()
+	}
+}
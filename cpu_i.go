@@ -355,7 +355,7 @@ func (_ *isaI) srli(c *CPU, i uint64) (uint64, error) {
 	imm = SignExtend(imm, 11)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "srli", c.LogI(rd), c.LogI(rs1), imm))
 	shamt := imm & 0x3f
-	c.SetRegister(rd, c.GetRegister(rs1)>>shamt)
+	c.SetRegister(rd, c.GetRegisterUnsigned(rs1)>>shamt)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -389,7 +389,7 @@ func (_ *isaI) sub(c *CPU, i uint64) (uint64, error) {
 func (_ *isaI) sll(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sll", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
-	c.SetRegister(rd, c.GetRegister(rs1)<<(c.GetRegister(rs2)&0x3f))
+	c.SetRegister(rd, c.GetRegister(rs1)<<(c.GetRegister(rs2)&(c.GetXLEN()-1)))
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -429,14 +429,14 @@ func (_ *isaI) xor(c *CPU, i uint64) (uint64, error) {
 func (_ *isaI) srl(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "srl", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
-	c.SetRegister(rd, c.GetRegister(rs1)>>(c.GetRegister(rs2)&0x3f))
+	c.SetRegister(rd, c.GetRegisterUnsigned(rs1)>>(c.GetRegister(rs2)&(c.GetXLEN()-1)))
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
 func (_ *isaI) sra(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sra", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
-	c.SetRegister(rd, uint64(int64(c.GetRegister(rs1))>>(c.GetRegister(rs2)&0x3f)))
+	c.SetRegister(rd, uint64(int64(c.GetRegister(rs1))>>(c.GetRegister(rs2)&(c.GetXLEN()-1))))
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -576,11 +576,11 @@ func (_ *isaZicsr) csrrw(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s csr: ----(%#016x)", c.GetPC(), "csrrw", c.LogI(rd), c.LogI(rs1), csr))
 	a := c.GetRegister(rs1)
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	if rd != Rzero {
 		c.SetRegister(rd, b)
 	}
-	c.GetCSR().Set(csr, a)
+	c.WriteCSR(csr, a)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -589,10 +589,10 @@ func (_ *isaZicsr) csrrs(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s csr: ----(%#016x)", c.GetPC(), "csrrs", c.LogI(rd), c.LogI(rs1), csr))
 	a := c.GetRegister(rs1)
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	c.SetRegister(rd, b)
 	if rs1 != Rzero {
-		c.GetCSR().Set(csr, b|a)
+		c.WriteCSR(csr, b|a)
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -602,10 +602,10 @@ func (_ *isaZicsr) csrrc(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s csr: ----(%#016x)", c.GetPC(), "csrrc", c.LogI(rd), c.LogI(rs1), csr))
 	a := c.GetRegister(rs1)
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	c.SetRegister(rd, b)
 	if rs1 != Rzero {
-		c.GetCSR().Set(csr, b&^a)
+		c.WriteCSR(csr, b&^a)
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -614,11 +614,11 @@ func (_ *isaZicsr) csrrc(c *CPU, i uint64) (uint64, error) {
 func (_ *isaZicsr) csrrwi(c *CPU, i uint64) (uint64, error) {
 	rd, imm, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x) csr: ----(%#016x)", c.GetPC(), "csrrwi", c.LogI(rd), imm, csr))
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	if rd != Rzero {
 		c.SetRegister(rd, b)
 	}
-	c.GetCSR().Set(csr, imm)
+	c.WriteCSR(csr, imm)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -626,10 +626,10 @@ func (_ *isaZicsr) csrrwi(c *CPU, i uint64) (uint64, error) {
 func (_ *isaZicsr) csrrsi(c *CPU, i uint64) (uint64, error) {
 	rd, imm, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x) csr: ----(%#016x)", c.GetPC(), "csrrsi", c.LogI(rd), imm, csr))
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	c.SetRegister(rd, b)
 	if csr != 0x00 {
-		c.GetCSR().Set(csr, b|imm)
+		c.WriteCSR(csr, b|imm)
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -638,10 +638,10 @@ func (_ *isaZicsr) csrrsi(c *CPU, i uint64) (uint64, error) {
 func (_ *isaZicsr) csrrci(c *CPU, i uint64) (uint64, error) {
 	rd, imm, csr := IType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x) csr: ----(%#016x)", c.GetPC(), "csrrci", c.LogI(rd), imm, csr))
-	b := c.GetCSR().Get(csr)
+	b := c.ReadCSR(csr)
 	c.SetRegister(rd, b)
 	if csr != 0x00 {
-		c.GetCSR().Set(csr, b&^imm)
+		c.WriteCSR(csr, b&^imm)
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -661,6 +661,9 @@ func (_ *isaM) mulh(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "mulh", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
 	v := func() uint64 {
+		if c.GetXLEN() == 32 {
+			return uint64((int64(c.GetRegister(rs1)) * int64(c.GetRegister(rs2))) >> 32)
+		}
 		ag1 := big.NewInt(int64(c.GetRegister(rs1)))
 		ag2 := big.NewInt(int64(c.GetRegister(rs2)))
 		tmp := big.NewInt(0)
@@ -677,6 +680,9 @@ func (_ *isaM) mulhsu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "mulhsu", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
 	v := func() uint64 {
+		if c.GetXLEN() == 32 {
+			return uint64((int64(c.GetRegister(rs1)) * int64(c.GetRegisterUnsigned(rs2))) >> 32)
+		}
 		ag1 := big.NewInt(int64(c.GetRegister(rs1)))
 		ag2 := big.NewInt(int64(c.GetRegister(rs2)))
 		if ag2.Cmp(big.NewInt(0)) == -1 {
@@ -699,6 +705,9 @@ func (_ *isaM) mulhu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "mulhu", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
 	v := func() uint64 {
+		if c.GetXLEN() == 32 {
+			return (c.GetRegisterUnsigned(rs1) * c.GetRegisterUnsigned(rs2)) >> 32
+		}
 		ag1 := big.NewInt(int64(c.GetRegister(rs1)))
 		ag2 := big.NewInt(int64(c.GetRegister(rs2)))
 		if ag1.Cmp(big.NewInt(0)) == -1 {
@@ -741,7 +750,7 @@ func (_ *isaM) divu(c *CPU, i uint64) (uint64, error) {
 	if c.GetRegister(rs2) == 0 {
 		c.SetRegister(rd, math.MaxUint64)
 	} else {
-		c.SetRegister(rd, c.GetRegister(rs1)/c.GetRegister(rs2))
+		c.SetRegister(rd, c.GetRegisterUnsigned(rs1)/c.GetRegisterUnsigned(rs2))
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -765,7 +774,7 @@ func (_ *isaM) remu(c *CPU, i uint64) (uint64, error) {
 	if c.GetRegister(rs2) == 0 {
 		c.SetRegister(rd, c.GetRegister(rs1))
 	} else {
-		c.SetRegister(rd, c.GetRegister(rs1)%c.GetRegister(rs2))
+		c.SetRegister(rd, c.GetRegisterUnsigned(rs1)%c.GetRegisterUnsigned(rs2))
 	}
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
@@ -1186,6 +1195,433 @@ func (_ *isaA) amomaxud(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+type isaZacas struct{}
+
+// The double-width forms operate on an even-odd register pair, the even register holds the low half. The pair
+// starting at x0 reads as zero and is never written.
+
+func getRegisterPair(c *CPU, i uint64) (uint64, uint64) {
+	if i == Rzero {
+		return 0, 0
+	}
+	return c.GetRegisterUnsigned(i), c.GetRegisterUnsigned(i + 1)
+}
+
+func setRegisterPair(c *CPU, i uint64, lo uint64, hi uint64) {
+	if i == Rzero {
+		return
+	}
+	c.SetRegister(i, lo)
+	c.SetRegister(i+1, hi)
+}
+
+func (_ *isaZacas) amocasw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amocas.w", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint32(a)
+	if err != nil {
+		return 0, err
+	}
+	if v == uint32(c.GetRegister(rd)) {
+		c.GetMemory().SetUint32(a, uint32(c.GetRegister(rs2)))
+	}
+	c.SetRegister(rd, SignExtend(uint64(v), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZacas) amocasd(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amocas.d", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	if c.GetXLEN() == 32 && (rd&1 != 0 || rs2&1 != 0) {
+		return 0, ErrAbnormalInstruction
+	}
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint64(a)
+	if err != nil {
+		return 0, err
+	}
+	if c.GetXLEN() == 32 {
+		cl, ch := getRegisterPair(c, rd)
+		sl, sh := getRegisterPair(c, rs2)
+		if v == ch<<32|cl {
+			c.GetMemory().SetUint64(a, sh<<32|sl)
+		}
+		setRegisterPair(c, rd, v&0xffffffff, v>>32)
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	if v == c.GetRegister(rd) {
+		c.GetMemory().SetUint64(a, c.GetRegister(rs2))
+	}
+	c.SetRegister(rd, v)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZacas) amocasq(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amocas.q", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	if rd&1 != 0 || rs2&1 != 0 {
+		return 0, ErrAbnormalInstruction
+	}
+	a := c.GetRegister(rs1)
+	vl, err := c.GetMemory().GetUint64(a)
+	if err != nil {
+		return 0, err
+	}
+	vh, err := c.GetMemory().GetUint64(a + 8)
+	if err != nil {
+		return 0, err
+	}
+	cl, ch := getRegisterPair(c, rd)
+	if vl == cl && vh == ch {
+		sl, sh := getRegisterPair(c, rs2)
+		c.GetMemory().SetUint64(a, sl)
+		c.GetMemory().SetUint64(a+8, sh)
+	}
+	setRegisterPair(c, rd, vl, vh)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZacas) amocasb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amocas.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	if v == uint8(c.GetRegister(rd)) {
+		c.GetMemory().SetUint8(a, uint8(c.GetRegister(rs2)))
+	}
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZacas) amocash(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amocas.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	if v == uint16(c.GetRegister(rd)) {
+		c.GetMemory().SetUint16(a, uint16(c.GetRegister(rs2)))
+	}
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZabha struct{}
+
+func (_ *isaZabha) amoswapb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoswap.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	c.GetMemory().SetUint8(a, b)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoaddb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoadd.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	c.GetMemory().SetUint8(a, v+b)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoxorb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoxor.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	c.GetMemory().SetUint8(a, v^b)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoandb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoand.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	c.GetMemory().SetUint8(a, v&b)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoorb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoor.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	c.GetMemory().SetUint8(a, v|b)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amominb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomin.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	r := b
+	if int8(v) < int8(b) {
+		r = v
+	}
+	c.GetMemory().SetUint8(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amomaxb(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomax.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	r := b
+	if int8(v) > int8(b) {
+		r = v
+	}
+	c.GetMemory().SetUint8(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amominub(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amominu.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	r := b
+	if v < b {
+		r = v
+	}
+	c.GetMemory().SetUint8(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amomaxub(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomaxu.b", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint8(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint8(c.GetRegister(rs2))
+	r := b
+	if v > b {
+		r = v
+	}
+	c.GetMemory().SetUint8(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 7))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoswaph(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoswap.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	c.GetMemory().SetUint16(a, b)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoaddh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoadd.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	c.GetMemory().SetUint16(a, v+b)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoxorh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoxor.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	c.GetMemory().SetUint16(a, v^b)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoandh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoand.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	c.GetMemory().SetUint16(a, v&b)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amoorh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amoor.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	c.GetMemory().SetUint16(a, v|b)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amominh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomin.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	r := b
+	if int16(v) < int16(b) {
+		r = v
+	}
+	c.GetMemory().SetUint16(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amomaxh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomax.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	r := b
+	if int16(v) > int16(b) {
+		r = v
+	}
+	c.GetMemory().SetUint16(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amominuh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amominu.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	r := b
+	if v < b {
+		r = v
+	}
+	c.GetMemory().SetUint16(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZabha) amomaxuh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "amomaxu.h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	b := uint16(c.GetRegister(rs2))
+	r := b
+	if v > b {
+		r = v
+	}
+	c.GetMemory().SetUint16(a, r)
+	c.SetRegister(rd, SignExtend(uint64(v), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
 type isaF struct{}
 
 func (_ *isaF) flw(c *CPU, i uint64) (uint64, error) {
@@ -1217,15 +1653,16 @@ func (_ *isaF) fsw(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fmadds(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fmadd.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	d := c.GetRegisterFloatAsFloat32(rs3)
-	r := a*b + d
-	c.SetRegisterFloatAsFloat32(rd, r)
-	if r-d != a*b || r-a*b != d || (r-d)/a != b || (r-d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	d := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs3)))
+	r, flags := SoftMulAdd(FloatFormatS, a, b, d, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1233,15 +1670,16 @@ func (_ *isaF) fmadds(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fmsubs(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fmsub.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	d := c.GetRegisterFloatAsFloat32(rs3)
-	r := a*b - d
-	c.SetRegisterFloatAsFloat32(rd, r)
-	if r+d != a*b || a*b-r != d || (r+d)/a != b || (r+d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	d := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs3)))
+	r, flags := SoftMulAdd(FloatFormatS, a, b, d^1<<31, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1249,15 +1687,16 @@ func (_ *isaF) fmsubs(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fnmsubs(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fnmsub.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	d := c.GetRegisterFloatAsFloat32(rs3)
-	r := a*b - d
-	c.SetRegisterFloatAsFloat32(rd, -r)
-	if r+d != a*b || a*b-r != d || (r+d)/a != b || (r+d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	d := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs3)))
+	r, flags := SoftMulAdd(FloatFormatS, a^1<<31, b, d, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1265,15 +1704,16 @@ func (_ *isaF) fnmsubs(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fnmadds(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fnmadd.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	d := c.GetRegisterFloatAsFloat32(rs3)
-	r := a*b + d
-	c.SetRegisterFloatAsFloat32(rd, -r)
-	if r-d != a*b || r-a*b != d || (r-d)/a != b || (r-d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	d := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs3)))
+	r, flags := SoftMulAdd(FloatFormatS, a^1<<31, b, d^1<<31, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1281,14 +1721,15 @@ func (_ *isaF) fnmadds(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fadds(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fadd.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
-	d := a + b
-	c.SetRegisterFloatAsFloat32(rd, d)
-	if d-a != b || d-b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	r, flags := SoftAdd(FloatFormatS, a, b, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1296,20 +1737,15 @@ func (_ *isaF) fadds(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fsubs(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsub.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
-	if (math.Signbit(float64(a)) == math.Signbit(float64(b))) && math.IsInf(float64(a), 0) && math.IsInf(float64(b), 0) {
-		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	d := a - b
-	c.SetRegisterFloatAsFloat32(rd, d)
-	if a-d != b || b+d != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	r, flags := SoftSub(FloatFormatS, a, b, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1317,14 +1753,15 @@ func (_ *isaF) fsubs(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fmuls(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmul.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
-	d := a * b
-	c.SetRegisterFloatAsFloat32(rd, d)
-	if d/a != b || d/b != a || float64(a)*float64(b) != float64(d) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	r, flags := SoftMul(FloatFormatS, a, b, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1332,20 +1769,15 @@ func (_ *isaF) fmuls(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fdivs(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fdiv.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
-	if b == 0 {
-		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
-		c.SetFloatFlag(FFlagsDZ, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	d := a / b
-	c.SetRegisterFloatAsFloat32(rd, d)
-	if a/d != b || b*d != a || float64(b)*float64(d) != float64(a) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	b := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs2)))
+	r, flags := SoftDiv(FloatFormatS, a, b, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1353,19 +1785,14 @@ func (_ *isaF) fdivs(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fsqrts(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsqrt.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat32(rs1)
-	c.ClrFloatFlag()
-	if a < 0 {
-		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	d := float32(math.Sqrt(float64(a)))
-	c.SetRegisterFloatAsFloat32(rd, d)
-	if a/d != d || d*d != a || float64(d)*float64(d) != float64(a) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftSqrt(FloatFormatS, a, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1417,8 +1844,10 @@ func (_ *isaF) fmins(c *CPU, i uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmin.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
 	a := c.GetRegisterFloatAsFloat32(rs1)
 	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
 	if math.IsNaN(float64(a)) && math.IsNaN(float64(b)) {
+		if IsSNaN32(a) || IsSNaN32(b) {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
 		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
 		c.SetPC(c.GetPC() + 4)
 		return 1, nil
@@ -1453,8 +1882,10 @@ func (_ *isaF) fmaxs(c *CPU, i uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmax.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
 	a := c.GetRegisterFloatAsFloat32(rs1)
 	b := c.GetRegisterFloatAsFloat32(rs2)
-	c.ClrFloatFlag()
 	if math.IsNaN(float64(a)) && math.IsNaN(float64(b)) {
+		if IsSNaN32(a) || IsSNaN32(b) {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
 		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
 		c.SetPC(c.GetPC() + 4)
 		return 1, nil
@@ -1477,38 +1908,24 @@ func (_ *isaF) fmaxs(c *CPU, i uint64) (uint64, error) {
 	}
 	if (!math.Signbit(float64(a)) && math.Signbit(float64(b))) || a > b {
 		c.SetRegisterFloatAsFloat32(rd, a)
-	} else {
-		c.SetRegisterFloatAsFloat32(rd, b)
-	}
-	c.SetPC(c.GetPC() + 4)
-	return 1, nil
-}
-
-func (_ *isaF) fcvtws(c *CPU, i uint64) (uint64, error) {
-	rd, rs1, rs2 := RType(i)
-	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.w.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat32(rs1)
-	if math.IsNaN(float64(d)) {
-		c.SetRegister(rd, 0x7fffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float32(math.MaxInt32) {
-		c.SetRegister(rd, SignExtend(0x7fffffff, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d < float32(math.MinInt32) {
-		c.SetRegister(rd, SignExtend(0x80000000, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
+	} else {
+		c.SetRegisterFloatAsFloat32(rd, b)
 	}
-	c.SetRegister(rd, SignExtend(uint64(int32(d)), 31))
-	if math.Ceil(float64(d)) != float64(d) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaF) fcvtws(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.w.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftToInt(FloatFormatS, a, true, 32, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1516,28 +1933,14 @@ func (_ *isaF) fcvtws(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtwus(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.wu.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat32(rs1)
-	if math.IsNaN(float64(d)) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float32(math.MaxUint32) {
-		c.SetRegister(rd, SignExtend(0xffffffff, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d <= float32(-1) {
-		c.SetRegister(rd, SignExtend(0x00000000, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, SignExtend(uint64(uint32(d)), 31))
-	if math.Ceil(float64(d)) != float64(d) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftToInt(FloatFormatS, a, false, 32, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1622,7 +2025,13 @@ func (_ *isaF) fclasss(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtsw(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.w", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat32(rd, float32(int32(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatS, c.GetRegister(rs1), true, 32, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1630,7 +2039,13 @@ func (_ *isaF) fcvtsw(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtswu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.wu", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat32(rd, float32(uint32(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatS, c.GetRegister(rs1), false, 32, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1646,28 +2061,14 @@ func (_ *isaF) fmvwx(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtls(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.l.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat32(rs1)
-	if math.IsNaN(float64(d)) {
-		c.SetRegister(rd, 0x7fffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float32(math.MaxInt64) {
-		c.SetRegister(rd, 0x7fffffffffffffff)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d < float32(math.MinInt64) {
-		c.SetRegister(rd, 0x8000000000000000)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, uint64(int64(d)))
-	if math.Ceil(float64(d)) != float64(d) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftToInt(FloatFormatS, a, true, 64, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1675,28 +2076,14 @@ func (_ *isaF) fcvtls(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtlus(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.lu.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat32(rs1)
-	if math.IsNaN(float64(d)) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float32(math.MaxUint64) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d <= float32(-1) {
-		c.SetRegister(rd, 0x0000000000000000)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, uint64(d))
-	if math.Ceil(float64(d)) != float64(d) {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftToInt(FloatFormatS, a, false, 64, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1704,7 +2091,13 @@ func (_ *isaF) fcvtlus(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtsl(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.l", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat32(rd, float32(int64(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatS, c.GetRegister(rs1), true, 64, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1712,7 +2105,13 @@ func (_ *isaF) fcvtsl(c *CPU, i uint64) (uint64, error) {
 func (_ *isaF) fcvtslu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.lu", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat32(rd, float32(uint64(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatS, c.GetRegister(rs1), false, 64, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1748,15 +2147,16 @@ func (_ *isaD) fsd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fmaddd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fmadd.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	d := c.GetRegisterFloatAsFloat64(rs3)
-	r := a*b + d
-	c.SetRegisterFloatAsFloat64(rd, r)
-	if r-d != a*b || r-a*b != d || (r-d)/a != b || (r-d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	d := math.Float64bits(c.GetRegisterFloatAsFloat64(rs3))
+	r, flags := SoftMulAdd(FloatFormatD, a, b, d, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1764,15 +2164,16 @@ func (_ *isaD) fmaddd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fmsubd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fmsub.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	d := c.GetRegisterFloatAsFloat64(rs3)
-	r := a*b - d
-	c.SetRegisterFloatAsFloat64(rd, r)
-	if r+d != a*b || a*b-r != d || (r+d)/a != b || (r+d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	d := math.Float64bits(c.GetRegisterFloatAsFloat64(rs3))
+	r, flags := SoftMulAdd(FloatFormatD, a, b, d^1<<63, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1780,15 +2181,16 @@ func (_ *isaD) fmsubd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fnmsubd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fnmsub.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	d := c.GetRegisterFloatAsFloat64(rs3)
-	r := a*b - d
-	c.SetRegisterFloatAsFloat64(rd, -r)
-	if r+d != a*b || a*b-r != d || (r+d)/a != b || (r+d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	d := math.Float64bits(c.GetRegisterFloatAsFloat64(rs3))
+	r, flags := SoftMulAdd(FloatFormatD, a^1<<63, b, d, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1796,15 +2198,16 @@ func (_ *isaD) fnmsubd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fnmaddd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2, rs3 := R4Type(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), "fnmadd.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
-	c.ClrFloatFlag()
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	d := c.GetRegisterFloatAsFloat64(rs3)
-	r := a*b + d
-	c.SetRegisterFloatAsFloat64(rd, -r)
-	if r-d != a*b || r-a*b != d || (r-d)/a != b || (r-d)/b != a {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	d := math.Float64bits(c.GetRegisterFloatAsFloat64(rs3))
+	r, flags := SoftMulAdd(FloatFormatD, a^1<<63, b, d^1<<63, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1812,13 +2215,15 @@ func (_ *isaD) fnmaddd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) faddd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fadd.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
-	c.SetRegisterFloatAsFloat64(rd, a+b)
-	if big.NewFloat(0).Add(big.NewFloat(a), big.NewFloat(b)).Acc() != big.Exact {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	r, flags := SoftAdd(FloatFormatD, a, b, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1826,19 +2231,15 @@ func (_ *isaD) faddd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fsubd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsub.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
-	if (math.Signbit(a) == math.Signbit(b)) && math.IsInf(a, 0) && math.IsInf(b, 0) {
-		c.SetRegisterFloat(rd, NaN64)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegisterFloatAsFloat64(rd, a-b)
-	if big.NewFloat(0).Sub(big.NewFloat(a), big.NewFloat(b)).Acc() != big.Exact {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	r, flags := SoftSub(FloatFormatD, a, b, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1846,13 +2247,15 @@ func (_ *isaD) fsubd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fmuld(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmul.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
-	c.SetRegisterFloatAsFloat64(rd, a*b)
-	if big.NewFloat(0).Add(big.NewFloat(a), big.NewFloat(b)).Acc() != big.Exact {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	r, flags := SoftMul(FloatFormatD, a, b, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1860,19 +2263,15 @@ func (_ *isaD) fmuld(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fdivd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fdiv.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
-	if b == 0 {
-		c.SetRegisterFloat(rd, NaN64)
-		c.SetFloatFlag(FFlagsDZ, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegisterFloatAsFloat64(rd, a/b)
-	if big.NewFloat(0).Quo(big.NewFloat(a), big.NewFloat(b)).Acc() != big.Exact {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	b := math.Float64bits(c.GetRegisterFloatAsFloat64(rs2))
+	r, flags := SoftDiv(FloatFormatD, a, b, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1880,19 +2279,14 @@ func (_ *isaD) fdivd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fsqrtd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsqrt.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	a := c.GetRegisterFloatAsFloat64(rs1)
-	c.ClrFloatFlag()
-	if a < 0 {
-		c.SetRegisterFloat(rd, NaN64)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegisterFloatAsFloat64(rd, math.Sqrt(a))
-	d := big.NewFloat(0).Sqrt(big.NewFloat(a))
-	if big.NewFloat(0).Mul(d, d).Acc() != big.Exact {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftSqrt(FloatFormatD, a, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -1944,8 +2338,10 @@ func (_ *isaD) fmind(c *CPU, i uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmin.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
 	a := c.GetRegisterFloatAsFloat64(rs1)
 	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
 	if math.IsNaN(a) && math.IsNaN(b) {
+		if IsSNaN64(a) || IsSNaN64(b) {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
 		c.SetRegisterFloat(rd, NaN64)
 		c.SetPC(c.GetPC() + 4)
 		return 1, nil
@@ -1980,8 +2376,10 @@ func (_ *isaD) fmaxd(c *CPU, i uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmax.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
 	a := c.GetRegisterFloatAsFloat64(rs1)
 	b := c.GetRegisterFloatAsFloat64(rs2)
-	c.ClrFloatFlag()
 	if math.IsNaN(a) && math.IsNaN(b) {
+		if IsSNaN64(a) || IsSNaN64(b) {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
 		c.SetRegisterFloat(rd, NaN64)
 		c.SetPC(c.GetPC() + 4)
 		return 1, nil
@@ -2014,12 +2412,14 @@ func (_ *isaD) fmaxd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtsd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat64(rs1)
-	if math.IsNaN(d) {
-		c.SetRegisterFloat(rd, 0xffffffff00000000|uint64(NaN32))
-	} else {
-		c.SetRegisterFloatAsFloat32(rd, float32(d))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftConvert(FloatFormatD, FloatFormatS, a, rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2027,12 +2427,14 @@ func (_ *isaD) fcvtsd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtds(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat32(rs1)
-	if math.IsNaN(float64(d)) {
-		c.SetRegisterFloat(rd, NaN64)
-	} else {
-		c.SetRegisterFloatAsFloat64(rd, float64(d))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+	r, flags := SoftConvert(FloatFormatS, FloatFormatD, a, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2109,28 +2511,14 @@ func (_ *isaD) fclassd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtwd(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.w.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat64(rs1)
-	if math.IsNaN(d) {
-		c.SetRegister(rd, 0x7fffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float64(math.MaxInt32) {
-		c.SetRegister(rd, SignExtend(0x7fffffff, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d < float64(math.MinInt32) {
-		c.SetRegister(rd, SignExtend(0x80000000, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, SignExtend(uint64(int32(d)), 31))
-	if math.Ceil(d) != d {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftToInt(FloatFormatD, a, true, 32, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2138,28 +2526,14 @@ func (_ *isaD) fcvtwd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtwud(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.wu.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat64(rs1)
-	if math.IsNaN(d) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float64(math.MaxUint32) {
-		c.SetRegister(rd, SignExtend(0xffffffff, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d <= float64(-1) {
-		c.SetRegister(rd, SignExtend(0x00000000, 31))
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, SignExtend(uint64(uint32(d)), 31))
-	if math.Ceil(d) != d {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftToInt(FloatFormatD, a, false, 32, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2167,7 +2541,13 @@ func (_ *isaD) fcvtwud(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtdw(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.w", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat64(rd, float64(int32(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatD, c.GetRegister(rs1), true, 32, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2175,7 +2555,13 @@ func (_ *isaD) fcvtdw(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtdwu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.wu", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat64(rd, float64(uint32(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatD, c.GetRegister(rs1), false, 32, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2183,28 +2569,14 @@ func (_ *isaD) fcvtdwu(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtld(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.l.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat64(rs1)
-	if math.IsNaN(d) {
-		c.SetRegister(rd, 0x7fffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float64(math.MaxInt64) {
-		c.SetRegister(rd, 0x7fffffffffffffff)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d < float64(math.MinInt64) {
-		c.SetRegister(rd, 0x8000000000000000)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, uint64(int64(d)))
-	if math.Ceil(d) != d {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftToInt(FloatFormatD, a, true, 64, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2212,28 +2584,14 @@ func (_ *isaD) fcvtld(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtlud(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.lu.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	d := c.GetRegisterFloatAsFloat64(rs1)
-	if math.IsNaN(d) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d > float64(math.MaxUint64) {
-		c.SetRegister(rd, 0xffffffffffffffff)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	if d <= float64(-1) {
-		c.SetRegister(rd, 0x0000000000000000)
-		c.SetFloatFlag(FFlagsNV, 1)
-		c.SetPC(c.GetPC() + 4)
-		return 1, nil
-	}
-	c.SetRegister(rd, uint64(d))
-	if math.Ceil(d) != d {
-		c.SetFloatFlag(FFlagsNX, 1)
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
 	}
+	a := math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+	r, flags := SoftToInt(FloatFormatD, a, false, 64, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2249,7 +2607,13 @@ func (_ *isaD) fmvxd(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtdl(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.l", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat64(rd, float64(int64(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatD, c.GetRegister(rs1), true, 64, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2257,7 +2621,13 @@ func (_ *isaD) fcvtdl(c *CPU, i uint64) (uint64, error) {
 func (_ *isaD) fcvtdlu(c *CPU, i uint64) (uint64, error) {
 	rd, rs1, rs2 := RType(i)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.lu", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
-	c.SetRegisterFloatAsFloat64(rd, float64(uint64(c.GetRegister(rs1))))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatD, c.GetRegister(rs1), false, 64, rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
 	c.SetPC(c.GetPC() + 4)
 	return 1, nil
 }
@@ -2338,6 +2708,23 @@ func (_ *isaC) ld(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+func (_ *isaC) flw(c *CPU, i uint64) (uint64, error) {
+	var (
+		rd  = InstructionPart(i, 2, 4) + 8
+		rs1 = InstructionPart(i, 7, 9) + 8
+		imm = InstructionPart(i, 5, 5)<<6 | InstructionPart(i, 10, 12)<<3 | InstructionPart(i, 6, 6)<<2
+	)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "c.flw", c.LogF(rd), c.LogI(rs1), imm))
+	a := c.GetRegister(rs1) + imm
+	v, err := c.GetMemory().GetUint32(a)
+	if err != nil {
+		return 0, err
+	}
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(v))
+	c.SetPC(c.GetPC() + 2)
+	return 1, nil
+}
+
 func (_ *isaC) fsd(c *CPU, i uint64) (uint64, error) {
 	var (
 		rs1 = InstructionPart(i, 7, 9) + 8
@@ -2383,6 +2770,21 @@ func (_ *isaC) sd(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+func (_ *isaC) fsw(c *CPU, i uint64) (uint64, error) {
+	var (
+		rs1 = InstructionPart(i, 7, 9) + 8
+		rs2 = InstructionPart(i, 2, 4) + 8
+		imm = InstructionPart(i, 5, 5)<<6 | InstructionPart(i, 10, 12)<<3 | InstructionPart(i, 6, 6)<<2
+	)
+	Debugln(fmt.Sprintf("%#08x % 10s rs1: %s rs2: %s imm: ----(%#016x)", c.GetPC(), "c.fsw", c.LogI(rs1), c.LogF(rs2), imm))
+	a := c.GetRegister(rs1) + imm
+	if err := c.GetMemory().SetUint32(a, uint32(c.GetRegisterFloat(rs2))); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 2)
+	return 1, nil
+}
+
 func (_ *isaC) nop(c *CPU, _ uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s", c.GetPC(), "c.nop"))
 	c.SetPC(c.GetPC() + 2)
@@ -2420,6 +2822,25 @@ func (_ *isaC) addiw(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+func (_ *isaC) jal(c *CPU, i uint64) (uint64, error) {
+	var imm = SignExtend(InstructionPart(i, 12, 12)<<11|
+		InstructionPart(i, 8, 8)<<10|
+		InstructionPart(i, 9, 10)<<8|
+		InstructionPart(i, 6, 6)<<7|
+		InstructionPart(i, 7, 7)<<6|
+		InstructionPart(i, 2, 2)<<5|
+		InstructionPart(i, 11, 11)<<4|
+		InstructionPart(i, 3, 5)<<1, 11)
+	Debugln(fmt.Sprintf("%#08x % 10s imm: ----(%#016x)", c.GetPC(), "c.jal", imm))
+	r := c.GetPC() + imm
+	if r%2 != 0x00 {
+		return 0, ErrMisalignedInstructionFetch
+	}
+	c.SetRegister(Rra, c.GetPC()+2)
+	c.SetPC(r)
+	return 1, nil
+}
+
 func (_ *isaC) li(c *CPU, i uint64) (uint64, error) {
 	var (
 		rd  = InstructionPart(i, 7, 11)
@@ -2470,7 +2891,7 @@ func (_ *isaC) srli(c *CPU, i uint64) (uint64, error) {
 		shamt = InstructionPart(i, 12, 12)<<5 | InstructionPart(i, 2, 6)
 	)
 	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x)", c.GetPC(), "c.srli", c.LogI(rd), shamt))
-	c.SetRegister(rd, c.GetRegister(rd)>>shamt)
+	c.SetRegister(rd, c.GetRegisterUnsigned(rd)>>shamt)
 	c.SetPC(c.GetPC() + 2)
 	return 1, nil
 }
@@ -2683,6 +3104,21 @@ func (_ *isaC) ldsp(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+func (_ *isaC) flwsp(c *CPU, i uint64) (uint64, error) {
+	var (
+		rd  = InstructionPart(i, 7, 11)
+		imm = InstructionPart(i, 2, 3)<<6 | InstructionPart(i, 12, 12)<<5 | InstructionPart(i, 4, 6)<<2
+	)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x)", c.GetPC(), "c.flwsp", c.LogF(rd), imm))
+	v, err := c.GetMemory().GetUint32(c.GetRegister(Rsp) + imm)
+	if err != nil {
+		return 0, err
+	}
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(v))
+	c.SetPC(c.GetPC() + 2)
+	return 1, nil
+}
+
 func (_ *isaC) jr(c *CPU, i uint64) (uint64, error) {
 	var rs1 = InstructionPart(i, 7, 11)
 	Debugln(fmt.Sprintf("%#08x % 10s rs1: %s", c.GetPC(), "c.jr", c.LogI(rs1)))
@@ -2785,6 +3221,20 @@ func (_ *isaC) sdsp(c *CPU, i uint64) (uint64, error) {
 	return 1, nil
 }
 
+func (_ *isaC) fswsp(c *CPU, i uint64) (uint64, error) {
+	var (
+		rs2 = InstructionPart(i, 2, 6)
+		imm = InstructionPart(i, 7, 8)<<6 | InstructionPart(i, 9, 12)<<2
+	)
+	Debugln(fmt.Sprintf("%#08x % 10s rs2: %s imm: ----(%#016x)", c.GetPC(), "c.fswsp", c.LogF(rs2), imm))
+	a := c.GetRegister(Rsp) + imm
+	if err := c.GetMemory().SetUint32(a, uint32(c.GetRegisterFloat(rs2))); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 2)
+	return 1, nil
+}
+
 type isaPrivileged struct{}
 
 func (_ *isaPrivileged) uret(c *CPU, _ uint64) (uint64, error) {
@@ -2807,7 +3257,13 @@ func (_ *isaPrivileged) hret(c *CPU, _ uint64) (uint64, error) {
 
 func (_ *isaPrivileged) mret(c *CPU, _ uint64) (uint64, error) {
 	Debugln(fmt.Sprintf("%#08x % 10s", c.GetPC(), "mret"))
-	c.SetPC(c.GetPC() + 4)
+	mstatus := c.GetCSR().Get(CSRmstatus)
+	mie := uint64(0)
+	if mstatus&MstatusMPIE != 0 {
+		mie = MstatusMIE
+	}
+	c.GetCSR().Set(CSRmstatus, mstatus&^(MstatusMIE|MstatusMPP)|mie|MstatusMPIE)
+	c.SetPC(c.GetCSR().Get(CSRmepc))
 	return 1, nil
 }
 
@@ -2910,13 +3366,31 @@ func FClassD(f float64) uint64 {
 }
 
 var (
-	aluI          = &isaI{}
-	aluZifencei   = &isaZifencei{}
-	aluZicsr      = &isaZicsr{}
-	aluM          = &isaM{}
-	aluA          = &isaA{}
-	aluF          = &isaF{}
-	aluD          = &isaD{}
-	aluC          = &isaC{}
-	aluPrivileged = &isaPrivileged{}
+	aluI           = &isaI{}
+	aluZifencei    = &isaZifencei{}
+	aluZicsr       = &isaZicsr{}
+	aluM           = &isaM{}
+	aluA           = &isaA{}
+	aluF           = &isaF{}
+	aluD           = &isaD{}
+	aluC           = &isaC{}
+	aluPrivileged  = &isaPrivileged{}
+	aluZba         = &isaZba{}
+	aluZbb         = &isaZbb{}
+	aluZbs         = &isaZbs{}
+	aluV           = &isaV{}
+	aluZfh         = &isaZfh{}
+	aluQ           = &isaQ{}
+	aluZbkb        = &isaZbkb{}
+	aluZbkc        = &isaZbkc{}
+	aluZbkx        = &isaZbkx{}
+	aluZknd        = &isaZknd{}
+	aluZkne        = &isaZkne{}
+	aluZknh        = &isaZknh{}
+	aluZicond      = &isaZicond{}
+	aluZicbom      = &isaZicbom{}
+	aluZicboz      = &isaZicboz{}
+	aluZihintpause = &isaZihintpause{}
+	aluZacas       = &isaZacas{}
+	aluZabha       = &isaZabha{}
 )
@@ -0,0 +1,562 @@
+package rv64
+
+// https://github.com/riscv/riscv-crypto/releases/download/v1.0.1-scalar/riscv-crypto-spec-scalar-v1.0.1.pdf
+//
+// Zbkb: Bit-manipulation for cryptography.
+// Zbkc: Carry-less multiplication.
+// Zbkx: Crossbar permutations.
+// Zknd: AES decryption.
+// Zkne: AES encryption.
+// Zknh: SHA-256 and SHA-512 hash functions.
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+type isaZbkb struct{}
+
+func (_ *isaZbkb) pack(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "pack", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	n := c.GetXLEN() / 2
+	c.SetRegister(rd, b<<n|a&(1<<n-1))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkb) packh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "packh", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, b&0xff<<8|a&0xff)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkb) packw(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "packw", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, SignExtend(b&0xffff<<16|a&0xffff, 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkb) brev8(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "brev8", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, bits.ReverseBytes64(bits.Reverse64(a)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkb) zip(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "zip", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	var r uint64
+	for j := 0; j < 16; j++ {
+		r |= (a>>j&1)<<(2*j) | (a>>(j+16)&1)<<(2*j+1)
+	}
+	c.SetRegister(rd, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkb) unzip(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "unzip", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	var r uint64
+	for j := 0; j < 16; j++ {
+		r |= (a>>(2*j)&1)<<j | (a>>(2*j+1)&1)<<(j+16)
+	}
+	c.SetRegister(rd, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZbkc struct{}
+
+func (_ *isaZbkc) clmul(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "clmul", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	_, lo := clmul(a, b, c.GetXLEN())
+	c.SetRegister(rd, lo)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkc) clmulh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "clmulh", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	hi, _ := clmul(a, b, c.GetXLEN())
+	c.SetRegister(rd, hi)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZbkx struct{}
+
+func (_ *isaZbkx) xperm4(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "xperm4", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, xperm(a, b, 4, c.GetXLEN()))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZbkx) xperm8(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "xperm8", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, xperm(a, b, 8, c.GetXLEN()))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZknd struct{}
+
+func (_ *isaZknd) aes64ds(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes64ds", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aesSubBytes(aesShiftRows(a, b, true), true))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknd) aes64dsm(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes64dsm", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aesMixColumns(aesSubBytes(aesShiftRows(a, b, true), true), true))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknd) aes64im(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "aes64im", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, aesMixColumns(a, true))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknd) aes32dsi(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes32dsi", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aes32(a, b, InstructionPart(i, 30, 31), true, false))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknd) aes32dsmi(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes32dsmi", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aes32(a, b, InstructionPart(i, 30, 31), true, true))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZkne struct{}
+
+func (_ *isaZkne) aes64es(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes64es", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aesSubBytes(aesShiftRows(a, b, false), false))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZkne) aes64esm(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes64esm", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aesMixColumns(aesSubBytes(aesShiftRows(a, b, false), false), false))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZkne) aes64ks1i(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	rnum := InstructionPart(imm, 0, 3)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "aes64ks1i", c.LogI(rd), c.LogI(rs1), rnum))
+	if rnum > 0xa {
+		return 0, ErrAbnormalInstruction
+	}
+	w := uint32(c.GetRegister(rs1) >> 32)
+	if rnum != 0xa {
+		w = bits.RotateLeft32(w, -8)
+	}
+	w = uint32(aesSubBytes(uint64(w), false)) ^ uint32(aesRcon[rnum])
+	c.SetRegister(rd, uint64(w)<<32|uint64(w))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZkne) aes64ks2(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes64ks2", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	w0 := a>>32 ^ b&0xffffffff
+	w1 := w0 ^ b>>32
+	c.SetRegister(rd, w1<<32|w0)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZkne) aes32esi(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes32esi", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aes32(a, b, InstructionPart(i, 30, 31), false, false))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZkne) aes32esmi(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "aes32esmi", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, aes32(a, b, InstructionPart(i, 30, 31), false, true))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZknh struct{}
+
+func (_ *isaZknh) sha256sig0(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha256sig0", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	x := uint32(a)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(x, -7)^bits.RotateLeft32(x, -18)^x>>3), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha256sig1(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha256sig1", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	x := uint32(a)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(x, -17)^bits.RotateLeft32(x, -19)^x>>10), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha256sum0(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha256sum0", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	x := uint32(a)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(x, -2)^bits.RotateLeft32(x, -13)^bits.RotateLeft32(x, -22)), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha256sum1(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha256sum1", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	x := uint32(a)
+	c.SetRegister(rd, SignExtend(uint64(bits.RotateLeft32(x, -6)^bits.RotateLeft32(x, -11)^bits.RotateLeft32(x, -25)), 31))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig0(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha512sig0", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, sha512sig0(a))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig1(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha512sig1", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, sha512sig1(a))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sum0(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha512sum0", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, sha512sum0(a))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sum1(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s", c.GetPC(), "sha512sum1", c.LogI(rd), c.LogI(rs1)))
+	a := c.GetRegister(rs1)
+	c.SetRegister(rd, sha512sum1(a))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig0l(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sig0l", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sig0(b<<32|a&0xffffffff))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig0h(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sig0h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sig0(a<<32|b&0xffffffff)>>32)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig1l(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sig1l", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sig1(b<<32|a&0xffffffff))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sig1h(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sig1h", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sig1(a<<32|b&0xffffffff)>>32)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sum0r(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sum0r", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sum0(b<<32|a&0xffffffff))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZknh) sha512sum1r(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "sha512sum1r", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	a := c.GetRegister(rs1)
+	b := c.GetRegister(rs2)
+	c.SetRegister(rd, sha512sum1(b<<32|a&0xffffffff))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func sha512sig0(x uint64) uint64 {
+	return bits.RotateLeft64(x, -1) ^ bits.RotateLeft64(x, -8) ^ x>>7
+}
+
+func sha512sig1(x uint64) uint64 {
+	return bits.RotateLeft64(x, -19) ^ bits.RotateLeft64(x, -61) ^ x>>6
+}
+
+func sha512sum0(x uint64) uint64 {
+	return bits.RotateLeft64(x, -28) ^ bits.RotateLeft64(x, -34) ^ bits.RotateLeft64(x, -39)
+}
+
+func sha512sum1(x uint64) uint64 {
+	return bits.RotateLeft64(x, -14) ^ bits.RotateLeft64(x, -18) ^ bits.RotateLeft64(x, -41)
+}
+
+// clmul returns the high and low halves of the 2*n-bit carry-less product of the n-bit values a and b.
+func clmul(a uint64, b uint64, n uint64) (uint64, uint64) {
+	a &= 1<<n - 1
+	b &= 1<<n - 1
+	var hi, lo uint64
+	for j := uint64(0); j < n; j++ {
+		if b>>j&1 != 0 {
+			lo ^= a << j
+			if j != 0 {
+				hi ^= a >> (64 - j)
+			}
+		}
+	}
+	if n == 32 {
+		return lo >> 32, lo & 0xffffffff
+	}
+	return hi, lo
+}
+
+// xperm looks up each w-bit element of b as an index into the w-bit elements of a. Out of range indices give 0.
+func xperm(a uint64, b uint64, w uint64, n uint64) uint64 {
+	var r uint64
+	m := uint64(1)<<w - 1
+	for j := uint64(0); j < n; j += w {
+		k := b >> j & m
+		if k < n/w {
+			r |= (a >> (k * w) & m) << j
+		}
+	}
+	return r
+}
+
+var (
+	aesSbox = [256]byte{
+		0x63, 0x7c, 0x77, 0x7b, 0xf2, 0x6b, 0x6f, 0xc5, 0x30, 0x01, 0x67, 0x2b, 0xfe, 0xd7, 0xab, 0x76,
+		0xca, 0x82, 0xc9, 0x7d, 0xfa, 0x59, 0x47, 0xf0, 0xad, 0xd4, 0xa2, 0xaf, 0x9c, 0xa4, 0x72, 0xc0,
+		0xb7, 0xfd, 0x93, 0x26, 0x36, 0x3f, 0xf7, 0xcc, 0x34, 0xa5, 0xe5, 0xf1, 0x71, 0xd8, 0x31, 0x15,
+		0x04, 0xc7, 0x23, 0xc3, 0x18, 0x96, 0x05, 0x9a, 0x07, 0x12, 0x80, 0xe2, 0xeb, 0x27, 0xb2, 0x75,
+		0x09, 0x83, 0x2c, 0x1a, 0x1b, 0x6e, 0x5a, 0xa0, 0x52, 0x3b, 0xd6, 0xb3, 0x29, 0xe3, 0x2f, 0x84,
+		0x53, 0xd1, 0x00, 0xed, 0x20, 0xfc, 0xb1, 0x5b, 0x6a, 0xcb, 0xbe, 0x39, 0x4a, 0x4c, 0x58, 0xcf,
+		0xd0, 0xef, 0xaa, 0xfb, 0x43, 0x4d, 0x33, 0x85, 0x45, 0xf9, 0x02, 0x7f, 0x50, 0x3c, 0x9f, 0xa8,
+		0x51, 0xa3, 0x40, 0x8f, 0x92, 0x9d, 0x38, 0xf5, 0xbc, 0xb6, 0xda, 0x21, 0x10, 0xff, 0xf3, 0xd2,
+		0xcd, 0x0c, 0x13, 0xec, 0x5f, 0x97, 0x44, 0x17, 0xc4, 0xa7, 0x7e, 0x3d, 0x64, 0x5d, 0x19, 0x73,
+		0x60, 0x81, 0x4f, 0xdc, 0x22, 0x2a, 0x90, 0x88, 0x46, 0xee, 0xb8, 0x14, 0xde, 0x5e, 0x0b, 0xdb,
+		0xe0, 0x32, 0x3a, 0x0a, 0x49, 0x06, 0x24, 0x5c, 0xc2, 0xd3, 0xac, 0x62, 0x91, 0x95, 0xe4, 0x79,
+		0xe7, 0xc8, 0x37, 0x6d, 0x8d, 0xd5, 0x4e, 0xa9, 0x6c, 0x56, 0xf4, 0xea, 0x65, 0x7a, 0xae, 0x08,
+		0xba, 0x78, 0x25, 0x2e, 0x1c, 0xa6, 0xb4, 0xc6, 0xe8, 0xdd, 0x74, 0x1f, 0x4b, 0xbd, 0x8b, 0x8a,
+		0x70, 0x3e, 0xb5, 0x66, 0x48, 0x03, 0xf6, 0x0e, 0x61, 0x35, 0x57, 0xb9, 0x86, 0xc1, 0x1d, 0x9e,
+		0xe1, 0xf8, 0x98, 0x11, 0x69, 0xd9, 0x8e, 0x94, 0x9b, 0x1e, 0x87, 0xe9, 0xce, 0x55, 0x28, 0xdf,
+		0x8c, 0xa1, 0x89, 0x0d, 0xbf, 0xe6, 0x42, 0x68, 0x41, 0x99, 0x2d, 0x0f, 0xb0, 0x54, 0xbb, 0x16,
+	}
+	aesSboxInv = [256]byte{
+		0x52, 0x09, 0x6a, 0xd5, 0x30, 0x36, 0xa5, 0x38, 0xbf, 0x40, 0xa3, 0x9e, 0x81, 0xf3, 0xd7, 0xfb,
+		0x7c, 0xe3, 0x39, 0x82, 0x9b, 0x2f, 0xff, 0x87, 0x34, 0x8e, 0x43, 0x44, 0xc4, 0xde, 0xe9, 0xcb,
+		0x54, 0x7b, 0x94, 0x32, 0xa6, 0xc2, 0x23, 0x3d, 0xee, 0x4c, 0x95, 0x0b, 0x42, 0xfa, 0xc3, 0x4e,
+		0x08, 0x2e, 0xa1, 0x66, 0x28, 0xd9, 0x24, 0xb2, 0x76, 0x5b, 0xa2, 0x49, 0x6d, 0x8b, 0xd1, 0x25,
+		0x72, 0xf8, 0xf6, 0x64, 0x86, 0x68, 0x98, 0x16, 0xd4, 0xa4, 0x5c, 0xcc, 0x5d, 0x65, 0xb6, 0x92,
+		0x6c, 0x70, 0x48, 0x50, 0xfd, 0xed, 0xb9, 0xda, 0x5e, 0x15, 0x46, 0x57, 0xa7, 0x8d, 0x9d, 0x84,
+		0x90, 0xd8, 0xab, 0x00, 0x8c, 0xbc, 0xd3, 0x0a, 0xf7, 0xe4, 0x58, 0x05, 0xb8, 0xb3, 0x45, 0x06,
+		0xd0, 0x2c, 0x1e, 0x8f, 0xca, 0x3f, 0x0f, 0x02, 0xc1, 0xaf, 0xbd, 0x03, 0x01, 0x13, 0x8a, 0x6b,
+		0x3a, 0x91, 0x11, 0x41, 0x4f, 0x67, 0xdc, 0xea, 0x97, 0xf2, 0xcf, 0xce, 0xf0, 0xb4, 0xe6, 0x73,
+		0x96, 0xac, 0x74, 0x22, 0xe7, 0xad, 0x35, 0x85, 0xe2, 0xf9, 0x37, 0xe8, 0x1c, 0x75, 0xdf, 0x6e,
+		0x47, 0xf1, 0x1a, 0x71, 0x1d, 0x29, 0xc5, 0x89, 0x6f, 0xb7, 0x62, 0x0e, 0xaa, 0x18, 0xbe, 0x1b,
+		0xfc, 0x56, 0x3e, 0x4b, 0xc6, 0xd2, 0x79, 0x20, 0x9a, 0xdb, 0xc0, 0xfe, 0x78, 0xcd, 0x5a, 0xf4,
+		0x1f, 0xdd, 0xa8, 0x33, 0x88, 0x07, 0xc7, 0x31, 0xb1, 0x12, 0x10, 0x59, 0x27, 0x80, 0xec, 0x5f,
+		0x60, 0x51, 0x7f, 0xa9, 0x19, 0xb5, 0x4a, 0x0d, 0x2d, 0xe5, 0x7a, 0x9f, 0x93, 0xc9, 0x9c, 0xef,
+		0xa0, 0xe0, 0x3b, 0x4d, 0xae, 0x2a, 0xf5, 0xb0, 0xc8, 0xeb, 0xbb, 0x3c, 0x83, 0x53, 0x99, 0x61,
+		0x17, 0x2b, 0x04, 0x7e, 0xba, 0x77, 0xd6, 0x26, 0xe1, 0x69, 0x14, 0x63, 0x55, 0x21, 0x0c, 0x7d,
+	}
+	aesRcon = [11]byte{0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x80, 0x1b, 0x36, 0x00}
+)
+
+// aesMul multiplies in GF(2^8) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+func aesMul(a byte, b byte) byte {
+	var r byte
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			r ^= a
+		}
+		if a&0x80 != 0 {
+			a = a<<1 ^ 0x1b
+		} else {
+			a <<= 1
+		}
+	}
+	return r
+}
+
+// aesSubBytes applies the forward or inverse S-box to each byte of x.
+func aesSubBytes(x uint64, inv bool) uint64 {
+	s := &aesSbox
+	if inv {
+		s = &aesSboxInv
+	}
+	var r uint64
+	for j := 0; j < 64; j += 8 {
+		r |= uint64(s[byte(x>>j)]) << j
+	}
+	return r
+}
+
+// aesShiftRows returns the first two columns of the state after ShiftRows, or its inverse. The AES state is stored in
+// column-major order, lo holds columns 0 and 1 and hi holds columns 2 and 3. Swapping lo and hi gives the other two.
+func aesShiftRows(lo uint64, hi uint64, inv bool) uint64 {
+	var r uint64
+	for k := 0; k < 8; k++ {
+		col, row := k/4, k%4
+		src := col + row
+		if inv {
+			src = col - row + 4
+		}
+		j := 4*(src%4) + row
+		b := lo
+		if j >= 8 {
+			b = hi
+		}
+		r |= (b >> (8 * (j % 8)) & 0xff) << (8 * k)
+	}
+	return r
+}
+
+// aesMixColumn applies MixColumns, or its inverse, to the column x.
+func aesMixColumn(x uint32, inv bool) uint32 {
+	m := [4]byte{0x02, 0x03, 0x01, 0x01}
+	if inv {
+		m = [4]byte{0x0e, 0x0b, 0x0d, 0x09}
+	}
+	var r uint32
+	for row := 0; row < 4; row++ {
+		var b byte
+		for k := 0; k < 4; k++ {
+			b ^= aesMul(byte(x>>(8*k)), m[(k-row+4)%4])
+		}
+		r |= uint32(b) << (8 * row)
+	}
+	return r
+}
+
+// aesMixColumns applies MixColumns, or its inverse, to the two columns of x.
+func aesMixColumns(x uint64, inv bool) uint64 {
+	return uint64(aesMixColumn(uint32(x>>32), inv))<<32 | uint64(aesMixColumn(uint32(x), inv))
+}
+
+// aes32 implements the RV32 AES instructions. Byte bs of b is substituted, optionally multiplied by its column of the
+// MixColumns matrix, rotated back into position and added to a.
+func aes32(a uint64, b uint64, bs uint64, inv bool, mix bool) uint64 {
+	s := &aesSbox
+	if inv {
+		s = &aesSboxInv
+	}
+	x := uint32(s[byte(b>>(8*bs))])
+	if mix {
+		x = aesMixColumn(x, inv)
+	}
+	return SignExtend(uint64(uint32(a)^bits.RotateLeft32(x, int(8*bs))), 31)
+}
@@ -0,0 +1,273 @@
+package rv64
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+// run executes i with a1 = a and a2 = b and returns a0.
+func run(t *testing.T, c *CPU, i uint64, a uint64, b uint64) uint64 {
+	c.SetRegister(Ra1, a)
+	c.SetRegister(Ra2, b)
+	if err := execute(c, i); err != nil {
+		t.Fatalf("%#08x: %v", i, err)
+	}
+	return c.GetRegister(Ra0)
+}
+
+func TestCryptoAES64(t *testing.T) {
+	const op = 0b0110011
+	var (
+		ks1i = func(rnum uint64) uint64 { return encodeI(0b0010011, 0b001, 0x310|rnum, Ra0, Ra1) }
+		ks2  = encodeR(op, 0b000, 0b0111111, Ra0, Ra1, Ra2)
+		es   = encodeR(op, 0b000, 0b0011001, Ra0, Ra1, Ra2)
+		esm  = encodeR(op, 0b000, 0b0011011, Ra0, Ra1, Ra2)
+		ds   = encodeR(op, 0b000, 0b0011101, Ra0, Ra1, Ra2)
+		dsm  = encodeR(op, 0b000, 0b0011111, Ra0, Ra1, Ra2)
+		im   = encodeI(0b0010011, 0b001, 0x300, Ra0, Ra1)
+	)
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	src := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	dst := make([]byte, 16)
+	b, _ := aes.NewCipher(key)
+	b.Encrypt(dst, src)
+	c := newVectorCPU()
+	// Key expansion.
+	rk := [22]uint64{binary.LittleEndian.Uint64(key), binary.LittleEndian.Uint64(key[8:])}
+	for r := uint64(0); r < 10; r++ {
+		k := run(t, c, ks1i(r), rk[2*r+1], 0)
+		rk[2*r+2] = run(t, c, ks2, k, rk[2*r])
+		rk[2*r+3] = run(t, c, ks2, rk[2*r+2], rk[2*r+1])
+	}
+	// Encryption.
+	s0 := binary.LittleEndian.Uint64(src) ^ rk[0]
+	s1 := binary.LittleEndian.Uint64(src[8:]) ^ rk[1]
+	for r := 1; r < 10; r++ {
+		s0, s1 = run(t, c, esm, s0, s1)^rk[2*r], run(t, c, esm, s1, s0)^rk[2*r+1]
+	}
+	s0, s1 = run(t, c, es, s0, s1)^rk[20], run(t, c, es, s1, s0)^rk[21]
+	out := make([]byte, 16)
+	binary.LittleEndian.PutUint64(out, s0)
+	binary.LittleEndian.PutUint64(out[8:], s1)
+	if !bytes.Equal(out, dst) {
+		t.Fatalf("%x != %x", out, dst)
+	}
+	// Decryption with the equivalent inverse cipher.
+	s0, s1 = s0^rk[20], s1^rk[21]
+	for r := 9; r > 0; r-- {
+		s0, s1 = run(t, c, dsm, s0, s1)^run(t, c, im, rk[2*r], 0), run(t, c, dsm, s1, s0)^run(t, c, im, rk[2*r+1], 0)
+	}
+	s0, s1 = run(t, c, ds, s0, s1)^rk[0], run(t, c, ds, s1, s0)^rk[1]
+	if s0 != binary.LittleEndian.Uint64(src) || s1 != binary.LittleEndian.Uint64(src[8:]) {
+		t.FailNow()
+	}
+	// Round numbers above 0xa are reserved.
+	if err := execute(c, ks1i(0xb)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestCryptoAES32(t *testing.T) {
+	const op = 0b0110011
+	var (
+		esi  = func(bs uint64) uint64 { return encodeR(op, 0b000, bs<<5|0b10001, Ra0, Ra1, Ra2) }
+		esmi = func(bs uint64) uint64 { return encodeR(op, 0b000, bs<<5|0b10011, Ra0, Ra1, Ra2) }
+		dsi  = func(bs uint64) uint64 { return encodeR(op, 0b000, bs<<5|0b10101, Ra0, Ra1, Ra2) }
+		dsmi = func(bs uint64) uint64 { return encodeR(op, 0b000, bs<<5|0b10111, Ra0, Ra1, Ra2) }
+	)
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	src := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	dst := make([]byte, 16)
+	b, _ := aes.NewCipher(key)
+	b.Encrypt(dst, src)
+	c := newRV32CPU()
+	// Key expansion, SubWord is aes32esi applied to each byte.
+	var rk [44]uint32
+	for j := 0; j < 4; j++ {
+		rk[j] = binary.LittleEndian.Uint32(key[4*j:])
+	}
+	for j := 4; j < 44; j++ {
+		w := rk[j-1]
+		if j%4 == 0 {
+			w = bits.RotateLeft32(w, -8)
+			var s uint64
+			for bs := uint64(0); bs < 4; bs++ {
+				s = run(t, c, esi(bs), s, uint64(w))
+			}
+			w = uint32(s) ^ uint32(aesRcon[j/4-1])
+		}
+		rk[j] = rk[j-4] ^ w
+	}
+	round := func(s [4]uint32, r int, f func(uint64) uint64, inv bool) [4]uint32 {
+		var n [4]uint32
+		for j := 0; j < 4; j++ {
+			x := uint64(rk[4*r+j])
+			for bs := 0; bs < 4; bs++ {
+				k := (j + bs) % 4
+				if inv {
+					k = (j - bs + 4) % 4
+				}
+				x = run(t, c, f(uint64(bs)), x, uint64(s[k]))
+			}
+			n[j] = uint32(x)
+		}
+		return n
+	}
+	var s [4]uint32
+	for j := 0; j < 4; j++ {
+		s[j] = binary.LittleEndian.Uint32(src[4*j:]) ^ rk[j]
+	}
+	for r := 1; r < 10; r++ {
+		s = round(s, r, esmi, false)
+	}
+	s = round(s, 10, esi, false)
+	out := make([]byte, 16)
+	for j := 0; j < 4; j++ {
+		binary.LittleEndian.PutUint32(out[4*j:], s[j])
+	}
+	if !bytes.Equal(out, dst) {
+		t.Fatalf("%x != %x", out, dst)
+	}
+	// Decryption, the inverse round keys go through InvMixColumns.
+	for j := 4; j < 40; j++ {
+		rk[j] = aesMixColumn(rk[j], true)
+	}
+	for j := 0; j < 4; j++ {
+		s[j] ^= rk[40+j]
+	}
+	for r := 9; r > 0; r-- {
+		s = round(s, r, dsmi, true)
+	}
+	s = round(s, 0, dsi, true)
+	for j := 0; j < 4; j++ {
+		if s[j] != binary.LittleEndian.Uint32(src[4*j:]) {
+			t.FailNow()
+		}
+	}
+}
+
+func TestCryptoSHA(t *testing.T) {
+	const op = 0b0110011
+	r := rand.New(rand.NewSource(42))
+	unary := func(imm uint64) uint64 { return encodeI(0b0010011, 0b001, imm, Ra0, Ra1) }
+	ror32 := func(x uint32, n int) uint32 { return bits.RotateLeft32(x, -n) }
+	ror64 := func(x uint64, n int) uint64 { return bits.RotateLeft64(x, -n) }
+	sha256 := []func(x uint32) uint32{
+		func(x uint32) uint32 { return ror32(x, 2) ^ ror32(x, 13) ^ ror32(x, 22) },
+		func(x uint32) uint32 { return ror32(x, 6) ^ ror32(x, 11) ^ ror32(x, 25) },
+		func(x uint32) uint32 { return ror32(x, 7) ^ ror32(x, 18) ^ x>>3 },
+		func(x uint32) uint32 { return ror32(x, 17) ^ ror32(x, 19) ^ x>>10 },
+	}
+	sha512 := []func(x uint64) uint64{
+		func(x uint64) uint64 { return ror64(x, 28) ^ ror64(x, 34) ^ ror64(x, 39) },
+		func(x uint64) uint64 { return ror64(x, 14) ^ ror64(x, 18) ^ ror64(x, 41) },
+		func(x uint64) uint64 { return ror64(x, 1) ^ ror64(x, 8) ^ x>>7 },
+		func(x uint64) uint64 { return ror64(x, 19) ^ ror64(x, 61) ^ x>>6 },
+	}
+	c64 := newVectorCPU()
+	c32 := newRV32CPU()
+	for n := 0; n < 100; n++ {
+		x := r.Uint64()
+		for j, f := range sha256 {
+			e := SignExtend(uint64(f(uint32(x))), 31)
+			if v := run(t, c64, unary(0x100|uint64(j)), x, 0); v != e {
+				t.Fatalf("sha256 %d: %#x != %#x", j, v, e)
+			}
+			if v := run(t, c32, unary(0x100|uint64(j)), x, 0); v != e {
+				t.Fatalf("sha256 %d: %#x != %#x", j, v, e)
+			}
+		}
+		for j, f := range sha512 {
+			if v := run(t, c64, unary(0x104|uint64(j)), x, 0); v != f(x) {
+				t.Fatalf("sha512 %d: %#x != %#x", j, v, f(x))
+			}
+		}
+		// On RV32 the 64-bit value is split across two registers, a1 holds the low half.
+		lo, hi := x&0xffffffff, x>>32
+		for _, e := range []struct {
+			name  string
+			funct uint64
+			a     uint64
+			b     uint64
+			r     uint64
+		}{
+			{"sha512sum0r", 0b0101000, lo, hi, sha512[0](x)},
+			{"sha512sum1r", 0b0101001, lo, hi, sha512[1](x)},
+			{"sha512sig0l", 0b0101010, lo, hi, sha512[2](x)},
+			{"sha512sig0h", 0b0101110, hi, lo, sha512[2](x) >> 32},
+			{"sha512sig1l", 0b0101011, lo, hi, sha512[3](x)},
+			{"sha512sig1h", 0b0101111, hi, lo, sha512[3](x) >> 32},
+		} {
+			if v := run(t, c32, encodeR(op, 0b000, e.funct, Ra0, Ra1, Ra2), e.a, e.b); v != SignExtend(e.r&0xffffffff, 31) {
+				t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+			}
+		}
+	}
+	// The 64-bit forms are reserved on RV32.
+	if err := execute(c32, unary(0x104)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestCryptoBitManipulation(t *testing.T) {
+	const op = 0b0110011
+	c64 := newVectorCPU()
+	c32 := newRV32CPU()
+	for _, e := range []struct {
+		name string
+		c    *CPU
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		{"pack", c64, encodeR(op, 0b100, 0b0000100, Ra0, Ra1, Ra2), 0x0123456789abcdef, 0xfedcba9876543210, 0x7654321089abcdef},
+		{"pack", c32, encodeR(op, 0b100, 0b0000100, Ra0, Ra1, Ra2), 0x89abcdef, 0x76543210, 0x3210cdef},
+		{"packh", c64, encodeR(op, 0b111, 0b0000100, Ra0, Ra1, Ra2), 0x0123, 0x4567, 0x6723},
+		{"packw", c64, encodeR(0b0111011, 0b100, 0b0000100, Ra0, Ra1, Ra2), 0x1234, 0x8765, 0xffffffff87651234},
+		{"brev8", c64, encodeI(0b0010011, 0b101, 0x687, Ra0, Ra1), 0x0102040880c0e0f0, 0, 0x804020100103070f},
+		{"rev8", c32, encodeI(0b0010011, 0b101, 0x698, Ra0, Ra1), 0x12345680, 0, 0xffffffff80563412},
+		{"ror", c32, encodeR(op, 0b101, 0b0110000, Ra0, Ra1, Ra2), 0x00000001, 33, 0xffffffff80000000},
+		{"rol", c32, encodeR(op, 0b001, 0b0110000, Ra0, Ra1, Ra2), 0x80000000, 1, 1},
+		{"rori", c32, encodeI(0b0010011, 0b101, 0x600|4, Ra0, Ra1), 0x12345678, 0, 0xffffffff81234567},
+		{"zip", c32, encodeI(0b0010011, 0b001, 0x08f, Ra0, Ra1), 0xffff0000, 0, 0xffffffffaaaaaaaa},
+		{"unzip", c32, encodeI(0b0010011, 0b101, 0x08f, Ra0, Ra1), 0xaaaaaaaa, 0, 0xffffffffffff0000},
+		{"clmul", c64, encodeR(op, 0b001, 0b0000101, Ra0, Ra1, Ra2), 0b1011, 0b0111, 0b110001},
+		{"clmulh", c64, encodeR(op, 0b011, 0b0000101, Ra0, Ra1, Ra2), 1 << 63, 0b110, 0b11},
+		{"clmulh", c32, encodeR(op, 0b011, 0b0000101, Ra0, Ra1, Ra2), 1 << 31, 0b110, 0b11},
+		{"xperm4", c64, encodeR(op, 0b010, 0b0010100, Ra0, Ra1, Ra2), 0xfedcba9876543210, 0x0123456789abcdef, 0x0123456789abcdef},
+		{"xperm8", c64, encodeR(op, 0b100, 0b0010100, Ra0, Ra1, Ra2), 0x0706050403020100, 0xff00010203040506, 0x0000010203040506},
+		{"xperm8", c32, encodeR(op, 0b100, 0b0010100, Ra0, Ra1, Ra2), 0x44332211, 0x04030201, 0x00443322},
+	} {
+		if v := run(t, e.c, e.i, e.a, e.b); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+	}
+	// zip and unzip are RV32 only.
+	if err := execute(c64, encodeI(0b0010011, 0b001, 0x08f, Ra0, Ra1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	// The rotations are in Zbkb as well as in Zbb.
+	c := newVectorCPU()
+	c.SetISA(c.GetISA()&^ISAZbb | ISAZbkb)
+	for _, e := range []struct {
+		name string
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		{"rol", encodeR(op, 0b001, 0b0110000, Ra0, Ra1, Ra2), 0x8000000000000001, 1, 3},
+		{"rolw", encodeR(0b0111011, 0b001, 0b0110000, Ra0, Ra1, Ra2), 0x40000000, 1, 0xffffffff80000000},
+		{"rorw", encodeR(0b0111011, 0b101, 0b0110000, Ra0, Ra1, Ra2), 1, 1, 0xffffffff80000000},
+		{"roriw", encodeI(0b0011011, 0b101, 0x600|1, Ra0, Ra1), 1, 0, 0xffffffff80000000},
+	} {
+		if v := run(t, c, e.i, e.a, e.b); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+	}
+}
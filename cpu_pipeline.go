@@ -14,14 +14,17 @@ package rv64
 // |     |     | IF  | ID  | EX  | MEM | WB  |     |     |
 // |     |     |     | IF  | ID  | EX  | MEM | WB  |     |
 // |     |     |     |     | IF  | ID  | EX  | MEM | WB  |
+//
+// Execution itself is functional, each instruction completes before the next one starts. Timing estimates the cycles
+// the pipeline would take.
 
 func (c *CPU) PipelineInstructionFetch() ([]byte, error) {
-	a, err := c.GetMemory().GetByte(c.GetPC(), 2)
+	a, err := c.GetMemoryFetch().GetByte(c.GetPC(), 2)
 	if err != nil {
 		return nil, err
 	}
 	b := InstructionLengthEncoding(a)
-	r, err := c.GetMemory().GetByte(c.GetPC(), uint64(b))
+	r, err := c.GetMemoryFetch().GetByte(c.GetPC(), uint64(b))
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +36,9 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 	for j := len(data) - 1; j >= 0; j-- {
 		i += uint64(data[j]) << (8 * j)
 	}
+	if c.GetISA()&ISAE != 0 && c.usesUpperRegister(i, len(data)) {
+		return 0, ErrAbnormalInstruction
+	}
 	switch len(data) {
 	case 2:
 		opcode := InstructionPart(i, 0, 1)
@@ -45,6 +51,9 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 		case 0b00_010:
 			return aluC.lw(c, i)
 		case 0b00_011:
+			if c.GetXLEN() == 32 {
+				return aluC.flw(c, i)
+			}
 			return aluC.ld(c, i)
 		case 0b00_100:
 			return 0, ErrReservedInstruction
@@ -53,10 +62,16 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 		case 0b00_110:
 			return aluC.sw(c, i)
 		case 0b00_111:
+			if c.GetXLEN() == 32 {
+				return aluC.fsw(c, i)
+			}
 			return aluC.sd(c, i)
 		case 0b01_000:
 			return aluC.addi(c, i)
 		case 0b01_001:
+			if c.GetXLEN() == 32 {
+				return aluC.jal(c, i)
+			}
 			return aluC.addiw(c, i)
 		case 0b01_010:
 			return aluC.li(c, i)
@@ -67,6 +82,10 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				return aluC.lui(c, i)
 			}
 		case 0b01_100:
+			// On RV32 a shift amount with bit 5 set is reserved.
+			if c.GetXLEN() == 32 && InstructionPart(i, 10, 11) != 0b10 && InstructionPart(i, 12, 12) == 1 {
+				return 0, ErrReservedInstruction
+			}
 			switch InstructionPart(i, 10, 11) {
 			case 0b00:
 				return aluC.srli(c, i)
@@ -85,9 +104,15 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				case 0b0_11:
 					return aluC.and(c, i)
 				case 0b1_00:
-					return aluC.subw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluC.subw(c, i)
+					}
+					return 0, ErrReservedInstruction
 				case 0b1_01:
-					return aluC.addw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluC.addw(c, i)
+					}
+					return 0, ErrReservedInstruction
 				case 0b1_10:
 					return 0, ErrReservedInstruction
 				case 0b1_11:
@@ -101,12 +126,18 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 		case 0b01_111:
 			return aluC.bnez(c, i)
 		case 0b10_000:
+			if c.GetXLEN() == 32 && InstructionPart(i, 12, 12) == 1 {
+				return 0, ErrReservedInstruction
+			}
 			return aluC.slli(c, i)
 		case 0b10_001:
 			return aluC.fldsp(c, i)
 		case 0b10_010:
 			return aluC.lwsp(c, i)
 		case 0b10_011:
+			if c.GetXLEN() == 32 {
+				return aluC.flwsp(c, i)
+			}
 			return aluC.ldsp(c, i)
 		case 0b10_100:
 			switch InstructionPart(i, 12, 12) {
@@ -132,6 +163,9 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 		case 0b10_110:
 			return aluC.swsp(c, i)
 		case 0b10_111:
+			if c.GetXLEN() == 32 {
+				return aluC.fswsp(c, i)
+			}
 			return aluC.sdsp(c, i)
 		}
 	case 4:
@@ -171,13 +205,17 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 			case 0b010:
 				return aluI.lw(c, i)
 			case 0b011:
-				return aluI.ld(c, i)
+				if c.GetXLEN() == 64 {
+					return aluI.ld(c, i)
+				}
 			case 0b100:
 				return aluI.lbu(c, i)
 			case 0b101:
 				return aluI.lhu(c, i)
 			case 0b110:
-				return aluI.lwu(c, i)
+				if c.GetXLEN() == 64 {
+					return aluI.lwu(c, i)
+				}
 			}
 		case 0b0100011:
 			switch funct3 {
@@ -188,7 +226,9 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 			case 0b010:
 				return aluI.sw(c, i)
 			case 0b011:
-				return aluI.sd(c, i)
+				if c.GetXLEN() == 64 {
+					return aluI.sd(c, i)
+				}
 			}
 		case 0b0010011:
 			switch funct3 {
@@ -205,13 +245,111 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 			case 0b111:
 				return aluI.andi(c, i)
 			case 0b001:
-				return aluI.slli(c, i)
+				switch InstructionPart(i, 26, 31) {
+				case 0b000000:
+					if c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0 {
+						return aluI.slli(c, i)
+					}
+				case 0b011000:
+					if c.GetISA()&ISAZbb != 0 && InstructionPart(i, 25, 25) == 0 {
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluZbb.clz(c, i)
+						case 0b00001:
+							return aluZbb.ctz(c, i)
+						case 0b00010:
+							return aluZbb.cpop(c, i)
+						case 0b00100:
+							return aluZbb.sextb(c, i)
+						case 0b00101:
+							return aluZbb.sexth(c, i)
+						}
+					}
+				case 0b010010:
+					if c.GetISA()&ISAZbs != 0 && (c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0) {
+						return aluZbs.bclri(c, i)
+					}
+				case 0b011010:
+					if c.GetISA()&ISAZbs != 0 && (c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0) {
+						return aluZbs.binvi(c, i)
+					}
+				case 0b001010:
+					if c.GetISA()&ISAZbs != 0 && (c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0) {
+						return aluZbs.bseti(c, i)
+					}
+				case 0b000010:
+					if c.GetISA()&ISAZbkb != 0 && c.GetXLEN() == 32 && InstructionPart(i, 20, 25) == 0b001111 {
+						return aluZbkb.zip(c, i)
+					}
+				case 0b000100:
+					if c.GetISA()&ISAZknh != 0 {
+						switch InstructionPart(i, 20, 25) {
+						case 0b000000:
+							return aluZknh.sha256sum0(c, i)
+						case 0b000001:
+							return aluZknh.sha256sum1(c, i)
+						case 0b000010:
+							return aluZknh.sha256sig0(c, i)
+						case 0b000011:
+							return aluZknh.sha256sig1(c, i)
+						}
+						if c.GetXLEN() == 64 {
+							switch InstructionPart(i, 20, 25) {
+							case 0b000100:
+								return aluZknh.sha512sum0(c, i)
+							case 0b000101:
+								return aluZknh.sha512sum1(c, i)
+							case 0b000110:
+								return aluZknh.sha512sig0(c, i)
+							case 0b000111:
+								return aluZknh.sha512sig1(c, i)
+							}
+						}
+					}
+				case 0b001100:
+					if c.GetXLEN() == 64 {
+						if c.GetISA()&ISAZknd != 0 && InstructionPart(i, 20, 25) == 0b000000 {
+							return aluZknd.aes64im(c, i)
+						}
+						if c.GetISA()&(ISAZknd|ISAZkne) != 0 && InstructionPart(i, 24, 25) == 0b01 {
+							return aluZkne.aes64ks1i(c, i)
+						}
+					}
+				}
 			case 0b101:
 				switch InstructionPart(i, 26, 31) {
 				case 0b000000:
-					return aluI.srli(c, i)
+					if c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0 {
+						return aluI.srli(c, i)
+					}
 				case 0b010000:
-					return aluI.srai(c, i)
+					if c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0 {
+						return aluI.srai(c, i)
+					}
+				case 0b011000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 && (c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0) {
+						return aluZbb.rori(c, i)
+					}
+				case 0b010010:
+					if c.GetISA()&ISAZbs != 0 && (c.GetXLEN() == 64 || InstructionPart(i, 25, 25) == 0) {
+						return aluZbs.bexti(c, i)
+					}
+				case 0b001010:
+					if c.GetISA()&ISAZbb != 0 && InstructionPart(i, 20, 25) == 0b000111 {
+						return aluZbb.orcb(c, i)
+					}
+				case 0b011010:
+					// rev8 is encoded with the shift amount XLEN-8.
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 && InstructionPart(i, 20, 25) == c.GetXLEN()-8 {
+						return aluZbb.rev8(c, i)
+					}
+					if c.GetISA()&ISAZbkb != 0 && InstructionPart(i, 20, 25) == 0b000111 {
+						return aluZbkb.brev8(c, i)
+					}
+				case 0b000010:
+					if c.GetISA()&ISAZbkb != 0 && c.GetXLEN() == 32 && InstructionPart(i, 20, 25) == 0b001111 {
+						return aluZbkb.unzip(c, i)
+					}
 				}
 			}
 		case 0b0110011:
@@ -224,6 +362,63 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluM.mul(c, i)
 				case 0b0100000:
 					return aluI.sub(c, i)
+				case 0b0011001:
+					if c.GetISA()&ISAZkne != 0 && c.GetXLEN() == 64 {
+						return aluZkne.aes64es(c, i)
+					}
+				case 0b0011011:
+					if c.GetISA()&ISAZkne != 0 && c.GetXLEN() == 64 {
+						return aluZkne.aes64esm(c, i)
+					}
+				case 0b0011101:
+					if c.GetISA()&ISAZknd != 0 && c.GetXLEN() == 64 {
+						return aluZknd.aes64ds(c, i)
+					}
+				case 0b0011111:
+					if c.GetISA()&ISAZknd != 0 && c.GetXLEN() == 64 {
+						return aluZknd.aes64dsm(c, i)
+					}
+				case 0b0111111:
+					if c.GetISA()&(ISAZknd|ISAZkne) != 0 && c.GetXLEN() == 64 {
+						return aluZkne.aes64ks2(c, i)
+					}
+				}
+				if c.GetXLEN() == 32 {
+					// The RV32 AES instructions hold the byte select in funct7[6:5].
+					switch InstructionPart(i, 25, 29) {
+					case 0b10001:
+						if c.GetISA()&ISAZkne != 0 {
+							return aluZkne.aes32esi(c, i)
+						}
+					case 0b10011:
+						if c.GetISA()&ISAZkne != 0 {
+							return aluZkne.aes32esmi(c, i)
+						}
+					case 0b10101:
+						if c.GetISA()&ISAZknd != 0 {
+							return aluZknd.aes32dsi(c, i)
+						}
+					case 0b10111:
+						if c.GetISA()&ISAZknd != 0 {
+							return aluZknd.aes32dsmi(c, i)
+						}
+					}
+					if c.GetISA()&ISAZknh != 0 {
+						switch funct7 {
+						case 0b0101000:
+							return aluZknh.sha512sum0r(c, i)
+						case 0b0101001:
+							return aluZknh.sha512sum1r(c, i)
+						case 0b0101010:
+							return aluZknh.sha512sig0l(c, i)
+						case 0b0101110:
+							return aluZknh.sha512sig0h(c, i)
+						case 0b0101011:
+							return aluZknh.sha512sig1l(c, i)
+						case 0b0101111:
+							return aluZknh.sha512sig1h(c, i)
+						}
+					}
 				}
 			case 0b001:
 				switch funct7 {
@@ -231,6 +426,26 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.sll(c, i)
 				case 0b0000001:
 					return aluM.mulh(c, i)
+				case 0b0110000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 {
+						return aluZbb.rol(c, i)
+					}
+				case 0b0000101:
+					if c.GetISA()&ISAZbkc != 0 {
+						return aluZbkc.clmul(c, i)
+					}
+				case 0b0100100:
+					if c.GetISA()&ISAZbs != 0 {
+						return aluZbs.bclr(c, i)
+					}
+				case 0b0110100:
+					if c.GetISA()&ISAZbs != 0 {
+						return aluZbs.binv(c, i)
+					}
+				case 0b0010100:
+					if c.GetISA()&ISAZbs != 0 {
+						return aluZbs.bset(c, i)
+					}
 				}
 			case 0b010:
 				switch funct7 {
@@ -238,6 +453,14 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.slt(c, i)
 				case 0b0000001:
 					return aluM.mulhsu(c, i)
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 {
+						return aluZba.sh1add(c, i)
+					}
+				case 0b0010100:
+					if c.GetISA()&ISAZbkx != 0 {
+						return aluZbkx.xperm4(c, i)
+					}
 				}
 			case 0b011:
 				switch funct7 {
@@ -245,6 +468,10 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.sltu(c, i)
 				case 0b0000001:
 					return aluM.mulhu(c, i)
+				case 0b0000101:
+					if c.GetISA()&ISAZbkc != 0 {
+						return aluZbkc.clmulh(c, i)
+					}
 				}
 			case 0b100:
 				switch funct7 {
@@ -252,6 +479,30 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.xor(c, i)
 				case 0b0000001:
 					return aluM.div(c, i)
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 {
+						return aluZba.sh2add(c, i)
+					}
+				case 0b0100000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 {
+						return aluZbb.xnor(c, i)
+					}
+				case 0b0000100:
+					// On RV32 zext.h is encoded as pack with rs2 x0.
+					if c.GetISA()&ISAZbb != 0 && c.GetXLEN() == 32 && InstructionPart(i, 20, 24) == 0 {
+						return aluZbb.zexth(c, i)
+					}
+					if c.GetISA()&ISAZbkb != 0 {
+						return aluZbkb.pack(c, i)
+					}
+				case 0b0010100:
+					if c.GetISA()&ISAZbkx != 0 {
+						return aluZbkx.xperm8(c, i)
+					}
+				case 0b0000101:
+					if c.GetISA()&ISAZbb != 0 {
+						return aluZbb.min(c, i)
+					}
 				}
 			case 0b101:
 				switch funct7 {
@@ -261,6 +512,22 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluM.divu(c, i)
 				case 0b0100000:
 					return aluI.sra(c, i)
+				case 0b0110000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 {
+						return aluZbb.ror(c, i)
+					}
+				case 0b0100100:
+					if c.GetISA()&ISAZbs != 0 {
+						return aluZbs.bext(c, i)
+					}
+				case 0b0000101:
+					if c.GetISA()&ISAZbb != 0 {
+						return aluZbb.minu(c, i)
+					}
+				case 0b0000111:
+					if c.GetISA()&ISAZicond != 0 {
+						return aluZicond.czeroeqz(c, i)
+					}
 				}
 			case 0b110:
 				switch funct7 {
@@ -268,6 +535,18 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.or(c, i)
 				case 0b0000001:
 					return aluM.rem(c, i)
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 {
+						return aluZba.sh3add(c, i)
+					}
+				case 0b0100000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 {
+						return aluZbb.orn(c, i)
+					}
+				case 0b0000101:
+					if c.GetISA()&ISAZbb != 0 {
+						return aluZbb.max(c, i)
+					}
 				}
 			case 0b111:
 				switch funct7 {
@@ -275,14 +554,55 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					return aluI.and(c, i)
 				case 0b0000001:
 					return aluM.remu(c, i)
+				case 0b0100000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 {
+						return aluZbb.andn(c, i)
+					}
+				case 0b0000100:
+					if c.GetISA()&ISAZbkb != 0 {
+						return aluZbkb.packh(c, i)
+					}
+				case 0b0000101:
+					if c.GetISA()&ISAZbb != 0 {
+						return aluZbb.maxu(c, i)
+					}
+				case 0b0000111:
+					if c.GetISA()&ISAZicond != 0 {
+						return aluZicond.czeronez(c, i)
+					}
 				}
 			}
 		case 0b0001111:
 			switch funct3 {
 			case 0b000:
+				// pause is encoded as a fence with pred = W and succ = 0.
+				if i == 0x0100000f {
+					return aluZihintpause.pause(c, i)
+				}
 				return aluI.fence(c, i)
 			case 0b001:
 				return aluZifencei.fencei(c, i)
+			case 0b010:
+				if InstructionPart(i, 7, 11) == 0 {
+					switch InstructionPart(i, 20, 31) {
+					case 0b000000000000:
+						if c.GetISA()&ISAZicbom != 0 {
+							return aluZicbom.cboinval(c, i)
+						}
+					case 0b000000000001:
+						if c.GetISA()&ISAZicbom != 0 {
+							return aluZicbom.cboclean(c, i)
+						}
+					case 0b000000000010:
+						if c.GetISA()&ISAZicbom != 0 {
+							return aluZicbom.cboflush(c, i)
+						}
+					case 0b000000000100:
+						if c.GetISA()&ISAZicboz != 0 {
+							return aluZicboz.cbozero(c, i)
+						}
+					}
+				}
 			}
 		case 0b1110011:
 			switch funct3 {
@@ -321,15 +641,45 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 		case 0b0011011:
 			switch funct3 {
 			case 0b000:
-				return aluI.addiw(c, i)
+				if c.GetXLEN() == 64 {
+					return aluI.addiw(c, i)
+				}
 			case 0b001:
-				return aluI.slliw(c, i)
+				switch funct7 {
+				case 0b0000000:
+					if c.GetXLEN() == 64 {
+						return aluI.slliw(c, i)
+					}
+				case 0b0000100, 0b0000101:
+					if c.GetISA()&ISAZba != 0 && c.GetXLEN() == 64 {
+						return aluZba.slliuw(c, i)
+					}
+				case 0b0110000:
+					if c.GetISA()&ISAZbb != 0 && c.GetXLEN() == 64 {
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluZbb.clzw(c, i)
+						case 0b00001:
+							return aluZbb.ctzw(c, i)
+						case 0b00010:
+							return aluZbb.cpopw(c, i)
+						}
+					}
+				}
 			case 0b101:
 				switch funct7 {
 				case 0b0000000:
-					return aluI.srliw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.srliw(c, i)
+					}
 				case 0b0100000:
-					return aluI.sraiw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.sraiw(c, i)
+					}
+				case 0b0110000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 && c.GetXLEN() == 64 {
+						return aluZbb.roriw(c, i)
+					}
 				}
 			}
 		case 0b0111011:
@@ -337,34 +687,194 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 			case 0b000:
 				switch funct7 {
 				case 0b0000000:
-					return aluI.addw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.addw(c, i)
+					}
 				case 0b0000001:
-					return aluM.mulw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluM.mulw(c, i)
+					}
 				case 0b0100000:
-					return aluI.subw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.subw(c, i)
+					}
+				case 0b0000100:
+					if c.GetISA()&ISAZba != 0 && c.GetXLEN() == 64 {
+						return aluZba.adduw(c, i)
+					}
 				}
 			case 0b001:
-				return aluI.sllw(c, i)
+				switch funct7 {
+				case 0b0000000:
+					if c.GetXLEN() == 64 {
+						return aluI.sllw(c, i)
+					}
+				case 0b0110000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 && c.GetXLEN() == 64 {
+						return aluZbb.rolw(c, i)
+					}
+				}
+			case 0b010:
+				switch funct7 {
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 && c.GetXLEN() == 64 {
+						return aluZba.sh1adduw(c, i)
+					}
+				}
 			case 0b100:
-				return aluM.divw(c, i)
+				switch funct7 {
+				case 0b0000001:
+					if c.GetXLEN() == 64 {
+						return aluM.divw(c, i)
+					}
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 && c.GetXLEN() == 64 {
+						return aluZba.sh2adduw(c, i)
+					}
+				case 0b0000100:
+					if c.GetISA()&ISAZbb != 0 && c.GetXLEN() == 64 && InstructionPart(i, 20, 24) == 0 {
+						return aluZbb.zexth(c, i)
+					}
+					if c.GetISA()&ISAZbkb != 0 && c.GetXLEN() == 64 {
+						return aluZbkb.packw(c, i)
+					}
+				}
 			case 0b101:
 				switch funct7 {
 				case 0b0000000:
-					return aluI.srlw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.srlw(c, i)
+					}
 				case 0b0000001:
-					return aluM.divuw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluM.divuw(c, i)
+					}
 				case 0b0100000:
-					return aluI.sraw(c, i)
+					if c.GetXLEN() == 64 {
+						return aluI.sraw(c, i)
+					}
+				case 0b0110000:
+					if c.GetISA()&(ISAZbb|ISAZbkb) != 0 && c.GetXLEN() == 64 {
+						return aluZbb.rorw(c, i)
+					}
 				}
 			case 0b110:
-				return aluM.remw(c, i)
+				switch funct7 {
+				case 0b0000001:
+					if c.GetXLEN() == 64 {
+						return aluM.remw(c, i)
+					}
+				case 0b0010000:
+					if c.GetISA()&ISAZba != 0 && c.GetXLEN() == 64 {
+						return aluZba.sh3adduw(c, i)
+					}
+				}
 			case 0b111:
-				return aluM.remuw(c, i)
+				switch funct7 {
+				case 0b0000001:
+					if c.GetXLEN() == 64 {
+						return aluM.remuw(c, i)
+					}
+				}
 			}
 		case 0b0101111:
 			switch funct3 {
+			case 0b000:
+				switch InstructionPart(i, 27, 31) {
+				case 0b00001:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoswapb(c, i)
+					}
+				case 0b00000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoaddb(c, i)
+					}
+				case 0b00100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoxorb(c, i)
+					}
+				case 0b01100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoandb(c, i)
+					}
+				case 0b01000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoorb(c, i)
+					}
+				case 0b10000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amominb(c, i)
+					}
+				case 0b10100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amomaxb(c, i)
+					}
+				case 0b11000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amominub(c, i)
+					}
+				case 0b11100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amomaxub(c, i)
+					}
+				case 0b00101:
+					if c.GetISA()&ISAZacas != 0 && c.GetISA()&ISAZabha != 0 {
+						return aluZacas.amocasb(c, i)
+					}
+				}
+			case 0b001:
+				switch InstructionPart(i, 27, 31) {
+				case 0b00001:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoswaph(c, i)
+					}
+				case 0b00000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoaddh(c, i)
+					}
+				case 0b00100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoxorh(c, i)
+					}
+				case 0b01100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoandh(c, i)
+					}
+				case 0b01000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amoorh(c, i)
+					}
+				case 0b10000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amominh(c, i)
+					}
+				case 0b10100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amomaxh(c, i)
+					}
+				case 0b11000:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amominuh(c, i)
+					}
+				case 0b11100:
+					if c.GetISA()&ISAZabha != 0 {
+						return aluZabha.amomaxuh(c, i)
+					}
+				case 0b00101:
+					if c.GetISA()&ISAZacas != 0 && c.GetISA()&ISAZabha != 0 {
+						return aluZacas.amocash(c, i)
+					}
+				}
+			case 0b100:
+				if InstructionPart(i, 27, 31) == 0b00101 && c.GetISA()&ISAZacas != 0 && c.GetXLEN() == 64 {
+					return aluZacas.amocasq(c, i)
+				}
 			case 0b010:
 				switch InstructionPart(i, 27, 31) {
+				case 0b00101:
+					if c.GetISA()&ISAZacas != 0 {
+						return aluZacas.amocasw(c, i)
+					}
 				case 0b00010:
 					return aluA.lrw(c, i)
 				case 0b00011:
@@ -390,43 +900,137 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				}
 			case 0b011:
 				switch InstructionPart(i, 27, 31) {
+				case 0b00101:
+					if c.GetISA()&ISAZacas != 0 {
+						return aluZacas.amocasd(c, i)
+					}
 				case 0b00010:
-					return aluA.lrd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.lrd(c, i)
+					}
 				case 0b00011:
-					return aluA.scd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.scd(c, i)
+					}
 				case 0b00001:
-					return aluA.amoswapd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amoswapd(c, i)
+					}
 				case 0b00000:
-					return aluA.amoaddd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amoaddd(c, i)
+					}
 				case 0b00100:
-					return aluA.amoxord(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amoxord(c, i)
+					}
 				case 0b01100:
-					return aluA.amoandd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amoandd(c, i)
+					}
 				case 0b01000:
-					return aluA.amoord(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amoord(c, i)
+					}
 				case 0b10000:
-					return aluA.amomind(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amomind(c, i)
+					}
 				case 0b10100:
-					return aluA.amomaxd(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amomaxd(c, i)
+					}
 				case 0b11000:
-					return aluA.amominud(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amominud(c, i)
+					}
 				case 0b11100:
-					return aluA.amomaxud(c, i)
+					if c.GetXLEN() == 64 {
+						return aluA.amomaxud(c, i)
+					}
 				}
 			}
 		case 0b0000111:
 			switch funct3 {
+			case 0b001:
+				if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+					return aluZfh.flh(c, i)
+				}
 			case 0b010:
 				return aluF.flw(c, i)
 			case 0b011:
 				return aluD.fld(c, i)
+			case 0b100:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.flq(c, i)
+				}
+			case 0b000, 0b101, 0b110, 0b111:
+				if c.GetISA()&ISAV != 0 {
+					switch InstructionPart(i, 26, 27) {
+					case 0b00:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluV.vle(c, i)
+						case 0b01000:
+							return aluV.vlr(c, i)
+						case 0b01011:
+							return aluV.vlm(c, i)
+						case 0b10000:
+							return aluV.vleff(c, i)
+						}
+					case 0b01, 0b11:
+						return aluV.vlx(c, i)
+					case 0b10:
+						return aluV.vlse(c, i)
+					}
+				}
 			}
 		case 0b0100111:
 			switch funct3 {
+			case 0b001:
+				if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+					return aluZfh.fsh(c, i)
+				}
 			case 0b010:
 				return aluF.fsw(c, i)
 			case 0b011:
 				return aluD.fsd(c, i)
+			case 0b100:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.fsq(c, i)
+				}
+			case 0b000, 0b101, 0b110, 0b111:
+				if c.GetISA()&ISAV != 0 {
+					switch InstructionPart(i, 26, 27) {
+					case 0b00:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluV.vse(c, i)
+						case 0b01000:
+							return aluV.vsr(c, i)
+						case 0b01011:
+							return aluV.vsm(c, i)
+						}
+					case 0b01, 0b11:
+						return aluV.vsx(c, i)
+					case 0b10:
+						return aluV.vsse(c, i)
+					}
+				}
+			}
+		case 0b1010111:
+			if c.GetISA()&ISAV != 0 {
+				if funct3 != vOPCFG {
+					return aluV.arith(c, i)
+				}
+				switch {
+				case InstructionPart(i, 31, 31) == 0b0:
+					return aluV.vsetvli(c, i)
+				case InstructionPart(i, 30, 31) == 0b11:
+					return aluV.vsetivli(c, i)
+				case InstructionPart(i, 25, 31) == 0b1000000:
+					return aluV.vsetvl(c, i)
+				}
 			}
 		case 0b1000011:
 			switch InstructionPart(i, 25, 26) {
@@ -434,6 +1038,14 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				return aluF.fmadds(c, i)
 			case 0b01:
 				return aluD.fmaddd(c, i)
+			case 0b10:
+				if c.GetISA()&ISAZfh != 0 {
+					return aluZfh.fmaddh(c, i)
+				}
+			case 0b11:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.fmaddq(c, i)
+				}
 			}
 		case 0b1000111:
 			switch InstructionPart(i, 25, 26) {
@@ -441,6 +1053,14 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				return aluF.fmsubs(c, i)
 			case 0b01:
 				return aluD.fmsubd(c, i)
+			case 0b10:
+				if c.GetISA()&ISAZfh != 0 {
+					return aluZfh.fmsubh(c, i)
+				}
+			case 0b11:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.fmsubq(c, i)
+				}
 			}
 		case 0b1001011:
 			switch InstructionPart(i, 25, 26) {
@@ -448,6 +1068,14 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				return aluF.fnmsubs(c, i)
 			case 0b01:
 				return aluD.fnmsubd(c, i)
+			case 0b10:
+				if c.GetISA()&ISAZfh != 0 {
+					return aluZfh.fnmsubh(c, i)
+				}
+			case 0b11:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.fnmsubq(c, i)
+				}
 			}
 		case 0b1001111:
 			switch InstructionPart(i, 25, 26) {
@@ -455,6 +1083,14 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				return aluF.fnmadds(c, i)
 			case 0b01:
 				return aluD.fnmaddd(c, i)
+			case 0b10:
+				if c.GetISA()&ISAZfh != 0 {
+					return aluZfh.fnmaddh(c, i)
+				}
+			case 0b11:
+				if c.GetISA()&ISAQ != 0 {
+					return aluQ.fnmaddq(c, i)
+				}
 			}
 		case 0b1010011:
 			switch InstructionPart(i, 25, 26) {
@@ -493,12 +1129,27 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					case 0b00001:
 						return aluF.fcvtwus(c, i)
 					case 0b00010:
-						return aluF.fcvtls(c, i)
+						if c.GetXLEN() == 64 {
+							return aluF.fcvtls(c, i)
+						}
 					case 0b00011:
-						return aluF.fcvtlus(c, i)
+						if c.GetXLEN() == 64 {
+							return aluF.fcvtlus(c, i)
+						}
 					}
 				case 0b01000:
-					return aluD.fcvtsd(c, i)
+					switch InstructionPart(i, 20, 24) {
+					case 0b00001:
+						return aluD.fcvtsd(c, i)
+					case 0b00010:
+						if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+							return aluZfh.fcvtsh(c, i)
+						}
+					case 0b00011:
+						if c.GetISA()&ISAQ != 0 {
+							return aluQ.fcvtsq(c, i)
+						}
+					}
 				case 0b11100:
 					switch InstructionPart(i, 12, 14) {
 					case 0b000:
@@ -522,9 +1173,13 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					case 0b00001:
 						return aluF.fcvtswu(c, i)
 					case 0b00010:
-						return aluF.fcvtsl(c, i)
+						if c.GetXLEN() == 64 {
+							return aluF.fcvtsl(c, i)
+						}
 					case 0b00011:
-						return aluF.fcvtslu(c, i)
+						if c.GetXLEN() == 64 {
+							return aluF.fcvtslu(c, i)
+						}
 					}
 				case 0b11110:
 					return aluF.fmvwx(c, i)
@@ -564,12 +1219,27 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					case 0b00001:
 						return aluD.fcvtwud(c, i)
 					case 0b00010:
-						return aluD.fcvtld(c, i)
+						if c.GetXLEN() == 64 {
+							return aluD.fcvtld(c, i)
+						}
 					case 0b00011:
-						return aluD.fcvtlud(c, i)
+						if c.GetXLEN() == 64 {
+							return aluD.fcvtlud(c, i)
+						}
 					}
 				case 0b01000:
-					return aluD.fcvtds(c, i)
+					switch InstructionPart(i, 20, 24) {
+					case 0b00000:
+						return aluD.fcvtds(c, i)
+					case 0b00010:
+						if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+							return aluZfh.fcvtdh(c, i)
+						}
+					case 0b00011:
+						if c.GetISA()&ISAQ != 0 {
+							return aluQ.fcvtdq(c, i)
+						}
+					}
 				case 0b10100:
 					switch InstructionPart(i, 12, 14) {
 					case 0b010:
@@ -582,7 +1252,9 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 				case 0b11100:
 					switch InstructionPart(i, 12, 14) {
 					case 0b000:
-						return aluD.fmvxd(c, i)
+						if c.GetXLEN() == 64 {
+							return aluD.fmvxd(c, i)
+						}
 					case 0b001:
 						return aluD.fclassd(c, i)
 					}
@@ -593,12 +1265,198 @@ func (c *CPU) PipelineExecute(data []byte) (uint64, error) {
 					case 0b00001:
 						return aluD.fcvtdwu(c, i)
 					case 0b00010:
-						return aluD.fcvtdl(c, i)
+						if c.GetXLEN() == 64 {
+							return aluD.fcvtdl(c, i)
+						}
 					case 0b00011:
-						return aluD.fcvtdlu(c, i)
+						if c.GetXLEN() == 64 {
+							return aluD.fcvtdlu(c, i)
+						}
 					}
 				case 0b11110:
-					return aluD.fmvdx(c, i)
+					if c.GetXLEN() == 64 {
+						return aluD.fmvdx(c, i)
+					}
+				}
+			case 0b10:
+				if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+					switch InstructionPart(i, 27, 31) {
+					case 0b01000:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluZfh.fcvths(c, i)
+						case 0b00001:
+							return aluZfh.fcvthd(c, i)
+						case 0b00011:
+							if c.GetISA()&ISAQ != 0 {
+								return aluQ.fcvthq(c, i)
+							}
+						}
+					case 0b11100:
+						if funct3 == 0b000 {
+							return aluZfh.fmvxh(c, i)
+						}
+					case 0b11110:
+						return aluZfh.fmvhx(c, i)
+					}
+				}
+				if c.GetISA()&ISAZfh != 0 {
+					switch InstructionPart(i, 27, 31) {
+					case 0b00000:
+						return aluZfh.faddh(c, i)
+					case 0b00001:
+						return aluZfh.fsubh(c, i)
+					case 0b00010:
+						return aluZfh.fmulh(c, i)
+					case 0b00011:
+						return aluZfh.fdivh(c, i)
+					case 0b01011:
+						return aluZfh.fsqrth(c, i)
+					case 0b00100:
+						switch funct3 {
+						case 0b000:
+							return aluZfh.fsgnjh(c, i)
+						case 0b001:
+							return aluZfh.fsgnjnh(c, i)
+						case 0b010:
+							return aluZfh.fsgnjxh(c, i)
+						}
+					case 0b00101:
+						switch funct3 {
+						case 0b000:
+							return aluZfh.fminh(c, i)
+						case 0b001:
+							return aluZfh.fmaxh(c, i)
+						}
+					case 0b11000:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluZfh.fcvtwh(c, i)
+						case 0b00001:
+							return aluZfh.fcvtwuh(c, i)
+						case 0b00010:
+							if c.GetXLEN() == 64 {
+								return aluZfh.fcvtlh(c, i)
+							}
+						case 0b00011:
+							if c.GetXLEN() == 64 {
+								return aluZfh.fcvtluh(c, i)
+							}
+						}
+					case 0b10100:
+						switch funct3 {
+						case 0b010:
+							return aluZfh.feqh(c, i)
+						case 0b001:
+							return aluZfh.flth(c, i)
+						case 0b000:
+							return aluZfh.fleh(c, i)
+						}
+					case 0b11100:
+						if funct3 == 0b001 {
+							return aluZfh.fclassh(c, i)
+						}
+					case 0b11010:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluZfh.fcvthw(c, i)
+						case 0b00001:
+							return aluZfh.fcvthwu(c, i)
+						case 0b00010:
+							if c.GetXLEN() == 64 {
+								return aluZfh.fcvthl(c, i)
+							}
+						case 0b00011:
+							if c.GetXLEN() == 64 {
+								return aluZfh.fcvthlu(c, i)
+							}
+						}
+					}
+				}
+			case 0b11:
+				if c.GetISA()&ISAQ != 0 {
+					switch InstructionPart(i, 27, 31) {
+					case 0b00000:
+						return aluQ.faddq(c, i)
+					case 0b00001:
+						return aluQ.fsubq(c, i)
+					case 0b00010:
+						return aluQ.fmulq(c, i)
+					case 0b00011:
+						return aluQ.fdivq(c, i)
+					case 0b01011:
+						return aluQ.fsqrtq(c, i)
+					case 0b00100:
+						switch funct3 {
+						case 0b000:
+							return aluQ.fsgnjq(c, i)
+						case 0b001:
+							return aluQ.fsgnjnq(c, i)
+						case 0b010:
+							return aluQ.fsgnjxq(c, i)
+						}
+					case 0b00101:
+						switch funct3 {
+						case 0b000:
+							return aluQ.fminq(c, i)
+						case 0b001:
+							return aluQ.fmaxq(c, i)
+						}
+					case 0b01000:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluQ.fcvtqs(c, i)
+						case 0b00001:
+							return aluQ.fcvtqd(c, i)
+						case 0b00010:
+							if c.GetISA()&(ISAZfh|ISAZfhmin) != 0 {
+								return aluQ.fcvtqh(c, i)
+							}
+						}
+					case 0b11000:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluQ.fcvtwq(c, i)
+						case 0b00001:
+							return aluQ.fcvtwuq(c, i)
+						case 0b00010:
+							if c.GetXLEN() == 64 {
+								return aluQ.fcvtlq(c, i)
+							}
+						case 0b00011:
+							if c.GetXLEN() == 64 {
+								return aluQ.fcvtluq(c, i)
+							}
+						}
+					case 0b10100:
+						switch funct3 {
+						case 0b010:
+							return aluQ.feqq(c, i)
+						case 0b001:
+							return aluQ.fltq(c, i)
+						case 0b000:
+							return aluQ.fleq(c, i)
+						}
+					case 0b11100:
+						if funct3 == 0b001 {
+							return aluQ.fclassq(c, i)
+						}
+					case 0b11010:
+						switch InstructionPart(i, 20, 24) {
+						case 0b00000:
+							return aluQ.fcvtqw(c, i)
+						case 0b00001:
+							return aluQ.fcvtqwu(c, i)
+						case 0b00010:
+							if c.GetXLEN() == 64 {
+								return aluQ.fcvtql(c, i)
+							}
+						case 0b00011:
+							if c.GetXLEN() == 64 {
+								return aluQ.fcvtqlu(c, i)
+							}
+						}
+					}
 				}
 			}
 		}
@@ -0,0 +1,392 @@
+package rv64
+
+// https://github.com/riscv/riscv-isa-manual/releases/download/Ratified-IMAFDQC/riscv-spec-20191213.pdf
+//
+// Q: Quad-precision floating-point. FLEN is 128, there are no FMV.X.Q and FMV.Q.X instructions in RV64.
+
+import (
+	"fmt"
+	"math"
+)
+
+func (c *CPU) getQ(i uint64) *softfloat {
+	return FloatFormatQ.unpackBig(c.GetRegisterFloatAsFloat128(i).big())
+}
+
+// setQ rounds r to quad precision into register i and raises the accrued exception flags of the operation with it.
+func (c *CPU) setQ(i uint64, r *softfloat, flags uint64, rm uint64) {
+	u, more := FloatFormatQ.roundBig(r, rm)
+	c.SetRegisterFloatAsFloat128(i, float128(u))
+	c.SetFloatFlag(flags|more, 1)
+}
+
+func FClassQ(f Float128) uint64 {
+	x := FloatFormatQ.unpackBig(f.big())
+	switch {
+	case x.snan:
+		return 0b01_00000000
+	case x.nan:
+		return 0b10_00000000
+	}
+	subnormal := f.Hi&0x7fff000000000000 == 0 && !x.zero()
+	if x.neg {
+		if x.inf {
+			return 0b00_00000001
+		} else if x.zero() {
+			return 0b00_00001000
+		} else if subnormal {
+			return 0b00_00000100
+		} else {
+			return 0b00_00000010
+		}
+	}
+	if x.inf {
+		return 0b00_10000000
+	} else if x.zero() {
+		return 0b00_00010000
+	} else if subnormal {
+		return 0b00_00100000
+	} else {
+		return 0b00_01000000
+	}
+}
+
+type isaQ struct{}
+
+func (_ *isaQ) flq(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	imm = SignExtend(imm, 11)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "flq", c.LogF(rd), c.LogI(rs1), imm))
+	a := c.GetRegister(rs1) + imm
+	lo, err := c.GetMemory().GetUint64(a)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := c.GetMemory().GetUint64(a + 8)
+	if err != nil {
+		return 0, err
+	}
+	c.SetRegisterFloatAsFloat128(rd, Float128{Hi: hi, Lo: lo})
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaQ) fsq(c *CPU, i uint64) (uint64, error) {
+	rs1, rs2, imm := SType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s rs1: %s rs2: %s imm: ----(%#016x)", c.GetPC(), "fsq", c.LogI(rs1), c.LogF(rs2), imm))
+	a := c.GetRegister(rs1) + imm
+	f := c.GetRegisterFloatAsFloat128(rs2)
+	if err := c.GetMemory().SetUint64(a, f.Lo); err != nil {
+		return 0, err
+	}
+	if err := c.GetMemory().SetUint64(a+8, f.Hi); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaQ) fma(c *CPU, i uint64, name string, negp bool, negd bool) (uint64, error) {
+	rd, rs1, rs2, rs3 := R4Type(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	a := c.getQ(rs1)
+	b := c.getQ(rs2)
+	d := c.getQ(rs3)
+	a.neg = a.neg != negp
+	d.neg = d.neg != negd
+	r, flags := softMulAdd(a, b, d, rm)
+	c.setQ(rd, r, flags, rm)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fmaddq(c *CPU, i uint64) (uint64, error) {
+	return q.fma(c, i, "fmadd.q", false, false)
+}
+
+func (q *isaQ) fmsubq(c *CPU, i uint64) (uint64, error) {
+	return q.fma(c, i, "fmsub.q", false, true)
+}
+
+func (q *isaQ) fnmsubq(c *CPU, i uint64) (uint64, error) {
+	return q.fma(c, i, "fnmsub.q", true, false)
+}
+
+func (q *isaQ) fnmaddq(c *CPU, i uint64) (uint64, error) {
+	return q.fma(c, i, "fnmadd.q", true, true)
+}
+
+func (_ *isaQ) arith(c *CPU, i uint64, name string, f func(a *softfloat, b *softfloat, rm uint64) (*softfloat, uint64)) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := f(c.getQ(rs1), c.getQ(rs2), rm)
+	c.setQ(rd, r, flags, rm)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) faddq(c *CPU, i uint64) (uint64, error) {
+	return q.arith(c, i, "fadd.q", softAdd)
+}
+
+func (q *isaQ) fsubq(c *CPU, i uint64) (uint64, error) {
+	return q.arith(c, i, "fsub.q", func(a *softfloat, b *softfloat, rm uint64) (*softfloat, uint64) {
+		b.neg = !b.neg
+		return softAdd(a, b, rm)
+	})
+}
+
+func (q *isaQ) fmulq(c *CPU, i uint64) (uint64, error) {
+	return q.arith(c, i, "fmul.q", func(a *softfloat, b *softfloat, _ uint64) (*softfloat, uint64) {
+		return softMul(a, b)
+	})
+}
+
+func (q *isaQ) fdivq(c *CPU, i uint64) (uint64, error) {
+	return q.arith(c, i, "fdiv.q", func(a *softfloat, b *softfloat, _ uint64) (*softfloat, uint64) {
+		return softDiv(FloatFormatQ, a, b)
+	})
+}
+
+func (_ *isaQ) fsqrtq(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsqrt.q", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := softSqrt(FloatFormatQ, c.getQ(rs1))
+	c.setQ(rd, r, flags, rm)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaQ) sgnj(c *CPU, i uint64, name string, f func(a uint64, b uint64) uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	a := c.GetRegisterFloatAsFloat128(rs1)
+	b := c.GetRegisterFloatAsFloat128(rs2)
+	a.Hi = a.Hi&0x7fffffffffffffff | f(a.Hi, b.Hi)&0x8000000000000000
+	c.SetRegisterFloatAsFloat128(rd, a)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fsgnjq(c *CPU, i uint64) (uint64, error) {
+	return q.sgnj(c, i, "fsgnj.q", func(a uint64, b uint64) uint64 { return b })
+}
+
+func (q *isaQ) fsgnjnq(c *CPU, i uint64) (uint64, error) {
+	return q.sgnj(c, i, "fsgnjn.q", func(a uint64, b uint64) uint64 { return ^b })
+}
+
+func (q *isaQ) fsgnjxq(c *CPU, i uint64) (uint64, error) {
+	return q.sgnj(c, i, "fsgnjx.q", func(a uint64, b uint64) uint64 { return a ^ b })
+}
+
+// minmax returns the lesser or the greater operand. -0 is less than +0, a NaN operand is ignored and two NaNs give the
+// canonical NaN.
+func (_ *isaQ) minmax(c *CPU, i uint64, name string, max bool) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	a, b := c.getQ(rs1), c.getQ(rs2)
+	c.SetFloatFlag(nanFlags(a, b), 1)
+	var r Float128
+	switch {
+	case a.nan && b.nan:
+		r = NaN128
+	case a.nan:
+		r = c.GetRegisterFloatAsFloat128(rs2)
+	case b.nan:
+		r = c.GetRegisterFloatAsFloat128(rs1)
+	default:
+		n := softCompare(a, b)
+		if n == 0 && a.zero() {
+			n = 1
+			if a.neg {
+				n = -1
+			}
+		}
+		if n < 0 != max {
+			r = c.GetRegisterFloatAsFloat128(rs1)
+		} else {
+			r = c.GetRegisterFloatAsFloat128(rs2)
+		}
+	}
+	c.SetRegisterFloatAsFloat128(rd, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fminq(c *CPU, i uint64) (uint64, error) {
+	return q.minmax(c, i, "fmin.q", false)
+}
+
+func (q *isaQ) fmaxq(c *CPU, i uint64) (uint64, error) {
+	return q.minmax(c, i, "fmax.q", true)
+}
+
+func (_ *isaQ) cmp(c *CPU, i uint64, name string, quiet bool, f func(n int) bool) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	a, b := c.getQ(rs1), c.getQ(rs2)
+	if a.nan || b.nan {
+		if !quiet {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
+		c.SetFloatFlag(nanFlags(a, b), 1)
+		c.SetRegister(rd, 0)
+	} else if f(softCompare(a, b)) {
+		c.SetRegister(rd, 1)
+	} else {
+		c.SetRegister(rd, 0)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) feqq(c *CPU, i uint64) (uint64, error) {
+	return q.cmp(c, i, "feq.q", true, func(n int) bool { return n == 0 })
+}
+
+func (q *isaQ) fltq(c *CPU, i uint64) (uint64, error) {
+	return q.cmp(c, i, "flt.q", false, func(n int) bool { return n < 0 })
+}
+
+func (q *isaQ) fleq(c *CPU, i uint64) (uint64, error) {
+	return q.cmp(c, i, "fle.q", false, func(n int) bool { return n <= 0 })
+}
+
+func (_ *isaQ) fclassq(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fclass.q", c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	c.SetRegister(rd, FClassQ(c.GetRegisterFloatAsFloat128(rs1)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaQ) cvtx(c *CPU, i uint64, name string, signed bool, n uint) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := softToInt(c.getQ(rs1), signed, n, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fcvtwq(c *CPU, i uint64) (uint64, error) {
+	return q.cvtx(c, i, "fcvt.w.q", true, 32)
+}
+
+func (q *isaQ) fcvtwuq(c *CPU, i uint64) (uint64, error) {
+	return q.cvtx(c, i, "fcvt.wu.q", false, 32)
+}
+
+func (q *isaQ) fcvtlq(c *CPU, i uint64) (uint64, error) {
+	return q.cvtx(c, i, "fcvt.l.q", true, 64)
+}
+
+func (q *isaQ) fcvtluq(c *CPU, i uint64) (uint64, error) {
+	return q.cvtx(c, i, "fcvt.lu.q", false, 64)
+}
+
+func (_ *isaQ) cvtq(c *CPU, i uint64, name string, signed bool, n uint) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogI(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	c.setQ(rd, softFromInt(c.GetRegister(rs1), signed, n), 0, rm)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fcvtqw(c *CPU, i uint64) (uint64, error) {
+	return q.cvtq(c, i, "fcvt.q.w", true, 32)
+}
+
+func (q *isaQ) fcvtqwu(c *CPU, i uint64) (uint64, error) {
+	return q.cvtq(c, i, "fcvt.q.wu", false, 32)
+}
+
+func (q *isaQ) fcvtql(c *CPU, i uint64) (uint64, error) {
+	return q.cvtq(c, i, "fcvt.q.l", true, 64)
+}
+
+func (q *isaQ) fcvtqlu(c *CPU, i uint64) (uint64, error) {
+	return q.cvtq(c, i, "fcvt.q.lu", false, 64)
+}
+
+// widen converts from a narrower format into quad precision, which is always exact.
+func (_ *isaQ) widen(c *CPU, i uint64, name string, f FloatFormat, get func(r uint64) uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	x := f.unpack(get(rs1))
+	c.setQ(rd, x, nanFlags(x), rm)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fcvtqs(c *CPU, i uint64) (uint64, error) {
+	return q.widen(c, i, "fcvt.q.s", FloatFormatS, func(r uint64) uint64 {
+		return uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(r)))
+	})
+}
+
+func (q *isaQ) fcvtqd(c *CPU, i uint64) (uint64, error) {
+	return q.widen(c, i, "fcvt.q.d", FloatFormatD, func(r uint64) uint64 {
+		return math.Float64bits(c.GetRegisterFloatAsFloat64(r))
+	})
+}
+
+func (q *isaQ) fcvtqh(c *CPU, i uint64) (uint64, error) {
+	return q.widen(c, i, "fcvt.q.h", FloatFormatH, func(r uint64) uint64 {
+		return uint64(c.GetRegisterFloatAsFloat16(r))
+	})
+}
+
+// narrow converts from quad precision into a narrower format and NaN-boxes the result.
+func (_ *isaQ) narrow(c *CPU, i uint64, name string, f FloatFormat) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	x := c.getQ(rs1)
+	u, flags := f.round(x, rm)
+	c.SetRegisterFloat(rd, u|^(uint64(1)<<(f.Exp+f.Frac+1)-1))
+	c.SetFloatFlag(flags|nanFlags(x), 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (q *isaQ) fcvtsq(c *CPU, i uint64) (uint64, error) {
+	return q.narrow(c, i, "fcvt.s.q", FloatFormatS)
+}
+
+func (q *isaQ) fcvtdq(c *CPU, i uint64) (uint64, error) {
+	return q.narrow(c, i, "fcvt.d.q", FloatFormatD)
+}
+
+func (q *isaQ) fcvthq(c *CPU, i uint64) (uint64, error) {
+	return q.narrow(c, i, "fcvt.h.q", FloatFormatH)
+}
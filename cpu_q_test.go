@@ -0,0 +1,195 @@
+package rv64
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func quadBig(f Float128) *big.Float {
+	x := FloatFormatQ.unpackBig(f.big())
+	r := new(big.Float).SetPrec(113).SetInt(x.m)
+	r.SetMantExp(r, x.e)
+	if x.neg {
+		r.Neg(r)
+	}
+	return r
+}
+
+func TestQuadRoundingMode(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	mode := map[uint64]big.RoundingMode{
+		FRoundRNE: big.ToNearestEven,
+		FRoundRTZ: big.ToZero,
+		FRoundRDN: big.ToNegativeInf,
+		FRoundRUP: big.ToPositiveInf,
+		FRoundRMM: big.ToNearestAway,
+	}
+	rnd := func() Float128 {
+		return Float128{Hi: uint64(r.Intn(2))<<63 | uint64(16383+r.Intn(400)-200)<<48 | r.Uint64()>>16, Lo: r.Uint64()}
+	}
+	for n := 0; n < 2000; n++ {
+		a, b := rnd(), rnd()
+		x, y := FloatFormatQ.unpackBig(a.big()), FloatFormatQ.unpackBig(b.big())
+		for rm, m := range mode {
+			for _, e := range []struct {
+				name string
+				f    func() (*softfloat, uint64)
+				g    func(z *big.Float, x *big.Float, y *big.Float) *big.Float
+			}{
+				{"add", func() (*softfloat, uint64) { return softAdd(x, y, rm) }, (*big.Float).Add},
+				{"mul", func() (*softfloat, uint64) { return softMul(x, y) }, (*big.Float).Mul},
+				{"div", func() (*softfloat, uint64) { return softDiv(FloatFormatQ, x, y) }, (*big.Float).Quo},
+			} {
+				s, _ := e.f()
+				u, _ := FloatFormatQ.roundBig(s, rm)
+				z := new(big.Float).SetPrec(113).SetMode(m)
+				e.g(z, quadBig(a), quadBig(b))
+				if quadBig(float128(u)).Cmp(z) != 0 {
+					t.Fatalf("%s %v %v rm=%d: %v != %v", e.name, quadBig(a), quadBig(b), rm, quadBig(float128(u)), z)
+				}
+			}
+		}
+	}
+}
+
+func TestQuad(t *testing.T) {
+	const opfp = 0b1010011
+	r := func(funct5, rm, rs2 uint64) uint64 { return encodeR(opfp, rm, funct5<<2|0b11, Ra0, Ra1, rs2) }
+	one := Float128{Hi: 0x3fff000000000000}
+	two := Float128{Hi: 0x4000000000000000}
+	c := newVectorCPU()
+	for _, e := range []struct {
+		name  string
+		i     uint64
+		a     Float128
+		b     Float128
+		r     Float128
+		flags uint64
+	}{
+		{"fadd.q", r(0b00000, FRoundRNE, Ra2), one, two, Float128{Hi: 0x4000800000000000}, 0},
+		{"fsub.q", r(0b00001, FRoundRNE, Ra2), one, one, Float128{}, 0},
+		{"fsub.q rdn", r(0b00001, FRoundRDN, Ra2), one, one, Float128{Hi: 0x8000000000000000}, 0},
+		{"fdiv.q", r(0b00011, FRoundRNE, Ra2), one, Float128{Hi: 0x4000800000000000}, Float128{Hi: 0x3ffd555555555555, Lo: 0x5555555555555555}, FFlagsNX},
+		{"fdiv.q rup", r(0b00011, FRoundRUP, Ra2), one, Float128{Hi: 0x4000800000000000}, Float128{Hi: 0x3ffd555555555555, Lo: 0x5555555555555556}, FFlagsNX},
+		{"fdiv.q 1/0", r(0b00011, FRoundRNE, Ra2), one, Float128{}, Float128{Hi: 0x7fff000000000000}, FFlagsDZ},
+		{"fsqrt.q", r(0b01011, FRoundRNE, 0), two, Float128{}, Float128{Hi: 0x3fff6a09e667f3bc, Lo: 0xc908b2fb1366ea95}, FFlagsNX},
+		{"fsqrt.q -1", r(0b01011, FRoundRNE, 0), Float128{Hi: 0xbfff000000000000}, Float128{}, NaN128, FFlagsNV},
+		{"fsgnjn.q", r(0b00100, 1, Ra2), one, one, Float128{Hi: 0xbfff000000000000}, 0},
+		{"fmin.q", r(0b00101, 0, Ra2), Float128{}, Float128{Hi: 0x8000000000000000}, Float128{Hi: 0x8000000000000000}, 0},
+		{"fmax.q", r(0b00101, 1, Ra2), NaN128, two, two, 0},
+	} {
+		c.SetRegisterFloatAsFloat128(Ra1, e.a)
+		c.SetRegisterFloatAsFloat128(Ra2, e.b)
+		c.ClrFloatFlag()
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if v := c.GetRegisterFloatAsFloat128(Ra0); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+		if f := c.GetCSR().Get(CSRfflags); f != e.flags {
+			t.Fatalf("%s: flags %#x != %#x", e.name, f, e.flags)
+		}
+	}
+	// fmadd.q a0, a1, a2, a3 computes 2 × 2 - 1 with a single rounding.
+	c.SetRegisterFloatAsFloat128(Ra1, two)
+	c.SetRegisterFloatAsFloat128(Ra2, two)
+	c.SetRegisterFloatAsFloat128(Ra3, Float128{Hi: 0xbfff000000000000})
+	if err := execute(c, Ra3<<27|0b11<<25|Ra2<<20|Ra1<<15|Ra0<<7|0b1000011); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat128(Ra0) != (Float128{Hi: 0x4000800000000000}) {
+		t.FailNow()
+	}
+	// Integer results.
+	for _, e := range []struct {
+		name  string
+		i     uint64
+		a     Float128
+		b     Float128
+		r     uint64
+		flags uint64
+	}{
+		{"fcvt.w.q", r(0b11000, FRoundRTZ, 0b00000), Float128{Hi: 0xc000400000000000}, Float128{}, 0xfffffffffffffffe, FFlagsNX},
+		{"fcvt.w.q rdn", r(0b11000, FRoundRDN, 0b00000), Float128{Hi: 0xc000400000000000}, Float128{}, 0xfffffffffffffffd, FFlagsNX},
+		{"fcvt.l.q", r(0b11000, FRoundRNE, 0b00010), Float128{Hi: 0x4045000000000000}, Float128{}, 0x7fffffffffffffff, FFlagsNV},
+		{"fcvt.lu.q", r(0b11000, FRoundRNE, 0b00011), Float128{Hi: 0x403e000000000000}, Float128{}, 0x8000000000000000, 0},
+		{"feq.q", r(0b10100, 2, Ra2), one, one, 1, 0},
+		{"flt.q", r(0b10100, 1, Ra2), NaN128, one, 0, FFlagsNV},
+		{"fle.q", r(0b10100, 0, Ra2), one, two, 1, 0},
+		{"fclass.q", r(0b11100, 1, 0), Float128{Lo: 1}, Float128{}, 0b00_00100000, 0},
+		{"fclass.q", r(0b11100, 1, 0), Float128{Hi: 0x7fff000000000000, Lo: 1}, Float128{}, 0b01_00000000, 0},
+	} {
+		c.SetRegisterFloatAsFloat128(Ra1, e.a)
+		c.SetRegisterFloatAsFloat128(Ra2, e.b)
+		c.ClrFloatFlag()
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if v := c.GetRegister(Ra0); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+		if f := c.GetCSR().Get(CSRfflags); f != e.flags {
+			t.Fatalf("%s: flags %#x != %#x", e.name, f, e.flags)
+		}
+	}
+	// fcvt.q.l
+	c.SetRegister(Ra1, 0xffffffffffffffff)
+	if err := execute(c, r(0b11010, FRoundRNE, 0b00010)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat128(Ra0) != (Float128{Hi: 0xbfff000000000000}) {
+		t.FailNow()
+	}
+	// fcvt.q.d and fcvt.d.q round trip.
+	c.SetRegisterFloatAsFloat64(Ra1, 0.1)
+	if err := execute(c, r(0b01000, FRoundRNE, 0b00001)); err != nil {
+		t.Fatal(err)
+	}
+	c.ClrFloatFlag()
+	if err := execute(c, encodeR(opfp, FRoundRNE, 0b0100001, Ra1, Ra0, 0b00011)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat64(Ra1) != 0.1 || c.GetCSR().Get(CSRfflags) != 0 {
+		t.FailNow()
+	}
+	// fcvt.s.q NaN-boxes the single into the 128-bit register.
+	c.SetRegisterFloatAsFloat128(Ra1, Float128{Hi: 0x3ffd555555555555, Lo: 0x5555555555555555})
+	if err := execute(c, encodeR(opfp, FRoundRNE, 0b0100000, Ra0, Ra1, 0b00011)); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.GetRegisterFloatAsFloat128(Ra0); v != (Float128{Hi: 0xffffffffffffffff, Lo: 0xffffffff3eaaaaab}) {
+		t.Fatalf("%#x", v)
+	}
+	// A quad is not a valid NaN-boxed double.
+	c.SetRegisterFloatAsFloat128(Ra1, two)
+	c.SetRegisterFloatAsFloat64(Ra2, 1)
+	if err := execute(c, encodeR(opfp, FRoundRNE, 0b0000001, Ra0, Ra1, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloat(Ra0) != NaN64 {
+		t.FailNow()
+	}
+	// flq and fsq.
+	c.SetRegister(Ra1, 0x100)
+	c.SetRegisterFloatAsFloat128(Ra0, Float128{Hi: 0x0123456789abcdef, Lo: 0xfedcba9876543210})
+	if err := execute(c, Ra0<<20|Ra1<<15|0b100<<12|0b10000<<7|0b0100111); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute(c, encodeI(0b0000111, 0b100, 16, Ra2, Ra1)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat128(Ra2) != (Float128{Hi: 0x0123456789abcdef, Lo: 0xfedcba9876543210}) {
+		t.FailNow()
+	}
+	// Without Q, FLEN is 64 and the upper bits are ignored.
+	c.SetISA(c.GetISA() &^ ISAQ)
+	if math.Float64bits(c.GetRegisterFloatAsFloat64(Ra2)) != 0xfedcba9876543210 {
+		t.FailNow()
+	}
+	if err := execute(c, r(0b00000, FRoundRNE, Ra2)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
@@ -4,38 +4,90 @@ import (
 	"log"
 )
 
-func (c *CPU) Run() uint8 {
-	for {
-		if c.GetStatus() == 1 {
-			Debugln("Exit:", c.GetSystem().Code())
-			return c.GetSystem().Code()
+// Step fetches and executes a single instruction, taking a pending interrupt first, and advances the counters. It
+// returns the number of cycles spent. When the instruction can not be paid for within the cycle limit, it is not
+// executed, the status of the CPU becomes StatusOutOfGas and no cycle is spent.
+func (c *CPU) Step() uint64 {
+	c.Interrupt()
+	data, err := c.PipelineInstructionFetch()
+	if err != nil {
+		Panicln(err)
+	}
+	if c.caches != nil {
+		a := c.GetPC()
+		if c.isPaging() {
+			a, _ = c.Translate(a, AccessFetch)
+		}
+		if fastenCacheable(c.fasten, a) {
+			c.caches.observe(c.caches.I, c.GetPC(), a, uint64(len(data)), false)
+		}
+	}
+	// Without a cost model the price is only known once the instruction executed, the handlers report one cycle.
+	var p uint64 = 1
+	if c.cost != nil {
+		var i uint64 = 0
+		for j := len(data) - 1; j >= 0; j-- {
+			i += uint64(data[j]) << (8 * j)
 		}
-		data, err := c.PipelineInstructionFetch()
-		if err != nil {
-			Panicln(err)
+		p = c.cost.Cost(c, i, uint64(len(data)))
+	}
+	if c.limit != 0 {
+		cycle := c.GetCSR().Get(CSRcycle)
+		if cycle > c.limit || p > c.limit-cycle {
+			c.SetStatus(StatusOutOfGas)
+			return 0
 		}
+	}
 
-		// Debugln("----------------------------------------")
-		// var s uint64 = 0
-		// for i := 0; i < 32; i++ {
-		// 	s += c.GetRegister(uint64(i))
-		// }
-		// Debugln(fmt.Sprintf("nums=%d, pc=%d, sum=%d", c.GetCSR().Get(CSRinstret), c.GetPC(), s))
-		// if len(data) == 2 {
-		// 	Debugln(fmt.Sprintf("%08b %08b", data[1], data[0]))
-		// } else if len(data) == 4 {
-		// 	Debugln(fmt.Sprintf("%08b %08b %08b %08b", data[3], data[2], data[1], data[0]))
-		// } else {
-		// 	Panicln("")
-		// }
+	// Debugln("----------------------------------------")
+	// var s uint64 = 0
+	// for i := 0; i < 32; i++ {
+	// 	s += c.GetRegister(uint64(i))
+	// }
+	// Debugln(fmt.Sprintf("nums=%d, pc=%d, sum=%d", c.GetCSR().Get(CSRinstret), c.GetPC(), s))
+	// if len(data) == 2 {
+	// 	Debugln(fmt.Sprintf("%08b %08b", data[1], data[0]))
+	// } else if len(data) == 4 {
+	// 	Debugln(fmt.Sprintf("%08b %08b %08b %08b", data[3], data[2], data[1], data[0]))
+	// } else {
+	// 	Panicln("")
+	// }
 
-		n, err := c.PipelineExecute(data)
-		if err != nil {
-			log.Panicln(err)
-		}
+	n, err := c.PipelineExecute(data)
+	if err != nil {
+		log.Panicln(err)
+	}
+	if c.cost != nil {
+		n = p
+	}
+	if c.caches != nil {
+		n += c.caches.take()
+	}
 
-		c.GetCSR().Set(CSRcycle, c.GetCSR().Get(CSRcycle)+n)
-		c.GetCSR().Set(CSRtime, c.GetCSR().Get(CSRtime)+n)
-		c.GetCSR().Set(CSRinstret, c.GetCSR().Get(CSRinstret)+1)
+	c.GetCSR().Set(CSRcycle, c.GetCSR().Get(CSRcycle)+n)
+	c.GetCSR().Set(CSRtime, c.GetCSR().Get(CSRtime)+n)
+	c.GetCSR().Set(CSRinstret, c.GetCSR().Get(CSRinstret)+1)
+	return n
+}
+
+// Run executes instructions until the program exits or runs out of gas, and returns the exit code of the System. A
+// program running out of gas returns ErrOutOfGas, its exit code is meaningless.
+func (c *CPU) Run() (uint8, error) {
+	for {
+		if c.GetStatus() == StatusOutOfGas {
+			Debugln("Out of gas")
+			return 0, ErrOutOfGas
+		}
+		if c.GetStatus() == StatusExit {
+			Debugln("Exit:", c.GetSystem().Code())
+			return c.GetSystem().Code(), nil
+		}
+		n := c.Step()
+		if t, ok := c.fasten.(Ticker); ok {
+			t.Tick(n)
+		}
+		if t, ok := c.GetSystem().(Ticker); ok {
+			t.Tick(n)
+		}
 	}
 }
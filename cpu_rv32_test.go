@@ -0,0 +1,177 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func newRV32CPU() *CPU {
+	c := newVectorCPU()
+	c.SetXLEN(32)
+	return c
+}
+
+func TestRV32Register(t *testing.T) {
+	const op = 0b0110011
+	c := newRV32CPU()
+	for _, e := range []struct {
+		name string
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		{"add", encodeR(op, 0b000, 0b0000000, Ra0, Ra1, Ra2), 0x7fffffff, 1, 0xffffffff80000000},
+		{"sub", encodeR(op, 0b000, 0b0100000, Ra0, Ra1, Ra2), 0x80000000, 1, 0x7fffffff},
+		{"sll", encodeR(op, 0b001, 0b0000000, Ra0, Ra1, Ra2), 3, 33, 6},
+		{"srl", encodeR(op, 0b101, 0b0000000, Ra0, Ra1, Ra2), 0x80000000, 31, 1},
+		{"sra", encodeR(op, 0b101, 0b0100000, Ra0, Ra1, Ra2), 0x80000000, 31, 0xffffffffffffffff},
+		{"sltu", encodeR(op, 0b011, 0b0000000, Ra0, Ra1, Ra2), 1, 0x80000000, 1},
+		{"mul", encodeR(op, 0b000, 0b0000001, Ra0, Ra1, Ra2), 0x10000, 0x10000, 0},
+		{"mulh", encodeR(op, 0b001, 0b0000001, Ra0, Ra1, Ra2), 0xffffffff, 0xffffffff, 0},
+		{"mulhsu", encodeR(op, 0b010, 0b0000001, Ra0, Ra1, Ra2), 0xffffffff, 0xffffffff, 0xffffffffffffffff},
+		{"mulhu", encodeR(op, 0b011, 0b0000001, Ra0, Ra1, Ra2), 0xffffffff, 0xffffffff, 0xfffffffffffffffe},
+		{"div", encodeR(op, 0b100, 0b0000001, Ra0, Ra1, Ra2), 0x80000000, 0xffffffff, 0xffffffff80000000},
+		{"divu", encodeR(op, 0b101, 0b0000001, Ra0, Ra1, Ra2), 0xffffffff, 2, 0x7fffffff},
+		{"rem", encodeR(op, 0b110, 0b0000001, Ra0, Ra1, Ra2), 0x80000000, 0xffffffff, 0},
+		{"remu", encodeR(op, 0b111, 0b0000001, Ra0, Ra1, Ra2), 0xffffffff, 0x10, 0xf},
+		{"srli", encodeI(0b0010011, 0b101, 4, Ra0, Ra1), 0xfffffff0, 0, 0x0fffffff},
+		{"srai", encodeI(0b0010011, 0b101, 0x400|4, Ra0, Ra1), 0x80000000, 0, 0xfffffffff8000000},
+	} {
+		c.SetRegister(Ra1, e.a)
+		c.SetRegister(Ra2, e.b)
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if v := c.GetRegister(Ra0); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+	}
+	if c.GetRegisterUnsigned(Ra1) != 0x80000000 {
+		t.FailNow()
+	}
+}
+
+func TestRV32Illegal(t *testing.T) {
+	c := newRV32CPU()
+	for _, i := range []uint64{
+		encodeI(0b0000011, 0b011, 0, Ra0, Ra1),              // ld
+		encodeI(0b0000011, 0b110, 0, Ra0, Ra1),              // lwu
+		encodeI(0b0011011, 0b000, 1, Ra0, Ra1),              // addiw
+		encodeR(0b0111011, 0b000, 0b0000000, Ra0, Ra1, Ra2), // addw
+		encodeI(0b0010011, 0b001, 32, Ra0, Ra1),             // slli
+		encodeR(0b0101111, 0b011, 0b0000000, Ra0, Ra1, Ra2), // amoadd.d
+		encodeR(0b1010011, 0b000, 0b1100000, Ra0, Ra1, 2),   // fcvt.l.s
+		encodeR(0b1010011, 0b000, 0b1110001, Ra0, Ra1, 0),   // fmv.x.d
+		encodeR(0b0111011, 0b000, 0b0000100, Ra0, Ra1, Ra2), // add.uw
+		encodeR(0b0011011, 0b001, 0b0110000, Ra0, Ra1, 0),   // clzw
+		encodeI(0b0010011, 0b001, 0b001010<<6|32, Ra0, Ra1), // bseti
+	} {
+		if err := execute(c, i); err != ErrAbnormalInstruction {
+			t.Fatalf("%#08x", i)
+		}
+	}
+	// c.srli with shamt[5] set and c.addw.
+	for _, i := range []uint16{0x9005, 0x9c25} {
+		if _, err := c.PipelineExecute([]byte{byte(i), byte(i >> 8)}); err != ErrReservedInstruction {
+			t.Fatalf("%#04x", i)
+		}
+	}
+}
+
+func TestRV32BitManipulation(t *testing.T) {
+	const (
+		op  = 0b0110011
+		opi = 0b0010011
+	)
+	c := newRV32CPU()
+	for _, e := range []struct {
+		name string
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		{"andn", encodeR(op, 0b111, 0b0100000, Ra0, Ra1, Ra2), 0xff00ff00, 0xf0f0f0f0, 0x0f000f00},
+		{"sh1add", encodeR(op, 0b010, 0b0010000, Ra0, Ra1, Ra2), 0x80000001, 0x10, 0x12},
+		{"bseti", encodeI(opi, 0b001, 0b001010<<6|31, Ra0, Ra1), 0, 0, 0xffffffff80000000},
+		{"bset", encodeR(op, 0b001, 0b0010100, Ra0, Ra1, Ra2), 0, 33, 2},
+		{"bext", encodeR(op, 0b101, 0b0100100, Ra0, Ra1, Ra2), 0x80000000, 63, 1},
+		{"clz", encodeR(opi, 0b001, 0b0110000, Ra0, Ra1, 0b00000), 0x10000, 0, 15},
+		{"ctz", encodeR(opi, 0b001, 0b0110000, Ra0, Ra1, 0b00001), 0, 0, 32},
+		{"cpop", encodeR(opi, 0b001, 0b0110000, Ra0, Ra1, 0b00010), 0x80000001, 0, 2},
+		{"zext.h", encodeR(op, 0b100, 0b0000100, Ra0, Ra1, 0), 0xffff8000, 0, 0x8000},
+	} {
+		c.SetRegister(Ra1, e.a)
+		c.SetRegister(Ra2, e.b)
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if v := c.GetRegister(Ra0); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+	}
+}
+
+func TestRV32Address(t *testing.T) {
+	c := newRV32CPU()
+	// jalr ra, 0x20(a1) wraps around the 32-bit address space.
+	c.SetPC(0xfffffff8)
+	c.SetRegister(Ra1, 0xfffffff0)
+	if err := execute(c, encodeI(0b1100111, 0b000, 0x20, Rra, Ra1)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetPC() != 0x10 || c.GetRegister(Rra) != 0xfffffffffffffffc {
+		t.FailNow()
+	}
+	// lw a0, 0x20(a1) reads address 0x10.
+	c.GetMemory().SetUint32(0x10, 0x80000000)
+	if err := execute(c, encodeI(0b0000011, 0b010, 0x20, Ra0, Ra1)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegister(Ra0) != 0xffffffff80000000 {
+		t.FailNow()
+	}
+}
+
+func TestRV32Compressed(t *testing.T) {
+	c := newRV32CPU()
+	// c.jal 4
+	c.SetPC(0x100)
+	if _, err := c.PipelineExecute([]byte{0x11, 0x20}); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetPC() != 0x104 || c.GetRegister(Rra) != 0x102 {
+		t.FailNow()
+	}
+	// c.flw fs0, 4(s1)
+	c.SetRegister(Rs1, 0x200)
+	c.GetMemory().SetUint32(0x204, 0x3f800000)
+	if _, err := c.PipelineExecute([]byte{0xc0, 0x60}); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat32(Rfs0) != 1 {
+		t.FailNow()
+	}
+	// c.fswsp fs0, 8(sp)
+	c.SetRegister(Rsp, 0x300)
+	if _, err := c.PipelineExecute([]byte{0x22, 0xe4}); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint32(0x308); v != 0x3f800000 {
+		t.FailNow()
+	}
+}
+
+func TestRV32Counter(t *testing.T) {
+	c := newRV32CPU()
+	c.GetCSR().Set(CSRcycle, 0x1_8000_0002)
+	if err := execute(c, encodeI(0b1110011, 0b010, CSRcycle, Ra0, Rzero)); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute(c, encodeI(0b1110011, 0b010, CSRcycleh, Ra1, Rzero)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegister(Ra0) != 0xffffffff80000002 || c.GetRegister(Ra1) != 1 {
+		t.FailNow()
+	}
+}
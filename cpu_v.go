@@ -0,0 +1,1850 @@
+package rv64
+
+// https://github.com/riscv/riscv-v-spec/releases/download/v1.0/riscv-v-spec-1.0.pdf
+//
+// The vector extension adds 32 vector registers of VLEN bits, VLEN is configured per CPU with SetVLEN and ELEN is 64.
+// Tail and masked-off elements are always left undisturbed, which is a legal implementation of both the agnostic and
+// the undisturbed policies. Floating-point vector instructions support SEW=32 and SEW=64. They round with frm, which
+// must hold a valid rounding mode, and raise the exception flags through the software implementation the scalar
+// instructions use. The estimate instructions vfrsqrt7.v and vfrec7.v read the 7-bit tables of the specification.
+//
+// |31   29|28 |27 26|25|24      20|19      15|14  12|11       7|6            0|
+// | nf    |mew| mop |vm| lumop/rs2| rs1      | width| vd       | 0000111      | Vector load
+// | nf    |mew| mop |vm| sumop/rs2| rs1      | width| vs3      | 0100111      | Vector store
+// |31      26|25|24      20|19      15|14  12|11       7|6            0|
+// | funct6   |vm| vs2      | vs1/rs1  | funct3| vd/rd    | 1010111      | OP-V
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// vfile is a view on the vector register file. Register groups are contiguous, element i of the group starting at
+// register r is simply the i-th element after the start of r.
+type vfile struct {
+	b     []byte
+	vlenb uint64
+}
+
+func (v vfile) get(r uint64, eew uint64, i uint64) uint64 {
+	o := r*v.vlenb + i*eew/8
+	switch eew {
+	case 8:
+		return uint64(v.b[o])
+	case 16:
+		return uint64(binary.LittleEndian.Uint16(v.b[o:]))
+	case 32:
+		return uint64(binary.LittleEndian.Uint32(v.b[o:]))
+	case 64:
+		return binary.LittleEndian.Uint64(v.b[o:])
+	}
+	Panicln("unreachable")
+	return 0
+}
+
+func (v vfile) set(r uint64, eew uint64, i uint64, u uint64) {
+	o := r*v.vlenb + i*eew/8
+	switch eew {
+	case 8:
+		v.b[o] = uint8(u)
+	case 16:
+		binary.LittleEndian.PutUint16(v.b[o:], uint16(u))
+	case 32:
+		binary.LittleEndian.PutUint32(v.b[o:], uint32(u))
+	case 64:
+		binary.LittleEndian.PutUint64(v.b[o:], u)
+	default:
+		Panicln("unreachable")
+	}
+}
+
+func (v vfile) mask(r uint64, i uint64) bool {
+	return v.b[r*v.vlenb+i/8]>>(i%8)&1 != 0
+}
+
+func (v vfile) setMask(r uint64, i uint64, b bool) {
+	o := r*v.vlenb + i/8
+	if b {
+		v.b[o] |= 1 << (i % 8)
+	} else {
+		v.b[o] &^= 1 << (i % 8)
+	}
+}
+
+func (c *CPU) vfile() vfile {
+	return vfile{b: c.vreg, vlenb: c.vlen / 8}
+}
+
+// vlmul8 returns LMUL×8 for the vlmul field of vtype, or 0 for the reserved encoding.
+func vlmul8(vlmul uint64) uint64 {
+	if vlmul < 4 {
+		return 8 << vlmul
+	}
+	return 8 >> (8 - vlmul)
+}
+
+// vgroup checks that the register group of EMUL registers starting at r is legal. EMUL is given as EMUL×8, fractional
+// EMUL occupies a single register.
+func vgroup(r uint64, emul8 uint64) error {
+	if emul8 == 0 || emul8 > 64 {
+		return ErrAbnormalInstruction
+	}
+	n := emul8 / 8
+	if n == 0 {
+		n = 1
+	}
+	if r%n != 0 {
+		return ErrAbnormalInstruction
+	}
+	return nil
+}
+
+// vtype returns SEW and LMUL×8 of the current vtype. Instructions depending on vtype are illegal when vill is set.
+func (c *CPU) vtype() (uint64, uint64, error) {
+	t := c.GetCSR().Get(CSRvtype)
+	if t>>63 != 0 {
+		return 0, 0, ErrAbnormalInstruction
+	}
+	return 8 << InstructionPart(t, 3, 5), vlmul8(InstructionPart(t, 0, 2)), nil
+}
+
+// vsigned sign-extends a sew-bit value.
+func vsigned(v uint64, sew uint64) int64 {
+	return int64(SignExtend(v, sew-1))
+}
+
+// vones returns a value with the low sew bits set.
+func vones(sew uint64) uint64 {
+	return math.MaxUint64 >> (64 - sew)
+}
+
+type isaV struct{}
+
+func (_ *isaV) setvl(c *CPU, rd uint64, avl uint64, vtype uint64) {
+	vsew := InstructionPart(vtype, 3, 5)
+	sew := uint64(8) << vsew
+	lmul8 := vlmul8(InstructionPart(vtype, 0, 2))
+	vlmax := c.GetVLEN() * lmul8 / 8 / sew
+	// Fractional LMUL is only supported when SEW ≤ LMUL×ELEN.
+	if vsew > 3 || lmul8 == 0 || sew*8 > lmul8*64 || vlmax == 0 || InstructionPart(vtype, 8, 63) != 0 {
+		c.GetCSR().Set(CSRvtype, 1<<63)
+		c.GetCSR().Set(CSRvl, 0)
+		c.GetCSR().Set(CSRvstart, 0)
+		c.SetRegister(rd, 0)
+		return
+	}
+	vl := avl
+	if vl > vlmax {
+		vl = vlmax
+	}
+	c.GetCSR().Set(CSRvtype, vtype)
+	c.GetCSR().Set(CSRvl, vl)
+	c.GetCSR().Set(CSRvstart, 0)
+	c.SetRegister(rd, vl)
+}
+
+func (v *isaV) vsetvli(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, _ := IType(i)
+	vtype := InstructionPart(i, 20, 30)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "vsetvli", c.LogI(rd), c.LogI(rs1), vtype))
+	avl := c.GetRegister(rs1)
+	if rs1 == Rzero {
+		if rd != Rzero {
+			avl = math.MaxUint64
+		} else {
+			avl = c.GetCSR().Get(CSRvl)
+		}
+	}
+	v.setvl(c, rd, avl, vtype)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (v *isaV) vsetivli(c *CPU, i uint64) (uint64, error) {
+	rd, uimm, _ := IType(i)
+	vtype := InstructionPart(i, 20, 29)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s imm: ----(%#016x) imm: ----(%#016x)", c.GetPC(), "vsetivli", c.LogI(rd), uimm, vtype))
+	v.setvl(c, rd, uimm, vtype)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (v *isaV) vsetvl(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "vsetvl", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	avl := c.GetRegister(rs1)
+	if rs1 == Rzero {
+		if rd != Rzero {
+			avl = math.MaxUint64
+		} else {
+			avl = c.GetCSR().Get(CSRvl)
+		}
+	}
+	v.setvl(c, rd, avl, c.GetRegister(rs2))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// veew returns the element width encoded in the width field of a vector load or store.
+func veew(i uint64) (uint64, error) {
+	if InstructionPart(i, 28, 28) != 0 {
+		return 0, ErrAbnormalInstruction
+	}
+	switch InstructionPart(i, 12, 14) {
+	case 0b000:
+		return 8, nil
+	case 0b101:
+		return 16, nil
+	case 0b110:
+		return 32, nil
+	case 0b111:
+		return 64, nil
+	}
+	return 0, ErrAbnormalInstruction
+}
+
+// transfer moves evl elements of eew bits between memory and the register group vd of EMUL×8 emul8. Segment field f of
+// element j is at addr(j, f) and in register group vd+f×EMUL. With ff set, a fault on any element but the first trims
+// vl instead of trapping. A fault sets vstart to the faulting element.
+func (_ *isaV) transfer(c *CPU, i uint64, store bool, eew uint64, emul8 uint64, evl uint64, addr func(j uint64, f uint64) uint64, ff bool) error {
+	vd := InstructionPart(i, 7, 11)
+	vm := InstructionPart(i, 25, 25)
+	nf := InstructionPart(i, 29, 31) + 1
+	if err := vgroup(vd, emul8); err != nil {
+		return err
+	}
+	n := emul8 / 8
+	if n == 0 {
+		n = 1
+	}
+	if nf*n > 8 || vd+nf*n > 32 {
+		return ErrAbnormalInstruction
+	}
+	if !store && vm == 0 && vd == 0 {
+		return ErrAbnormalInstruction
+	}
+	reg := c.vfile()
+	mem := c.GetMemory()
+	for j := c.GetCSR().Get(CSRvstart); j < evl; j++ {
+		if vm == 0 && !reg.mask(0, j) {
+			continue
+		}
+		for f := uint64(0); f < nf; f++ {
+			a := addr(j, f)
+			if store {
+				if err := fastenSet(mem.Fasten, a, eew/8, reg.get(vd+f*n, eew, j)); err != nil {
+					c.GetCSR().Set(CSRvstart, j)
+					return err
+				}
+				continue
+			}
+			u, err := fastenGet(mem.Fasten, a, eew/8)
+			if err != nil {
+				if ff && j != 0 {
+					c.GetCSR().Set(CSRvl, j)
+					c.GetCSR().Set(CSRvstart, 0)
+					return nil
+				}
+				c.GetCSR().Set(CSRvstart, j)
+				return err
+			}
+			reg.set(vd+f*n, eew, j, u)
+		}
+	}
+	c.GetCSR().Set(CSRvstart, 0)
+	return nil
+}
+
+// unit performs the unit-stride, strided, mask and whole register loads and stores.
+func (v *isaV) unit(c *CPU, i uint64, store bool, name string) (uint64, error) {
+	vd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  vd: %#02x rs1: %s rs2: %s", c.GetPC(), name, vd, c.LogI(rs1), c.LogI(rs2)))
+	eew, err := veew(i)
+	if err != nil {
+		return 0, err
+	}
+	base := c.GetRegister(rs1)
+	nf := InstructionPart(i, 29, 31) + 1
+	mop := InstructionPart(i, 26, 27)
+	if mop == 0b00 && (rs2 == 0b01000 || rs2 == 0b01011) {
+		if InstructionPart(i, 25, 25) == 0 {
+			return 0, ErrAbnormalInstruction
+		}
+		var emul8, evl uint64
+		if rs2 == 0b01000 {
+			// Whole register, the number of registers is encoded in nf.
+			if nf != 1 && nf != 2 && nf != 4 && nf != 8 {
+				return 0, ErrAbnormalInstruction
+			}
+			emul8 = nf * 8
+			evl = nf * c.GetVLEN() / eew
+		} else {
+			// Mask, vl bits are transferred as bytes.
+			if eew != 8 || nf != 1 {
+				return 0, ErrAbnormalInstruction
+			}
+			if _, _, err := c.vtype(); err != nil {
+				return 0, err
+			}
+			emul8 = 8
+			evl = (c.GetCSR().Get(CSRvl) + 7) / 8
+		}
+		// The register count of a whole register transfer must not be taken as a segment count.
+		i = i &^ (0b111 << 29)
+		if err := v.transfer(c, i, store, eew, emul8, evl, func(j uint64, _ uint64) uint64 { return base + j*eew/8 }, false); err != nil {
+			return 0, err
+		}
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	}
+	sew, lmul8, err := c.vtype()
+	if err != nil {
+		return 0, err
+	}
+	emul8 := lmul8 * eew / sew
+	if lmul8*eew%sew != 0 {
+		return 0, ErrAbnormalInstruction
+	}
+	var addr func(j uint64, f uint64) uint64
+	switch mop {
+	case 0b00:
+		addr = func(j uint64, f uint64) uint64 { return base + (j*nf+f)*eew/8 }
+	case 0b10:
+		stride := c.GetRegister(rs2)
+		addr = func(j uint64, f uint64) uint64 { return base + j*stride + f*eew/8 }
+	}
+	ff := mop == 0b00 && rs2 == 0b10000
+	if err := v.transfer(c, i, store, eew, emul8, c.GetCSR().Get(CSRvl), addr, ff); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// index performs the indexed loads and stores. The width field encodes the EEW of the indices, data elements are SEW
+// bits wide.
+func (v *isaV) index(c *CPU, i uint64, store bool, name string) (uint64, error) {
+	vd, rs1, vs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  vd: %#02x rs1: %s vs2: %#02x", c.GetPC(), name, vd, c.LogI(rs1), vs2))
+	ieew, err := veew(i)
+	if err != nil {
+		return 0, err
+	}
+	sew, lmul8, err := c.vtype()
+	if err != nil {
+		return 0, err
+	}
+	if lmul8*ieew%sew != 0 {
+		return 0, ErrAbnormalInstruction
+	}
+	if err := vgroup(vs2, lmul8*ieew/sew); err != nil {
+		return 0, err
+	}
+	base := c.GetRegister(rs1)
+	reg := vfile{b: append([]byte{}, c.vreg...), vlenb: c.vlen / 8}
+	addr := func(j uint64, f uint64) uint64 { return base + reg.get(vs2, ieew, j) + f*sew/8 }
+	if err := v.transfer(c, i, store, sew, lmul8, c.GetCSR().Get(CSRvl), addr, false); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (v *isaV) vle(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, false, "vle") }
+func (v *isaV) vleff(c *CPU, i uint64) (uint64, error) { return v.unit(c, i, false, "vleff") }
+func (v *isaV) vlm(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, false, "vlm") }
+func (v *isaV) vlr(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, false, "vlr") }
+func (v *isaV) vlse(c *CPU, i uint64) (uint64, error)  { return v.unit(c, i, false, "vlse") }
+func (v *isaV) vlx(c *CPU, i uint64) (uint64, error)   { return v.index(c, i, false, "vlx") }
+func (v *isaV) vse(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, true, "vse") }
+func (v *isaV) vsm(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, true, "vsm") }
+func (v *isaV) vsr(c *CPU, i uint64) (uint64, error)   { return v.unit(c, i, true, "vsr") }
+func (v *isaV) vsse(c *CPU, i uint64) (uint64, error)  { return v.unit(c, i, true, "vsse") }
+func (v *isaV) vsx(c *CPU, i uint64) (uint64, error)   { return v.index(c, i, true, "vsx") }
+
+// Operand forms of OP-V, encoded in funct3.
+const (
+	vOPIVV = 0b000
+	vOPFVV = 0b001
+	vOPMVV = 0b010
+	vOPIVI = 0b011
+	vOPIVX = 0b100
+	vOPFVF = 0b101
+	vOPMVX = 0b110
+	vOPCFG = 0b111
+)
+
+const (
+	vIVV = 1 << vOPIVV
+	vFVV = 1 << vOPFVV
+	vMVV = 1 << vOPMVV
+	vIVI = 1 << vOPIVI
+	vIVX = 1 << vOPIVX
+	vFVF = 1 << vOPFVF
+	vMVX = 1 << vOPMVX
+)
+
+// How the elements of an arithmetic instruction are combined. For every active element j, a is element j of vs2,
+// b is element j of vs1 or the scalar operand and d is element j of vd.
+const (
+	vkindSingle      = iota // vd[SEW] = f(vs2[SEW], b[SEW], vd[SEW])
+	vkindMask               // vd.mask = f(vs2[SEW], b[SEW]) != 0
+	vkindWide               // vd[2SEW] = f(vs2[SEW], b[SEW], vd[2SEW])
+	vkindWideW              // vd[2SEW] = f(vs2[2SEW], b[SEW], vd[2SEW])
+	vkindNarrow             // vd[SEW] = f(vs2[2SEW], b[SEW])
+	vkindReduce             // vd[0] = f(vs2[j], acc), the accumulator starts with vs1[0]
+	vkindReduceWide         // vd[0] = f(vs2[j], acc), with a 2SEW accumulator
+	vkindCarry              // vd[SEW] = f(vs2[SEW], b[SEW], v0.mask), every element is active
+	vkindCarryMask          // vd.mask = f(vs2[SEW], b[SEW], v0.mask if masked) != 0, every element is active
+	vkindMaskLogical        // vd.mask = f(vs2.mask, vs1.mask)
+	vkindSpecial            // x executes the instruction
+)
+
+type vop struct {
+	name  string
+	forms uint64
+	kind  int
+	float bool // Floating-point operands, SEW must be 32 or 64.
+	uimm  bool // The immediate of the .vi form is unsigned.
+	f     func(c *CPU, a uint64, b uint64, d uint64, sew uint64) uint64
+	x     func(c *CPU, s *vstate) error
+}
+
+// vstate holds the decoded operands of an arithmetic instruction. Sources are read from a copy of the register file
+// taken before the instruction, so that overlapping destinations do not clobber them.
+type vstate struct {
+	sew    uint64
+	lmul8  uint64
+	vl     uint64
+	vstart uint64
+	vlen   uint64
+	vm     bool
+	vd     uint64
+	vs1    uint64
+	vs2    uint64
+	funct3 uint64
+	scalar uint64
+	src    vfile
+	dst    vfile
+}
+
+func (c *CPU) vstate(i uint64, uimm bool) (*vstate, error) {
+	sew, lmul8, err := c.vtype()
+	if err != nil {
+		return nil, err
+	}
+	vd, rs1, vs2 := RType(i)
+	s := &vstate{
+		sew:    sew,
+		lmul8:  lmul8,
+		vl:     c.GetCSR().Get(CSRvl),
+		vstart: c.GetCSR().Get(CSRvstart),
+		vlen:   c.GetVLEN(),
+		vm:     InstructionPart(i, 25, 25) == 1,
+		vd:     vd,
+		vs1:    rs1,
+		vs2:    vs2,
+		funct3: InstructionPart(i, 12, 14),
+		src:    vfile{b: append([]byte{}, c.vreg...), vlenb: c.vlen / 8},
+		dst:    c.vfile(),
+	}
+	switch s.funct3 {
+	case vOPIVX, vOPMVX:
+		s.scalar = c.GetRegister(rs1)
+	case vOPIVI:
+		s.scalar = SignExtend(rs1, 4)
+		if uimm {
+			s.scalar = rs1
+		}
+	case vOPFVF:
+		// Scalar operands narrower than FLEN must be NaN-boxed.
+		s.scalar = math.Float64bits(c.GetRegisterFloatAsFloat64(rs1))
+		if sew == 32 {
+			s.scalar = uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1)))
+		}
+	}
+	return s, nil
+}
+
+func (s *vstate) vector() bool {
+	return s.funct3 == vOPIVV || s.funct3 == vOPFVV || s.funct3 == vOPMVV
+}
+
+func (s *vstate) active(j uint64) bool {
+	return s.vm || s.src.mask(0, j)
+}
+
+func (s *vstate) vlmax() uint64 {
+	return s.vlen * s.lmul8 / 8 / s.sew
+}
+
+// operand returns element j of vs1, or the scalar operand, as an eew-bit value.
+func (s *vstate) operand(j uint64, eew uint64) uint64 {
+	if s.vector() {
+		return s.src.get(s.vs1, eew, j)
+	}
+	return s.scalar & vones(eew)
+}
+
+// groups checks the register groups of vd, vs2 and, for the vector-vector forms, vs1, given their EMUL×8. An EMUL of 0
+// stands for a mask or an operand which is not a vector register, it is not checked. A masked instruction may only
+// write v0 if its result is a mask.
+func (s *vstate) groups(vd uint64, vs2 uint64, vs1 uint64) error {
+	if !s.vector() {
+		vs1 = 0
+	}
+	for _, e := range [][2]uint64{{s.vd, vd}, {s.vs2, vs2}, {s.vs1, vs1}} {
+		if e[1] == 0 {
+			continue
+		}
+		if err := vgroup(e[0], e[1]); err != nil {
+			return err
+		}
+	}
+	if !s.vm && s.vd == 0 && vd != 0 {
+		return ErrAbnormalInstruction
+	}
+	return nil
+}
+
+func (s *vstate) execute(c *CPU, op *vop) error {
+	sew := s.sew
+	switch op.kind {
+	case vkindSingle:
+		if err := s.groups(s.lmul8, s.lmul8, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			if s.active(j) {
+				s.dst.set(s.vd, sew, j, op.f(c, s.src.get(s.vs2, sew, j), s.operand(j, sew), s.src.get(s.vd, sew, j), sew))
+			}
+		}
+	case vkindMask:
+		if err := s.groups(0, s.lmul8, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			if s.active(j) {
+				s.dst.setMask(s.vd, j, op.f(c, s.src.get(s.vs2, sew, j), s.operand(j, sew), 0, sew) != 0)
+			}
+		}
+	case vkindWide, vkindWideW:
+		if sew > 32 {
+			return ErrAbnormalInstruction
+		}
+		aw, am := sew, s.lmul8
+		if op.kind == vkindWideW {
+			aw, am = sew*2, s.lmul8*2
+		}
+		if err := s.groups(s.lmul8*2, am, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			if s.active(j) {
+				s.dst.set(s.vd, sew*2, j, op.f(c, s.src.get(s.vs2, aw, j), s.operand(j, sew), s.src.get(s.vd, sew*2, j), sew))
+			}
+		}
+	case vkindNarrow:
+		if sew > 32 {
+			return ErrAbnormalInstruction
+		}
+		if err := s.groups(s.lmul8, s.lmul8*2, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			if s.active(j) {
+				s.dst.set(s.vd, sew, j, op.f(c, s.src.get(s.vs2, sew*2, j), s.operand(j, sew), 0, sew))
+			}
+		}
+	case vkindReduce, vkindReduceWide:
+		if s.vstart != 0 {
+			return ErrAbnormalInstruction
+		}
+		if err := vgroup(s.vs2, s.lmul8); err != nil {
+			return err
+		}
+		aw := sew
+		if op.kind == vkindReduceWide {
+			if sew > 32 {
+				return ErrAbnormalInstruction
+			}
+			aw = sew * 2
+		}
+		if s.vl == 0 {
+			return nil
+		}
+		acc := s.src.get(s.vs1, aw, 0)
+		for j := uint64(0); j < s.vl; j++ {
+			if s.active(j) {
+				acc = op.f(c, s.src.get(s.vs2, sew, j), acc, 0, sew)
+			}
+		}
+		s.dst.set(s.vd, aw, 0, acc)
+	case vkindCarry:
+		if s.vm || s.vd == 0 {
+			return ErrAbnormalInstruction
+		}
+		if err := s.groups(s.lmul8, s.lmul8, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			s.dst.set(s.vd, sew, j, op.f(c, s.src.get(s.vs2, sew, j), s.operand(j, sew), vbit(s.src.mask(0, j)), sew))
+		}
+	case vkindCarryMask:
+		if err := s.groups(0, s.lmul8, s.lmul8); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			s.dst.setMask(s.vd, j, op.f(c, s.src.get(s.vs2, sew, j), s.operand(j, sew), vbit(!s.vm && s.src.mask(0, j)), sew) != 0)
+		}
+	case vkindMaskLogical:
+		if !s.vm {
+			return ErrAbnormalInstruction
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			s.dst.setMask(s.vd, j, op.f(c, vbit(s.src.mask(s.vs2, j)), vbit(s.src.mask(s.vs1, j)), 0, 1)&1 != 0)
+		}
+	case vkindSpecial:
+		return op.x(c, s)
+	}
+	return nil
+}
+
+func vbit(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var vsuffix = map[uint64]string{
+	vOPIVV: ".vv",
+	vOPFVV: ".vv",
+	vOPMVV: ".vv",
+	vOPIVI: ".vi",
+	vOPIVX: ".vx",
+	vOPFVF: ".vf",
+	vOPMVX: ".vx",
+}
+
+// arith executes the integer, fixed-point, floating-point, mask and permutation instructions of OP-V.
+func (v *isaV) arith(c *CPU, i uint64) (uint64, error) {
+	funct3 := InstructionPart(i, 12, 14)
+	funct6 := InstructionPart(i, 26, 31)
+	var op *vop
+	switch funct3 {
+	case vOPIVV, vOPIVX, vOPIVI:
+		op = vopi[funct6]
+		switch {
+		case funct6 == 0b001110 && funct3 == vOPIVV:
+			op = vopRgatherei16
+		case funct6 == 0b100111 && funct3 == vOPIVI:
+			return v.vmvr(c, i)
+		}
+	case vOPMVV, vOPMVX:
+		op = vopm[funct6]
+	case vOPFVV, vOPFVF:
+		op = vopf[funct6]
+	}
+	if op == nil || op.forms&(1<<funct3) == 0 {
+		return 0, ErrAbnormalInstruction
+	}
+	vd, rs1, vs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  vd: %#02x vs2: %#02x rs1: %#02x", c.GetPC(), op.name+vsuffix[funct3], vd, vs2, rs1))
+	s, err := c.vstate(i, op.uimm)
+	if err != nil {
+		return 0, err
+	}
+	if op.float && s.sew != 32 && s.sew != 64 {
+		return 0, ErrAbnormalInstruction
+	}
+	// Floating-point instructions round with frm, a reserved rounding mode there makes them illegal.
+	if (funct3 == vOPFVV || funct3 == vOPFVF) && c.GetCSR().Get(CSRfrm) > FRoundRMM {
+		return 0, ErrAbnormalInstruction
+	}
+	if err := s.execute(c, op); err != nil {
+		return 0, err
+	}
+	c.GetCSR().Set(CSRvstart, 0)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// vmvr copies whole registers, ignoring vtype and vl.
+func (v *isaV) vmvr(c *CPU, i uint64) (uint64, error) {
+	vd, simm, vs2 := RType(i)
+	nr := simm + 1
+	Debugln(fmt.Sprintf("%#08x % 10s  vd: %#02x vs2: %#02x", c.GetPC(), fmt.Sprintf("vmv%dr.v", nr), vd, vs2))
+	if InstructionPart(i, 25, 25) == 0 || (nr != 1 && nr != 2 && nr != 4 && nr != 8) {
+		return 0, ErrAbnormalInstruction
+	}
+	if vgroup(vd, nr*8) != nil || vgroup(vs2, nr*8) != nil {
+		return 0, ErrAbnormalInstruction
+	}
+	n := c.GetVLEN() / 8
+	copy(c.vreg[vd*n:(vd+nr)*n], c.vreg[vs2*n:(vs2+nr)*n])
+	c.GetCSR().Set(CSRvstart, 0)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// vround returns the rounding increment for shifting v right by d bits under the fixed-point rounding mode in vxrm.
+func (c *CPU) vround(v uint64, d uint64) uint64 {
+	if d == 0 {
+		return 0
+	}
+	lsb := v >> d & 1
+	half := v >> (d - 1) & 1
+	rest := v & (1<<(d-1) - 1)
+	switch c.GetCSR().Get(CSRvxrm) {
+	case 0b00:
+		// Round-to-nearest-up
+		return half
+	case 0b01:
+		// Round-to-nearest-even
+		return half & vbit(rest != 0 || lsb == 1)
+	case 0b10:
+		// Round-down
+		return 0
+	}
+	// Round-to-odd
+	return vbit(lsb == 0 && (half|rest) != 0)
+}
+
+// vclip saturates a signed value to sew bits and sets vxsat if it does not fit.
+func (c *CPU) vclip(v int64, sew uint64) uint64 {
+	hi := int64(vones(sew) >> 1)
+	lo := -hi - 1
+	switch {
+	case v > hi:
+		c.GetCSR().Set(CSRvxsat, 1)
+		return uint64(hi)
+	case v < lo:
+		c.GetCSR().Set(CSRvxsat, 1)
+		return uint64(lo)
+	}
+	return uint64(v)
+}
+
+func (c *CPU) vsaturate(sew uint64, hi bool) uint64 {
+	c.GetCSR().Set(CSRvxsat, 1)
+	if hi {
+		return vones(sew) >> 1
+	}
+	return ^(vones(sew) >> 1)
+}
+
+func vmulh(x int64, y int64, sew uint64) uint64 {
+	if sew == 64 {
+		hi, _ := bits.Mul64(uint64(x), uint64(y))
+		if x < 0 {
+			hi -= uint64(y)
+		}
+		if y < 0 {
+			hi -= uint64(x)
+		}
+		return hi
+	}
+	return uint64(x * y >> sew)
+}
+
+func vmulhu(a uint64, b uint64, sew uint64) uint64 {
+	if sew == 64 {
+		hi, _ := bits.Mul64(a, b)
+		return hi
+	}
+	return a * b >> sew
+}
+
+func vmulhsu(x int64, b uint64, sew uint64) uint64 {
+	if sew == 64 {
+		hi, _ := bits.Mul64(uint64(x), b)
+		if x < 0 {
+			hi -= b
+		}
+		return hi
+	}
+	return uint64(x * int64(b) >> sew)
+}
+
+var vopi = map[uint64]*vop{
+	0b000000: {name: "vadd", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a + b }},
+	0b000010: {name: "vsub", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a - b }},
+	0b000011: {name: "vrsub", forms: vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b - a }},
+	0b000100: {name: "vminu", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if a < b {
+			return a
+		}
+		return b
+	}},
+	0b000101: {name: "vmin", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if vsigned(a, sew) < vsigned(b, sew) {
+			return a
+		}
+		return b
+	}},
+	0b000110: {name: "vmaxu", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if a > b {
+			return a
+		}
+		return b
+	}},
+	0b000111: {name: "vmax", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if vsigned(a, sew) > vsigned(b, sew) {
+			return a
+		}
+		return b
+	}},
+	0b001001: {name: "vand", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a & b }},
+	0b001010: {name: "vor", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a | b }},
+	0b001011: {name: "vxor", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a ^ b }},
+	0b001100: {name: "vrgather", forms: vIVV | vIVX | vIVI, kind: vkindSpecial, uimm: true, x: func(c *CPU, s *vstate) error {
+		return s.rgather(s.sew)
+	}},
+	0b001110: {name: "vslideup", forms: vIVX | vIVI, kind: vkindSpecial, uimm: true, x: func(c *CPU, s *vstate) error {
+		return s.slideup()
+	}},
+	0b001111: {name: "vslidedown", forms: vIVX | vIVI, kind: vkindSpecial, uimm: true, x: func(c *CPU, s *vstate) error {
+		return s.slidedown()
+	}},
+	0b010000: {name: "vadc", forms: vIVV | vIVX | vIVI, kind: vkindCarry, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a + b + d }},
+	0b010001: {name: "vmadc", forms: vIVV | vIVX | vIVI, kind: vkindCarryMask, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		_, carry := bits.Add64(a, b, d)
+		if sew == 64 {
+			return carry
+		}
+		return (a + b + d) >> sew
+	}},
+	0b010010: {name: "vsbc", forms: vIVV | vIVX, kind: vkindCarry, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a - b - d }},
+	0b010011: {name: "vmsbc", forms: vIVV | vIVX, kind: vkindCarryMask, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		_, borrow := bits.Sub64(a, b, d)
+		return borrow
+	}},
+	0b010111: {name: "vmerge", forms: vIVV | vIVX | vIVI, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.merge()
+	}},
+	0b011000: {name: "vmseq", forms: vIVV | vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vbit(a == b) }},
+	0b011001: {name: "vmsne", forms: vIVV | vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vbit(a != b) }},
+	0b011010: {name: "vmsltu", forms: vIVV | vIVX, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vbit(a < b) }},
+	0b011011: {name: "vmslt", forms: vIVV | vIVX, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return vbit(vsigned(a, sew) < vsigned(b, sew))
+	}},
+	0b011100: {name: "vmsleu", forms: vIVV | vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vbit(a <= b) }},
+	0b011101: {name: "vmsle", forms: vIVV | vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return vbit(vsigned(a, sew) <= vsigned(b, sew))
+	}},
+	0b011110: {name: "vmsgtu", forms: vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vbit(a > b) }},
+	0b011111: {name: "vmsgt", forms: vIVX | vIVI, kind: vkindMask, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return vbit(vsigned(a, sew) > vsigned(b, sew))
+	}},
+	0b100000: {name: "vsaddu", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		r := (a + b) & vones(sew)
+		if r < a {
+			c.GetCSR().Set(CSRvxsat, 1)
+			return vones(sew)
+		}
+		return r
+	}},
+	0b100001: {name: "vsadd", forms: vIVV | vIVX | vIVI, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		r := x + y
+		if (x^r)&(y^r) < 0 {
+			return c.vsaturate(sew, x >= 0)
+		}
+		return c.vclip(r, sew)
+	}},
+	0b100010: {name: "vssubu", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if a < b {
+			c.GetCSR().Set(CSRvxsat, 1)
+			return 0
+		}
+		return a - b
+	}},
+	0b100011: {name: "vssub", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		r := x - y
+		if (x^y)&(x^r) < 0 {
+			return c.vsaturate(sew, x >= 0)
+		}
+		return c.vclip(r, sew)
+	}},
+	0b100101: {name: "vsll", forms: vIVV | vIVX | vIVI, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a << (b & (sew - 1)) }},
+	0b100111: {name: "vsmul", forms: vIVV | vIVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		min := -int64(vones(sew)>>1) - 1
+		if x == min && y == min {
+			return c.vsaturate(sew, true)
+		}
+		if sew == 64 {
+			hi, lo := bits.Mul64(a, b)
+			if x < 0 {
+				hi -= b
+			}
+			if y < 0 {
+				hi -= a
+			}
+			return (hi<<1 | lo>>63) + c.vround(lo, 63)
+		}
+		p := x * y
+		return c.vclip(p>>(sew-1)+int64(c.vround(uint64(p), sew-1)), sew)
+	}},
+	0b101000: {name: "vsrl", forms: vIVV | vIVX | vIVI, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a >> (b & (sew - 1)) }},
+	0b101001: {name: "vsra", forms: vIVV | vIVX | vIVI, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew) >> (b & (sew - 1)))
+	}},
+	0b101010: {name: "vssrl", forms: vIVV | vIVX | vIVI, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		sh := b & (sew - 1)
+		return a>>sh + c.vround(a, sh)
+	}},
+	0b101011: {name: "vssra", forms: vIVV | vIVX | vIVI, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		sh := b & (sew - 1)
+		return uint64(vsigned(a, sew)>>sh) + c.vround(a, sh)
+	}},
+	0b101100: {name: "vnsrl", forms: vIVV | vIVX | vIVI, kind: vkindNarrow, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a >> (b & (sew*2 - 1))
+	}},
+	0b101101: {name: "vnsra", forms: vIVV | vIVX | vIVI, kind: vkindNarrow, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew*2) >> (b & (sew*2 - 1)))
+	}},
+	0b101110: {name: "vnclipu", forms: vIVV | vIVX | vIVI, kind: vkindNarrow, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		sh := b & (sew*2 - 1)
+		r := a>>sh + c.vround(a, sh)
+		if r > vones(sew) {
+			c.GetCSR().Set(CSRvxsat, 1)
+			return vones(sew)
+		}
+		return r
+	}},
+	0b101111: {name: "vnclip", forms: vIVV | vIVX | vIVI, kind: vkindNarrow, uimm: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		sh := b & (sew*2 - 1)
+		return c.vclip(vsigned(a, sew*2)>>sh+int64(c.vround(a, sh)), sew)
+	}},
+	0b110000: {name: "vwredsumu", forms: vIVV, kind: vkindReduceWide, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b + a }},
+	0b110001: {name: "vwredsum", forms: vIVV, kind: vkindReduceWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return b + uint64(vsigned(a, sew))
+	}},
+}
+
+var vopRgatherei16 = &vop{name: "vrgatherei16", forms: vIVV, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+	return s.rgather(16)
+}}
+
+var vopm = map[uint64]*vop{
+	0b000000: {name: "vredsum", forms: vMVV, kind: vkindReduce, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b + a }},
+	0b000001: {name: "vredand", forms: vMVV, kind: vkindReduce, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b & a }},
+	0b000010: {name: "vredor", forms: vMVV, kind: vkindReduce, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b | a }},
+	0b000011: {name: "vredxor", forms: vMVV, kind: vkindReduce, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b ^ a }},
+	0b000100: {name: "vredminu", forms: vMVV, kind: vkindReduce, f: vopi[0b000100].f},
+	0b000101: {name: "vredmin", forms: vMVV, kind: vkindReduce, f: vopi[0b000101].f},
+	0b000110: {name: "vredmaxu", forms: vMVV, kind: vkindReduce, f: vopi[0b000110].f},
+	0b000111: {name: "vredmax", forms: vMVV, kind: vkindReduce, f: vopi[0b000111].f},
+	0b001000: {name: "vaaddu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		q := a>>1 + b>>1 + a&b&1
+		return q + c.vround(q<<1|(a^b)&1, 1)
+	}},
+	0b001001: {name: "vaadd", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		q := uint64(x>>1 + y>>1 + x&y&1)
+		return q + c.vround(q<<1|(a^b)&1, 1)
+	}},
+	0b001010: {name: "vasubu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		q := a>>1 - b>>1 - ^a&b&1
+		return q + c.vround(q<<1|(a^b)&1, 1)
+	}},
+	0b001011: {name: "vasub", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		q := uint64(x>>1 - y>>1 - ^x&y&1)
+		return q + c.vround(q<<1|(a^b)&1, 1)
+	}},
+	0b001110: {name: "vslide1up", forms: vMVX, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.slide1up()
+	}},
+	0b001111: {name: "vslide1down", forms: vMVX, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.slide1down()
+	}},
+	0b010000: {name: "vwxunary0", forms: vMVV | vMVX, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.wxunary0(c)
+	}},
+	0b010010: {name: "vxunary0", forms: vMVV, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.extend()
+	}},
+	0b010100: {name: "vmunary0", forms: vMVV, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.munary0()
+	}},
+	0b010111: {name: "vcompress", forms: vMVV, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.compress()
+	}},
+	0b011000: {name: "vmandn", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a &^ b }},
+	0b011001: {name: "vmand", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a & b }},
+	0b011010: {name: "vmor", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a | b }},
+	0b011011: {name: "vmxor", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a ^ b }},
+	0b011100: {name: "vmorn", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a | ^b }},
+	0b011101: {name: "vmnand", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return ^(a & b) }},
+	0b011110: {name: "vmnor", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return ^(a | b) }},
+	0b011111: {name: "vmxnor", forms: vMVV, kind: vkindMaskLogical, f: func(c *CPU, a, b, d, sew uint64) uint64 { return ^(a ^ b) }},
+	0b100000: {name: "vdivu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if b == 0 {
+			return vones(sew)
+		}
+		return a / b
+	}},
+	0b100001: {name: "vdiv", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		switch {
+		case y == 0:
+			return vones(sew)
+		case y == -1:
+			// The quotient of the most negative number by -1 overflows to itself.
+			return uint64(-x)
+		}
+		return uint64(x / y)
+	}},
+	0b100010: {name: "vremu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		if b == 0 {
+			return a
+		}
+		return a % b
+	}},
+	0b100011: {name: "vrem", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y := vsigned(a, sew), vsigned(b, sew)
+		switch {
+		case y == 0:
+			return a
+		case y == -1:
+			return 0
+		}
+		return uint64(x % y)
+	}},
+	0b100100: {name: "vmulhu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vmulhu(a, b, sew) }},
+	0b100101: {name: "vmul", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a * b }},
+	0b100110: {name: "vmulhsu", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return vmulhsu(vsigned(a, sew), b, sew) }},
+	0b100111: {name: "vmulh", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return vmulh(vsigned(a, sew), vsigned(b, sew), sew)
+	}},
+	0b101001: {name: "vmadd", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b*d + a }},
+	0b101011: {name: "vnmsub", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a - b*d }},
+	0b101101: {name: "vmacc", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return b*a + d }},
+	0b101111: {name: "vnmsac", forms: vMVV | vMVX, f: func(c *CPU, a, b, d, sew uint64) uint64 { return d - b*a }},
+	0b110000: {name: "vwaddu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a + b }},
+	0b110001: {name: "vwadd", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew) + vsigned(b, sew))
+	}},
+	0b110010: {name: "vwsubu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a - b }},
+	0b110011: {name: "vwsub", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew) - vsigned(b, sew))
+	}},
+	0b110100: {name: "vwaddu.w", forms: vMVV | vMVX, kind: vkindWideW, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a + b }},
+	0b110101: {name: "vwadd.w", forms: vMVV | vMVX, kind: vkindWideW, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a + uint64(vsigned(b, sew))
+	}},
+	0b110110: {name: "vwsubu.w", forms: vMVV | vMVX, kind: vkindWideW, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a - b }},
+	0b110111: {name: "vwsub.w", forms: vMVV | vMVX, kind: vkindWideW, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a - uint64(vsigned(b, sew))
+	}},
+	0b111000: {name: "vwmulu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 { return a * b }},
+	0b111010: {name: "vwmulsu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew) * int64(b))
+	}},
+	0b111011: {name: "vwmul", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return uint64(vsigned(a, sew) * vsigned(b, sew))
+	}},
+	0b111100: {name: "vwmaccu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 { return d + a*b }},
+	0b111101: {name: "vwmacc", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return d + uint64(vsigned(a, sew)*vsigned(b, sew))
+	}},
+	0b111110: {name: "vwmaccus", forms: vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return d + uint64(vsigned(a, sew)*int64(b))
+	}},
+	0b111111: {name: "vwmaccsu", forms: vMVV | vMVX, kind: vkindWide, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return d + uint64(vsigned(b, sew)*int64(a))
+	}},
+}
+
+func vfloat(a uint64, sew uint64) float64 {
+	if sew == 32 {
+		return float64(math.Float32frombits(uint32(a)))
+	}
+	return math.Float64frombits(a)
+}
+
+// vfcheck raises the invalid flag for signaling NaN operands and reports whether any operand is a NaN.
+func (c *CPU) vfcheck(sew uint64, in ...uint64) bool {
+	nan := false
+	for _, a := range in {
+		if sew == 32 {
+			f := math.Float32frombits(uint32(a))
+			nan = nan || math.IsNaN(float64(f))
+			if IsSNaN32(f) {
+				c.SetFloatFlag(FFlagsNV, 1)
+			}
+			continue
+		}
+		f := math.Float64frombits(a)
+		nan = nan || math.IsNaN(f)
+		if IsSNaN64(f) {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
+	}
+	return nan
+}
+
+// vfbits converts the result of an operation to sew bits. A NaN result is replaced by the canonical NaN and raises the
+// invalid flag unless an operand was a NaN.
+func (c *CPU) vfbits(r float64, sew uint64, nan bool) uint64 {
+	if math.IsNaN(r) {
+		if !nan {
+			c.SetFloatFlag(FFlagsNV, 1)
+		}
+		if sew == 32 {
+			return uint64(NaN32)
+		}
+		return NaN64
+	}
+	if sew == 32 {
+		return uint64(math.Float32bits(float32(r)))
+	}
+	return math.Float64bits(r)
+}
+
+// vformat returns the floating-point format of sew-bit elements.
+func vformat(sew uint64) FloatFormat {
+	if sew == 32 {
+		return FloatFormatS
+	}
+	return FloatFormatD
+}
+
+// vfneg flips the sign of a value in format f.
+func vfneg(f FloatFormat, a uint64) uint64 {
+	return a ^ 1<<(f.Exp+f.Frac)
+}
+
+// vfop builds the element function of a floating-point operation on vs2, the vs1 or scalar operand and vd, all of
+// sew bits. The operation rounds with frm and its exception flags are accrued.
+func vfop(g func(f FloatFormat, a uint64, b uint64, d uint64, rm uint64) (uint64, uint64)) func(c *CPU, a, b, d, sew uint64) uint64 {
+	return func(c *CPU, a, b, d, sew uint64) uint64 {
+		r, flags := g(vformat(sew), a, b, d, c.GetCSR().Get(CSRfrm))
+		c.SetFloatFlag(flags, 1)
+		return r
+	}
+}
+
+// vfwiden converts a sew-bit element to 2×sew bits, which is exact.
+func (c *CPU) vfwiden(a uint64, sew uint64) uint64 {
+	r, flags := SoftConvert(vformat(sew), vformat(sew*2), a, FRoundRNE)
+	c.SetFloatFlag(flags, 1)
+	return r
+}
+
+// vfwop is vfop for the widening operations, vd is 2×sew bits and so is vs2 if wide is set. The narrow operands are
+// widened first.
+func vfwop(g func(f FloatFormat, a uint64, b uint64, d uint64, rm uint64) (uint64, uint64), wide bool) func(c *CPU, a, b, d, sew uint64) uint64 {
+	return func(c *CPU, a, b, d, sew uint64) uint64 {
+		if !wide {
+			a = c.vfwiden(a, sew)
+		}
+		return vfop(g)(c, a, c.vfwiden(b, sew), d, sew*2)
+	}
+}
+
+// vfwred adds the sew-bit element a to the 2×sew-bit accumulator b.
+func vfwred(c *CPU, a, b, d, sew uint64) uint64 {
+	return vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, b, a, rm)
+	})(c, c.vfwiden(a, sew), b, d, sew*2)
+}
+
+func (c *CPU) vfminmax(a uint64, b uint64, sew uint64, max bool) uint64 {
+	c.vfcheck(sew, a, b)
+	x, y := vfloat(a, sew), vfloat(b, sew)
+	switch {
+	case math.IsNaN(x) && math.IsNaN(y):
+		return c.vfbits(math.NaN(), sew, true)
+	case math.IsNaN(x):
+		return b
+	case math.IsNaN(y):
+		return a
+	case x == y:
+		// -0.0 is considered to be less than +0.0.
+		if math.Signbit(x) != max {
+			return a
+		}
+		return b
+	case (x > y) == max:
+		return a
+	}
+	return b
+}
+
+// vfcmp compares two floating-point values. Quiet comparisons only raise the invalid flag for signaling NaNs.
+func (c *CPU) vfcmp(a uint64, b uint64, sew uint64, quiet bool) (float64, float64, bool) {
+	nan := c.vfcheck(sew, a, b)
+	if nan && !quiet {
+		c.SetFloatFlag(FFlagsNV, 1)
+	}
+	return vfloat(a, sew), vfloat(b, sew), nan
+}
+
+func vfsign(sew uint64) uint64 {
+	return 1 << (sew - 1)
+}
+
+var vopf = map[uint64]*vop{
+	0b000000: {name: "vfadd", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, a, b, rm)
+	})},
+	0b000001: {name: "vfredusum", forms: vFVV, kind: vkindReduce, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, b, a, rm)
+	})},
+	0b000010: {name: "vfsub", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftSub(f, a, b, rm)
+	})},
+	0b000011: {name: "vfredosum", forms: vFVV, kind: vkindReduce, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, b, a, rm)
+	})},
+	0b000100: {name: "vfmin", forms: vFVV | vFVF, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 { return c.vfminmax(a, b, sew, false) }},
+	0b000101: {name: "vfredmin", forms: vFVV, kind: vkindReduce, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return c.vfminmax(a, b, sew, false)
+	}},
+	0b000110: {name: "vfmax", forms: vFVV | vFVF, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 { return c.vfminmax(a, b, sew, true) }},
+	0b000111: {name: "vfredmax", forms: vFVV, kind: vkindReduce, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return c.vfminmax(a, b, sew, true)
+	}},
+	0b001000: {name: "vfsgnj", forms: vFVV | vFVF, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a&^vfsign(sew) | b&vfsign(sew)
+	}},
+	0b001001: {name: "vfsgnjn", forms: vFVV | vFVF, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a&^vfsign(sew) | ^b&vfsign(sew)
+	}},
+	0b001010: {name: "vfsgnjx", forms: vFVV | vFVF, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		return a ^ b&vfsign(sew)
+	}},
+	0b001110: {name: "vfslide1up", forms: vFVF, kind: vkindSpecial, float: true, x: func(c *CPU, s *vstate) error {
+		return s.slide1up()
+	}},
+	0b001111: {name: "vfslide1down", forms: vFVF, kind: vkindSpecial, float: true, x: func(c *CPU, s *vstate) error {
+		return s.slide1down()
+	}},
+	0b010000: {name: "vwfunary0", forms: vFVV | vFVF, kind: vkindSpecial, float: true, x: func(c *CPU, s *vstate) error {
+		return s.wfunary0(c)
+	}},
+	0b010010: {name: "vfunary0", forms: vFVV, kind: vkindSpecial, x: func(c *CPU, s *vstate) error {
+		return s.funary0(c)
+	}},
+	0b010011: {name: "vfunary1", forms: vFVV, kind: vkindSpecial, float: true, x: func(c *CPU, s *vstate) error {
+		return s.funary1(c)
+	}},
+	0b010111: {name: "vfmerge", forms: vFVF, kind: vkindSpecial, float: true, x: func(c *CPU, s *vstate) error {
+		return s.merge()
+	}},
+	0b011000: {name: "vmfeq", forms: vFVV | vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, true)
+		return vbit(x == y)
+	}},
+	0b011001: {name: "vmfle", forms: vFVV | vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, false)
+		return vbit(x <= y)
+	}},
+	0b011011: {name: "vmflt", forms: vFVV | vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, false)
+		return vbit(x < y)
+	}},
+	0b011100: {name: "vmfne", forms: vFVV | vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, true)
+		return vbit(x != y)
+	}},
+	0b011101: {name: "vmfgt", forms: vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, false)
+		return vbit(x > y)
+	}},
+	0b011111: {name: "vmfge", forms: vFVF, kind: vkindMask, float: true, f: func(c *CPU, a, b, d, sew uint64) uint64 {
+		x, y, _ := c.vfcmp(a, b, sew, false)
+		return vbit(x >= y)
+	}},
+	0b100000: {name: "vfdiv", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftDiv(f, a, b, rm)
+	})},
+	0b100001: {name: "vfrdiv", forms: vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftDiv(f, b, a, rm)
+	})},
+	0b100100: {name: "vfmul", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMul(f, a, b, rm)
+	})},
+	0b100111: {name: "vfrsub", forms: vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftSub(f, b, a, rm)
+	})},
+	0b101000: {name: "vfmadd", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, d, a, rm)
+	})},
+	0b101001: {name: "vfnmadd", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), d, vfneg(f, a), rm)
+	})},
+	0b101010: {name: "vfmsub", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, d, vfneg(f, a), rm)
+	})},
+	0b101011: {name: "vfnmsub", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), d, a, rm)
+	})},
+	0b101100: {name: "vfmacc", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, a, d, rm)
+	})},
+	0b101101: {name: "vfnmacc", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), a, vfneg(f, d), rm)
+	})},
+	0b101110: {name: "vfmsac", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, a, vfneg(f, d), rm)
+	})},
+	0b101111: {name: "vfnmsac", forms: vFVV | vFVF, float: true, f: vfop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), a, d, rm)
+	})},
+	0b110000: {name: "vfwadd", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, a, b, rm)
+	}, false)},
+	0b110001: {name: "vfwredusum", forms: vFVV, kind: vkindReduceWide, float: true, f: vfwred},
+	0b110010: {name: "vfwsub", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftSub(f, a, b, rm)
+	}, false)},
+	0b110011: {name: "vfwredosum", forms: vFVV, kind: vkindReduceWide, float: true, f: vfwred},
+	0b110100: {name: "vfwadd.w", forms: vFVV | vFVF, kind: vkindWideW, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftAdd(f, a, b, rm)
+	}, true)},
+	0b110110: {name: "vfwsub.w", forms: vFVV | vFVF, kind: vkindWideW, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftSub(f, a, b, rm)
+	}, true)},
+	0b111000: {name: "vfwmul", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMul(f, a, b, rm)
+	}, false)},
+	0b111100: {name: "vfwmacc", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, a, d, rm)
+	}, false)},
+	0b111101: {name: "vfwnmacc", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), a, vfneg(f, d), rm)
+	}, false)},
+	0b111110: {name: "vfwmsac", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, b, a, vfneg(f, d), rm)
+	}, false)},
+	0b111111: {name: "vfwnmsac", forms: vFVV | vFVF, kind: vkindWide, float: true, f: vfwop(func(f FloatFormat, a, b, d, rm uint64) (uint64, uint64) {
+		return SoftMulAdd(f, vfneg(f, b), a, d, rm)
+	}, false)},
+}
+
+// rgather gathers the elements of vs2 selected by the ieew-bit indices in vs1, or by the scalar operand.
+func (s *vstate) rgather(ieew uint64) error {
+	iemul8 := s.lmul8
+	if s.vector() {
+		if s.lmul8*ieew%s.sew != 0 {
+			return ErrAbnormalInstruction
+		}
+		iemul8 = s.lmul8 * ieew / s.sew
+	}
+	if err := s.groups(s.lmul8, s.lmul8, iemul8); err != nil {
+		return err
+	}
+	vlmax := s.vlmax()
+	for j := s.vstart; j < s.vl; j++ {
+		if !s.active(j) {
+			continue
+		}
+		idx := s.scalar
+		if s.vector() {
+			idx = s.src.get(s.vs1, ieew, j)
+		}
+		r := uint64(0)
+		if idx < vlmax {
+			r = s.src.get(s.vs2, s.sew, idx)
+		}
+		s.dst.set(s.vd, s.sew, j, r)
+	}
+	return nil
+}
+
+func (s *vstate) slideup() error {
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if j >= s.scalar && s.active(j) {
+			s.dst.set(s.vd, s.sew, j, s.src.get(s.vs2, s.sew, j-s.scalar))
+		}
+	}
+	return nil
+}
+
+func (s *vstate) slidedown() error {
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	vlmax := s.vlmax()
+	for j := s.vstart; j < s.vl; j++ {
+		if !s.active(j) {
+			continue
+		}
+		r := uint64(0)
+		if s.scalar < vlmax && j+s.scalar < vlmax {
+			r = s.src.get(s.vs2, s.sew, j+s.scalar)
+		}
+		s.dst.set(s.vd, s.sew, j, r)
+	}
+	return nil
+}
+
+func (s *vstate) slide1up() error {
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if !s.active(j) {
+			continue
+		}
+		r := s.scalar
+		if j != 0 {
+			r = s.src.get(s.vs2, s.sew, j-1)
+		}
+		s.dst.set(s.vd, s.sew, j, r)
+	}
+	return nil
+}
+
+func (s *vstate) slide1down() error {
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if !s.active(j) {
+			continue
+		}
+		r := s.scalar
+		if j+1 < s.vl {
+			r = s.src.get(s.vs2, s.sew, j+1)
+		}
+		s.dst.set(s.vd, s.sew, j, r)
+	}
+	return nil
+}
+
+// merge performs vmerge and vfmerge, or when unmasked vmv.v and vfmv.v.f.
+func (s *vstate) merge() error {
+	if s.vm && s.vs2 != 0 {
+		return ErrAbnormalInstruction
+	}
+	if err := s.groups(s.lmul8, s.lmul8, s.lmul8); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		r := s.operand(j, s.sew)
+		if !s.vm && !s.src.mask(0, j) {
+			r = s.src.get(s.vs2, s.sew, j)
+		}
+		s.dst.set(s.vd, s.sew, j, r)
+	}
+	return nil
+}
+
+// wxunary0 performs vmv.s.x, vmv.x.s, vcpop.m and vfirst.m.
+func (s *vstate) wxunary0(c *CPU) error {
+	if s.funct3 == vOPMVX {
+		if s.vs2 != 0 || !s.vm {
+			return ErrAbnormalInstruction
+		}
+		if s.vstart < s.vl {
+			s.dst.set(s.vd, s.sew, 0, s.scalar)
+		}
+		return nil
+	}
+	switch s.vs1 {
+	case 0b00000:
+		if !s.vm {
+			return ErrAbnormalInstruction
+		}
+		c.SetRegister(s.vd, SignExtend(s.src.get(s.vs2, s.sew, 0), s.sew-1))
+	case 0b10000:
+		if s.vstart != 0 {
+			return ErrAbnormalInstruction
+		}
+		n := uint64(0)
+		for j := uint64(0); j < s.vl; j++ {
+			if s.active(j) && s.src.mask(s.vs2, j) {
+				n++
+			}
+		}
+		c.SetRegister(s.vd, n)
+	case 0b10001:
+		if s.vstart != 0 {
+			return ErrAbnormalInstruction
+		}
+		r := uint64(math.MaxUint64)
+		for j := uint64(0); j < s.vl; j++ {
+			if s.active(j) && s.src.mask(s.vs2, j) {
+				r = j
+				break
+			}
+		}
+		c.SetRegister(s.vd, r)
+	default:
+		return ErrAbnormalInstruction
+	}
+	return nil
+}
+
+// extend performs vzext.vf2, vsext.vf2 and their vf4 and vf8 variants.
+func (s *vstate) extend() error {
+	var f uint64
+	switch s.vs1 {
+	case 0b00010, 0b00011:
+		f = 8
+	case 0b00100, 0b00101:
+		f = 4
+	case 0b00110, 0b00111:
+		f = 2
+	default:
+		return ErrAbnormalInstruction
+	}
+	eew := s.sew / f
+	if eew < 8 {
+		return ErrAbnormalInstruction
+	}
+	if err := s.groups(s.lmul8, s.lmul8/f, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if !s.active(j) {
+			continue
+		}
+		a := s.src.get(s.vs2, eew, j)
+		if s.vs1&1 == 1 {
+			a = SignExtend(a, eew-1)
+		}
+		s.dst.set(s.vd, s.sew, j, a)
+	}
+	return nil
+}
+
+// munary0 performs vmsbf.m, vmsof.m, vmsif.m, viota.m and vid.v.
+func (s *vstate) munary0() error {
+	switch s.vs1 {
+	case 0b00001, 0b00010, 0b00011:
+		if s.vstart != 0 || s.vd == s.vs2 || (!s.vm && s.vd == 0) {
+			return ErrAbnormalInstruction
+		}
+		found := false
+		for j := uint64(0); j < s.vl; j++ {
+			if !s.active(j) {
+				continue
+			}
+			bit := s.src.mask(s.vs2, j)
+			switch s.vs1 {
+			case 0b00001:
+				s.dst.setMask(s.vd, j, !found && !bit)
+			case 0b00010:
+				s.dst.setMask(s.vd, j, !found && bit)
+			case 0b00011:
+				s.dst.setMask(s.vd, j, !found)
+			}
+			found = found || bit
+		}
+	case 0b10000:
+		if s.vstart != 0 {
+			return ErrAbnormalInstruction
+		}
+		if err := s.groups(s.lmul8, 0, 0); err != nil {
+			return err
+		}
+		n := uint64(0)
+		for j := uint64(0); j < s.vl; j++ {
+			if !s.active(j) {
+				continue
+			}
+			s.dst.set(s.vd, s.sew, j, n)
+			if s.src.mask(s.vs2, j) {
+				n++
+			}
+		}
+	case 0b10001:
+		if s.vs2 != 0 {
+			return ErrAbnormalInstruction
+		}
+		if err := s.groups(s.lmul8, 0, 0); err != nil {
+			return err
+		}
+		for j := s.vstart; j < s.vl; j++ {
+			if s.active(j) {
+				s.dst.set(s.vd, s.sew, j, j)
+			}
+		}
+	default:
+		return ErrAbnormalInstruction
+	}
+	return nil
+}
+
+func (s *vstate) compress() error {
+	if !s.vm || s.vstart != 0 {
+		return ErrAbnormalInstruction
+	}
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	k := uint64(0)
+	for j := uint64(0); j < s.vl; j++ {
+		if s.src.mask(s.vs1, j) {
+			s.dst.set(s.vd, s.sew, k, s.src.get(s.vs2, s.sew, j))
+			k++
+		}
+	}
+	return nil
+}
+
+// wfunary0 performs vfmv.s.f and vfmv.f.s.
+func (s *vstate) wfunary0(c *CPU) error {
+	if !s.vm {
+		return ErrAbnormalInstruction
+	}
+	if s.funct3 == vOPFVF {
+		if s.vs2 != 0 {
+			return ErrAbnormalInstruction
+		}
+		if s.vstart < s.vl {
+			s.dst.set(s.vd, s.sew, 0, s.scalar)
+		}
+		return nil
+	}
+	if s.vs1 != 0 {
+		return ErrAbnormalInstruction
+	}
+	r := s.src.get(s.vs2, s.sew, 0)
+	if s.sew == 32 {
+		r |= 0xffffffff00000000
+	}
+	c.SetRegisterFloat(s.vd, r)
+	return nil
+}
+
+// vrod is the round-towards-odd mode of vfncvt.rod.f.f.w, it is not a valid frm.
+const vrod = 8
+
+// vfcvtx converts a sw-bit floating-point value to a dw-bit integer. Values out of range saturate and raise the invalid
+// flag.
+func (c *CPU) vfcvtx(a uint64, sw uint64, dw uint64, signed bool, rm uint64) uint64 {
+	r, flags := SoftToInt(vformat(sw), a, signed, uint(dw), rm)
+	c.SetFloatFlag(flags, 1)
+	return r & vones(dw)
+}
+
+// vfcvtf converts a sw-bit integer to a dw-bit floating-point value.
+func (c *CPU) vfcvtf(a uint64, sw uint64, signed bool, dw uint64, rm uint64) uint64 {
+	r, flags := SoftFromInt(vformat(dw), a, signed, uint(sw), rm)
+	c.SetFloatFlag(flags, 1)
+	return r
+}
+
+// vfcvtff converts a sw-bit floating-point value to dw bits. Rounding towards odd truncates, then sets the least
+// significant bit of inexact results.
+func (c *CPU) vfcvtff(a uint64, sw uint64, dw uint64, rm uint64) uint64 {
+	if rm != vrod {
+		r, flags := SoftConvert(vformat(sw), vformat(dw), a, rm)
+		c.SetFloatFlag(flags, 1)
+		return r
+	}
+	r, flags := SoftConvert(vformat(sw), vformat(dw), a, FRoundRTZ)
+	c.SetFloatFlag(flags, 1)
+	if flags&FFlagsNX != 0 {
+		r |= 1
+	}
+	return r
+}
+
+// funary0 performs the single-width, widening and narrowing conversions.
+func (s *vstate) funary0(c *CPU) error {
+	sw, dw := s.sew, s.sew
+	switch s.vs1 >> 3 {
+	case 0b01:
+		dw = s.sew * 2
+	case 0b10:
+		sw = s.sew * 2
+	case 0b11:
+		return ErrAbnormalInstruction
+	}
+	rm := c.GetCSR().Get(CSRfrm)
+	if s.vs1&0b110 == 0b110 {
+		rm = FRoundRTZ
+	}
+	var conv func(a uint64) uint64
+	switch s.vs1 & 0b111 {
+	case 0b000, 0b110:
+		conv = func(a uint64) uint64 { return c.vfcvtx(a, sw, dw, false, rm) }
+	case 0b001, 0b111:
+		conv = func(a uint64) uint64 { return c.vfcvtx(a, sw, dw, true, rm) }
+	case 0b010:
+		conv = func(a uint64) uint64 { return c.vfcvtf(a, sw, false, dw, rm) }
+	case 0b011:
+		conv = func(a uint64) uint64 { return c.vfcvtf(a, sw, true, dw, rm) }
+	case 0b100:
+		if sw == dw {
+			return ErrAbnormalInstruction
+		}
+		conv = func(a uint64) uint64 { return c.vfcvtff(a, sw, dw, rm) }
+	case 0b101:
+		if sw <= dw {
+			return ErrAbnormalInstruction
+		}
+		conv = func(a uint64) uint64 { return c.vfcvtff(a, sw, dw, vrod) }
+	}
+	// Floating-point operands must be 32 or 64 bits, integers up to 64 bits.
+	fs := s.vs1&0b111 <= 0b001 || s.vs1&0b111 >= 0b100
+	fd := !(s.vs1&0b111 <= 0b001 || s.vs1&0b110 == 0b110)
+	if sw > 64 || dw > 64 || (fs && sw != 32 && sw != 64) || (fd && dw != 32 && dw != 64) {
+		return ErrAbnormalInstruction
+	}
+	if err := s.groups(s.lmul8*dw/s.sew, s.lmul8*sw/s.sew, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if s.active(j) {
+			s.dst.set(s.vd, dw, j, conv(s.src.get(s.vs2, sw, j)))
+		}
+	}
+	return nil
+}
+
+// funary1 performs vfsqrt.v, vfrsqrt7.v, vfrec7.v and vfclass.v.
+func (s *vstate) funary1(c *CPU) error {
+	sew := s.sew
+	var f func(a uint64) uint64
+	switch s.vs1 {
+	case 0b00000:
+		f = func(a uint64) uint64 {
+			r, flags := SoftSqrt(vformat(sew), a, c.GetCSR().Get(CSRfrm))
+			c.SetFloatFlag(flags, 1)
+			return r
+		}
+	case 0b00100:
+		f = func(a uint64) uint64 { return c.vfrsqrt7(a, sew) }
+	case 0b00101:
+		f = func(a uint64) uint64 { return c.vfrec7(a, sew) }
+	case 0b10000:
+		f = func(a uint64) uint64 {
+			if sew == 32 {
+				return FClassS(math.Float32frombits(uint32(a)))
+			}
+			return FClassD(math.Float64frombits(a))
+		}
+	default:
+		return ErrAbnormalInstruction
+	}
+	if err := s.groups(s.lmul8, s.lmul8, 0); err != nil {
+		return err
+	}
+	for j := s.vstart; j < s.vl; j++ {
+		if s.active(j) {
+			s.dst.set(s.vd, sew, j, f(s.src.get(s.vs2, sew, j)))
+		}
+	}
+	return nil
+}
+
+// vrsqrt7 holds the 7 bits of the significand of 1/sqrt(x), indexed by the lowest bit of the exponent of x and the 6
+// highest bits of its significand.
+var vrsqrt7 = [128]uint8{
+	52, 51, 50, 48, 47, 46, 44, 43, 42, 41, 40, 39, 38, 36, 35, 34,
+	33, 32, 31, 30, 30, 29, 28, 27, 26, 25, 24, 23, 23, 22, 21, 20,
+	19, 19, 18, 17, 16, 16, 15, 14, 14, 13, 12, 12, 11, 10, 10, 9,
+	9, 8, 7, 7, 6, 6, 5, 4, 4, 3, 3, 2, 2, 1, 1, 0,
+	127, 125, 123, 121, 119, 118, 116, 114, 113, 111, 109, 108, 106, 105, 103, 102,
+	100, 99, 97, 96, 95, 93, 92, 91, 90, 88, 87, 86, 85, 84, 83, 82,
+	80, 79, 78, 77, 76, 75, 74, 73, 72, 71, 70, 70, 69, 68, 67, 66,
+	65, 64, 63, 63, 62, 61, 60, 59, 59, 58, 57, 56, 56, 55, 54, 53,
+}
+
+// vrec7 holds the 7 bits of the significand of 1/x, indexed by the 7 highest bits of the significand of x.
+var vrec7 = [128]uint8{
+	127, 125, 123, 121, 119, 117, 116, 114, 112, 110, 109, 107, 105, 104, 102, 100,
+	99, 97, 96, 94, 93, 91, 90, 88, 87, 85, 84, 83, 81, 80, 79, 77,
+	76, 75, 74, 72, 71, 70, 69, 68, 66, 65, 64, 63, 62, 61, 60, 59,
+	58, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 47, 46, 45, 44, 43,
+	42, 41, 40, 40, 39, 38, 37, 36, 35, 35, 34, 33, 32, 31, 31, 30,
+	29, 28, 28, 27, 26, 25, 25, 24, 23, 23, 22, 21, 21, 20, 19, 19,
+	18, 17, 17, 16, 15, 15, 14, 14, 13, 12, 12, 11, 11, 10, 9, 9,
+	8, 8, 7, 7, 6, 5, 5, 4, 4, 3, 3, 2, 2, 1, 1, 0,
+}
+
+// vfunpack splits a sew-bit value into its sign, biased exponent and fraction. Subnormal values are normalized, their
+// exponent becomes zero or negative and the leading one is dropped from the fraction.
+func vfunpack(a uint64, sew uint64) (uint64, int64, uint64) {
+	f := vformat(sew)
+	e, s := uint64(f.Exp), uint64(f.Frac)
+	sign := a >> (e + s) & 1
+	exp := int64(a >> s & (1<<e - 1))
+	sig := a & (1<<s - 1)
+	if exp == 0 && sig != 0 {
+		for sig>>(s-1) == 0 {
+			exp--
+			sig <<= 1
+		}
+		sig = sig << 1 & (1<<s - 1)
+	}
+	return sign, exp, sig
+}
+
+// vfrsqrt7 estimates 1/sqrt(a) to 7 bits.
+func (c *CPU) vfrsqrt7(a uint64, sew uint64) uint64 {
+	f := vformat(sew)
+	e, s := uint64(f.Exp), uint64(f.Frac)
+	inf := (uint64(1)<<e - 1) << s
+	sign, exp, sig := vfunpack(a, sew)
+	switch {
+	case a&^(1<<(e+s)) > inf:
+		c.vfcheck(sew, a)
+		return c.vfbits(math.NaN(), sew, true)
+	case a&^(1<<(e+s)) == 0:
+		c.SetFloatFlag(FFlagsDZ, 1)
+		return sign<<(e+s) | inf
+	case sign == 1:
+		c.SetFloatFlag(FFlagsNV, 1)
+		return c.vfbits(math.NaN(), sew, true)
+	case a == inf:
+		return 0
+	}
+	b := int64(1)<<(e-1) - 1
+	i := uint64(exp&1)<<6 | sig>>(s-6)
+	return uint64((3*b-1-exp)/2)<<s | uint64(vrsqrt7[i])<<(s-7)
+}
+
+// vfrec7 estimates 1/a to 7 bits. Results below the normal range are subnormal. The reciprocal of the smallest
+// subnormals is not finite, it is the infinity or the largest finite value frm rounds to, with the overflow and
+// inexact flags.
+func (c *CPU) vfrec7(a uint64, sew uint64) uint64 {
+	f := vformat(sew)
+	e, s := uint64(f.Exp), uint64(f.Frac)
+	inf := (uint64(1)<<e - 1) << s
+	sign, exp, sig := vfunpack(a, sew)
+	switch {
+	case a&^(1<<(e+s)) > inf:
+		c.vfcheck(sew, a)
+		return c.vfbits(math.NaN(), sew, true)
+	case a&^(1<<(e+s)) == inf:
+		return sign << (e + s)
+	case a&^(1<<(e+s)) == 0:
+		c.SetFloatFlag(FFlagsDZ, 1)
+		return sign<<(e+s) | inf
+	case exp < -1:
+		c.SetFloatFlag(FFlagsOF|FFlagsNX, 1)
+		switch rm := c.GetCSR().Get(CSRfrm); {
+		case rm == FRoundRTZ, rm == FRoundRDN && sign == 0, rm == FRoundRUP && sign == 1:
+			return sign<<(e+s) | inf - 1
+		}
+		return sign<<(e+s) | inf
+	}
+	b := int64(1)<<(e-1) - 1
+	r := 2*b - 1 - exp
+	m := uint64(vrec7[sig>>(s-7)]) << (s - 7)
+	switch r {
+	case 0:
+		m = m>>1 | 1<<(s-1)
+	case -1:
+		m = (m>>1 | 1<<(s-1)) >> 1
+		r = 0
+	}
+	return sign<<(e+s) | uint64(r)<<s | m
+}
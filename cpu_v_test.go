@@ -0,0 +1,556 @@
+package rv64
+
+import (
+	"math"
+	"testing"
+)
+
+func newVectorCPU() *CPU {
+	c := NewCPU()
+	c.SetFasten(NewLinear(0x1000))
+	c.SetCSR(NewCSRStandard())
+	return c
+}
+
+func encodeV(funct6 uint64, vm uint64, vs2 uint64, vs1 uint64, funct3 uint64, vd uint64) uint64 {
+	return funct6<<26 | vm<<25 | vs2<<20 | vs1<<15 | funct3<<12 | vd<<7 | 0b1010111
+}
+
+func encodeVMem(opcode uint64, nf uint64, mop uint64, vm uint64, rs2 uint64, rs1 uint64, width uint64, vd uint64) uint64 {
+	return nf<<29 | mop<<26 | vm<<25 | rs2<<20 | rs1<<15 | width<<12 | vd<<7 | opcode
+}
+
+// vsetvli sets vl to avl with the given SEW and LMUL×8.
+func vsetvli(t *testing.T, c *CPU, avl uint64, sew uint64, lmul8 uint64) {
+	vtype := map[uint64]uint64{8: 0, 16: 1, 32: 2, 64: 3}[sew] << 3
+	vtype |= map[uint64]uint64{1: 5, 2: 6, 4: 7, 8: 0, 16: 1, 32: 2, 64: 3}[lmul8]
+	c.SetRegister(Rt0, avl)
+	if err := execute(c, encodeI(0b1010111, 0b111, vtype, Rt1, Rt0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func vexecute(t *testing.T, c *CPU, i uint64) {
+	if err := execute(c, i); err != nil {
+		t.Fatalf("%#08x: %s", i, err)
+	}
+}
+
+func vset(c *CPU, r uint64, sew uint64, e ...uint64) {
+	for j, u := range e {
+		c.vfile().set(r, sew, uint64(j), u)
+	}
+}
+
+func vcheck(t *testing.T, c *CPU, r uint64, sew uint64, e ...uint64) {
+	t.Helper()
+	for j, u := range e {
+		if v := c.vfile().get(r, sew, uint64(j)); v != u {
+			t.Fatalf("v%d[%d]: %#x != %#x", r, j, v, u)
+		}
+	}
+}
+
+func TestVectorConfiguration(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 10, 32, 8)
+	if c.GetRegister(Rt1) != 4 || c.GetCSR().Get(CSRvl) != 4 {
+		t.FailNow()
+	}
+	vsetvli(t, c, 100, 8, 64)
+	if c.GetRegister(Rt1) != 100 {
+		t.FailNow()
+	}
+	// AVL is VLMAX when rs1 is x0 and rd is not.
+	vexecute(t, c, encodeI(0b1010111, 0b111, 0b000_011, Ra0, Rzero))
+	if c.GetRegister(Ra0) != 128 {
+		t.FailNow()
+	}
+	// vsetivli
+	vexecute(t, c, 0b11<<30|0b010_000<<20|3<<15|0b111<<12|Ra0<<7|0b1010111)
+	if c.GetRegister(Ra0) != 3 || c.GetCSR().Get(CSRvtype) != 0b010_000 {
+		t.FailNow()
+	}
+	// SEW=64 is not supported with LMUL=1/8.
+	vsetvli(t, c, 4, 64, 1)
+	if c.GetCSR().Get(CSRvtype) != 1<<63 || c.GetCSR().Get(CSRvl) != 0 {
+		t.FailNow()
+	}
+	if err := execute(c, encodeV(0b000000, 1, 2, 3, vOPIVV, 1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	// vsetvl takes vtype from rs2.
+	c.SetRegister(Ra1, 0b011_001)
+	c.SetRegister(Ra2, 7)
+	vexecute(t, c, 0b1000000<<25|Ra1<<20|Ra2<<15|0b111<<12|Ra0<<7|0b1010111)
+	if c.GetRegister(Ra0) != 4 {
+		t.FailNow()
+	}
+	// csrr a0, vlenb
+	vexecute(t, c, encodeI(0b1110011, 0b010, CSRvlenb, Ra0, Rzero))
+	if c.GetRegister(Ra0) != 16 {
+		t.FailNow()
+	}
+	c.SetVLEN(256)
+	vexecute(t, c, encodeI(0b1110011, 0b010, CSRvlenb, Ra0, Rzero))
+	if c.GetRegister(Ra0) != 32 {
+		t.FailNow()
+	}
+	// vcsr holds vxrm and vxsat.
+	c.GetCSR().Set(CSRvxrm, 2)
+	c.GetCSR().Set(CSRvxsat, 1)
+	if c.GetCSR().Get(CSRvcsr) != 5 {
+		t.FailNow()
+	}
+	c.GetCSR().Set(CSRvcsr, 2)
+	if c.GetCSR().Get(CSRvxrm) != 1 || c.GetCSR().Get(CSRvxsat) != 0 {
+		t.FailNow()
+	}
+	// Disabled extension.
+	c.SetISA(c.GetISA() &^ ISAV)
+	if err := execute(c, encodeI(0b1010111, 0b111, 0, Ra0, Rzero)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestVectorLoadStore(t *testing.T) {
+	c := newVectorCPU()
+	mem := c.GetMemory()
+	for i := uint64(0); i < 64; i++ {
+		mem.SetUint8(0x100+i, uint8(i))
+	}
+	c.SetRegister(Ra0, 0x100)
+	c.SetRegister(Ra1, 0x200)
+	c.SetRegister(Ra2, 8)
+	vsetvli(t, c, 4, 32, 8)
+	// vle32.v v1, (a0)
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b00, 1, 0, Ra0, 0b110, 1))
+	vcheck(t, c, 1, 32, 0x03020100, 0x07060504, 0x0b0a0908, 0x0f0e0d0c)
+	// vlse32.v v2, (a0), a2
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b10, 1, Ra2, Ra0, 0b110, 2))
+	vcheck(t, c, 2, 32, 0x03020100, 0x0b0a0908, 0x13121110, 0x1b1a1918)
+	// vluxei8.v v3, (a0), v4
+	vset(c, 4, 8, 12, 0, 4, 40)
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b01, 1, 4, Ra0, 0b000, 3))
+	vcheck(t, c, 3, 32, 0x0f0e0d0c, 0x03020100, 0x07060504, 0x2b2a2928)
+	// vlseg2e16.v v6, (a0)
+	vsetvli(t, c, 3, 16, 8)
+	vexecute(t, c, encodeVMem(0b0000111, 1, 0b00, 1, 0, Ra0, 0b101, 6))
+	vcheck(t, c, 6, 16, 0x0100, 0x0504, 0x0908)
+	vcheck(t, c, 7, 16, 0x0302, 0x0706, 0x0b0a)
+	// Masked vle8.v v8, (a0), v0.t leaves inactive elements undisturbed.
+	vsetvli(t, c, 8, 8, 8)
+	vset(c, 0, 8, 0b10100101)
+	vset(c, 8, 8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b00, 0, 0, Ra0, 0b000, 8))
+	vcheck(t, c, 8, 8, 0x00, 0xff, 0x02, 0xff, 0xff, 0x05, 0xff, 0x07)
+	// vsse32.v v1, (a1), a2
+	vsetvli(t, c, 4, 32, 8)
+	vexecute(t, c, encodeVMem(0b0100111, 0, 0b10, 1, Ra2, Ra1, 0b110, 1))
+	if v, _ := mem.GetUint32(0x218); v != 0x0f0e0d0c {
+		t.FailNow()
+	}
+	// vsuxei8.v v1, (a1), v4
+	vexecute(t, c, encodeVMem(0b0100111, 0, 0b01, 1, 4, Ra1, 0b000, 1))
+	if v, _ := mem.GetUint32(0x228); v != 0x0f0e0d0c {
+		t.FailNow()
+	}
+	// vlm.v v9, (a0) loads ceil(vl/8) bytes.
+	vsetvli(t, c, 9, 8, 8)
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b00, 1, 0b01011, Ra0, 0b000, 9))
+	vcheck(t, c, 9, 8, 0x00, 0x01, 0x00)
+	// vl2re64.v v10, (a0) and vs1r.v v10, (a1)
+	vexecute(t, c, encodeVMem(0b0000111, 1, 0b00, 1, 0b01000, Ra0, 0b111, 10))
+	vcheck(t, c, 11, 64, 0x1716151413121110, 0x1f1e1d1c1b1a1918)
+	vexecute(t, c, encodeVMem(0b0100111, 0, 0b00, 1, 0b01000, Ra1, 0b000, 10))
+	if v, _ := mem.GetUint64(0x208); v != 0x0f0e0d0c0b0a0908 {
+		t.FailNow()
+	}
+	// Misaligned register group.
+	vsetvli(t, c, 4, 32, 16)
+	if err := execute(c, encodeVMem(0b0000111, 0, 0b00, 1, 0, Ra0, 0b110, 1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestVectorFaultOnlyFirst(t *testing.T) {
+	c := newVectorCPU()
+	c.SetRegister(Ra0, 0x1000-8)
+	vsetvli(t, c, 4, 32, 8)
+	// vle32ff.v v1, (a0)
+	vexecute(t, c, encodeVMem(0b0000111, 0, 0b00, 1, 0b10000, Ra0, 0b110, 1))
+	if c.GetCSR().Get(CSRvl) != 2 {
+		t.FailNow()
+	}
+	// A fault on the first element traps.
+	c.SetRegister(Ra0, 0x1000)
+	if err := execute(c, encodeVMem(0b0000111, 0, 0b00, 1, 0b10000, Ra0, 0b110, 1)); err != ErrOutOfMemory {
+		t.FailNow()
+	}
+	// Other loads trap on any element and record it in vstart.
+	c.SetRegister(Ra0, 0x1000-4)
+	if err := execute(c, encodeVMem(0b0000111, 0, 0b00, 1, 0, Ra0, 0b110, 1)); err != ErrOutOfMemory {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRvstart) != 1 {
+		t.FailNow()
+	}
+}
+
+func TestVectorInteger(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 4, 32, 8)
+	vset(c, 2, 32, 1, 0xffffffff, 0x80000000, 7)
+	vset(c, 3, 32, 2, 1, 0x80000000, 0)
+	c.SetRegister(Ra0, 3)
+	// vadd.vv, vadd.vi, vrsub.vx
+	vexecute(t, c, encodeV(0b000000, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 3, 0, 0, 7)
+	vexecute(t, c, encodeV(0b000000, 1, 2, 0b11111, vOPIVI, 1))
+	vcheck(t, c, 1, 32, 0, 0xfffffffe, 0x7fffffff, 6)
+	vexecute(t, c, encodeV(0b000011, 1, 2, Ra0, vOPIVX, 1))
+	vcheck(t, c, 1, 32, 2, 4, 0x80000003, 0xfffffffc)
+	// vmin.vv, vmaxu.vv
+	vexecute(t, c, encodeV(0b000101, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 1, 0xffffffff, 0x80000000, 0)
+	vexecute(t, c, encodeV(0b000110, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 2, 0xffffffff, 0x80000000, 7)
+	// vsra.vi uses an unsigned immediate.
+	vexecute(t, c, encodeV(0b101001, 1, 2, 31, vOPIVI, 1))
+	vcheck(t, c, 1, 32, 0, 0xffffffff, 0xffffffff, 0)
+	// vmulh.vv, vmulhu.vv, vdiv.vv, vremu.vv
+	vexecute(t, c, encodeV(0b100111, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 0, 0xffffffff, 0x40000000, 0)
+	vexecute(t, c, encodeV(0b100100, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 0, 0, 0x40000000, 0)
+	vexecute(t, c, encodeV(0b100001, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 0, 0xffffffff, 1, 0xffffffff)
+	vexecute(t, c, encodeV(0b100010, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 1, 0, 0, 7)
+	// vmacc.vv
+	vset(c, 1, 32, 1, 1, 1, 1)
+	vexecute(t, c, encodeV(0b101101, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 3, 0, 1, 1)
+	// Masked vadd.vv leaves inactive elements undisturbed, tail elements are undisturbed too.
+	vset(c, 0, 8, 0b0101)
+	vset(c, 1, 32, 9, 9, 9, 9)
+	vsetvli(t, c, 3, 32, 8)
+	vexecute(t, c, encodeV(0b000000, 0, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 3, 9, 0, 9)
+	// vmslt.vv, vmseq.vi write masks.
+	vsetvli(t, c, 4, 32, 8)
+	vset(c, 4, 8, 0xf0)
+	vexecute(t, c, encodeV(0b011011, 1, 2, 3, vOPIVV, 4))
+	vcheck(t, c, 4, 8, 0xf3)
+	vexecute(t, c, encodeV(0b011000, 1, 2, 7, vOPIVI, 4))
+	vcheck(t, c, 4, 8, 0xf8)
+	// vadc.vvm and vmadc.vvm
+	vset(c, 0, 8, 0b1111)
+	vexecute(t, c, encodeV(0b010000, 0, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 4, 1, 1, 8)
+	vexecute(t, c, encodeV(0b010001, 0, 2, 3, vOPIVV, 4))
+	vcheck(t, c, 4, 8, 0xf6)
+	// vwaddu.vv and vwmul.vx
+	vexecute(t, c, encodeV(0b110000, 1, 2, 3, vOPMVV, 8))
+	vcheck(t, c, 8, 64, 3, 0x100000000, 0x100000000, 7)
+	c.SetRegister(Ra0, 0xfffffffe)
+	vexecute(t, c, encodeV(0b111011, 1, 2, Ra0, vOPMVX, 8))
+	vcheck(t, c, 8, 64, 0xfffffffffffffffe, 2, 0x100000000, 0xfffffffffffffff2)
+	// vnsrl.wi
+	vexecute(t, c, encodeV(0b101100, 1, 8, 16, vOPIVI, 1))
+	vcheck(t, c, 1, 32, 0xffffffff, 0, 0x00010000, 0xffffffff)
+	// vzext.vf2 and vsext.vf4
+	vset(c, 5, 16, 0x8000, 1, 0xffff, 2)
+	vexecute(t, c, encodeV(0b010010, 1, 5, 0b00110, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 0x8000, 1, 0xffff, 2)
+	vset(c, 5, 8, 0x80, 1, 0xff, 2)
+	vexecute(t, c, encodeV(0b010010, 1, 5, 0b00101, vOPMVV, 1))
+	vcheck(t, c, 1, 32, 0xffffff80, 1, 0xffffffff, 2)
+	// vmv.v.x, vmv.x.s and vmv.s.x
+	c.SetRegister(Ra0, 0x12345678)
+	vexecute(t, c, encodeV(0b010111, 1, 0, Ra0, vOPIVX, 1))
+	vcheck(t, c, 1, 32, 0x12345678, 0x12345678, 0x12345678, 0x12345678)
+	vexecute(t, c, encodeV(0b010000, 1, 2, 0, vOPMVV, Ra1))
+	if c.GetRegister(Ra1) != 1 {
+		t.FailNow()
+	}
+	vexecute(t, c, encodeV(0b010000, 1, 0, Ra0, vOPMVX, 3))
+	vcheck(t, c, 3, 32, 0x12345678, 1)
+	// vmv2r.v
+	vexecute(t, c, encodeV(0b100111, 1, 2, 1, vOPIVI, 10))
+	vcheck(t, c, 10, 32, 1, 0xffffffff, 0x80000000, 7)
+	vcheck(t, c, 11, 32, 0x12345678, 1, 0x80000000, 0)
+}
+
+func TestVectorFixedPoint(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 4, 8, 8)
+	vset(c, 2, 8, 0xf0, 0x7f, 0x80, 0x05)
+	vset(c, 3, 8, 0x20, 0x01, 0xff, 0x02)
+	// vsaddu.vv
+	vexecute(t, c, encodeV(0b100000, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 8, 0xff, 0x80, 0xff, 0x07)
+	if c.GetCSR().Get(CSRvxsat) != 1 {
+		t.FailNow()
+	}
+	// vsadd.vv
+	c.GetCSR().Set(CSRvxsat, 0)
+	vexecute(t, c, encodeV(0b100001, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 8, 0x10, 0x7f, 0x80, 0x07)
+	if c.GetCSR().Get(CSRvxsat) != 1 {
+		t.FailNow()
+	}
+	// vaaddu.vv under every rounding mode, 0x80 + 0xff = 0x17f and 0x05 + 0x02 = 7 are shifted right by one.
+	for vxrm, r := range [][2]uint64{{0xc0, 4}, {0xc0, 4}, {0xbf, 3}, {0xbf, 3}} {
+		c.GetCSR().Set(CSRvxrm, uint64(vxrm))
+		vexecute(t, c, encodeV(0b001000, 1, 2, 3, vOPMVV, 1))
+		vcheck(t, c, 1, 8, 0x88, 0x40, r[0], r[1])
+	}
+	// vasub.vv, -128 - -1 = -127
+	c.GetCSR().Set(CSRvxrm, 0)
+	vexecute(t, c, encodeV(0b001011, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 8, 0xe8, 0x3f, 0xc1, 0x02)
+	// vsmul.vv, -128 × -128 saturates.
+	vset(c, 3, 8, 0x40, 0x7f, 0x80, 0x40)
+	vset(c, 2, 8, 0x40, 0x7f, 0x80, 0xc0)
+	vexecute(t, c, encodeV(0b100111, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 8, 0x20, 0x7e, 0x7f, 0xe0)
+	// vssrl.vi with round-to-odd.
+	c.GetCSR().Set(CSRvxrm, 3)
+	vset(c, 2, 8, 0x04, 0x05, 0x06, 0x07)
+	vexecute(t, c, encodeV(0b101010, 1, 2, 2, vOPIVI, 1))
+	vcheck(t, c, 1, 8, 0x01, 0x01, 0x01, 0x01)
+	// vnclipu.wi saturates.
+	c.GetCSR().Set(CSRvxrm, 0)
+	vset(c, 4, 16, 0x0100, 0x00ff, 0x0180, 0x017f)
+	vexecute(t, c, encodeV(0b101110, 1, 4, 1, vOPIVI, 1))
+	vcheck(t, c, 1, 8, 0x80, 0x80, 0xc0, 0xc0)
+	vexecute(t, c, encodeV(0b101110, 1, 4, 0, vOPIVI, 1))
+	vcheck(t, c, 1, 8, 0xff, 0xff, 0xff, 0xff)
+	// 64-bit vsmul.vv
+	vsetvli(t, c, 2, 64, 8)
+	vset(c, 2, 64, 0x4000000000000000, 0x8000000000000000)
+	vset(c, 3, 64, 0xc000000000000000, 0x8000000000000000)
+	vexecute(t, c, encodeV(0b100111, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 64, 0xe000000000000000, 0x7fffffffffffffff)
+}
+
+func TestVectorMaskPermutation(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 8, 16, 8)
+	vset(c, 2, 16, 10, 11, 12, 13, 14, 15, 16, 17)
+	// vredsum.vs and vredmaxu.vs
+	vset(c, 3, 16, 100)
+	vexecute(t, c, encodeV(0b000000, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 16, 208)
+	vexecute(t, c, encodeV(0b000110, 1, 2, 3, vOPMVV, 1))
+	vcheck(t, c, 1, 16, 100)
+	// vwredsumu.vs
+	vset(c, 4, 32, 1)
+	vexecute(t, c, encodeV(0b110000, 1, 2, 4, vOPIVV, 1))
+	vcheck(t, c, 1, 32, 109)
+	// vslideup.vi, vslidedown.vi
+	vset(c, 1, 16, 0, 0, 0, 0, 0, 0, 0, 0)
+	vexecute(t, c, encodeV(0b001110, 1, 2, 3, vOPIVI, 1))
+	vcheck(t, c, 1, 16, 0, 0, 0, 10, 11, 12, 13, 14)
+	vexecute(t, c, encodeV(0b001111, 1, 2, 6, vOPIVI, 1))
+	vcheck(t, c, 1, 16, 16, 17, 0, 0, 0, 0, 0, 0)
+	// vslide1up.vx, vslide1down.vx
+	c.SetRegister(Ra0, 99)
+	vexecute(t, c, encodeV(0b001110, 1, 2, Ra0, vOPMVX, 1))
+	vcheck(t, c, 1, 16, 99, 10, 11, 12, 13, 14, 15, 16)
+	vexecute(t, c, encodeV(0b001111, 1, 2, Ra0, vOPMVX, 1))
+	vcheck(t, c, 1, 16, 11, 12, 13, 14, 15, 16, 17, 99)
+	// vrgather.vv and vrgather.vx
+	vset(c, 3, 16, 7, 0, 100, 3, 3, 3, 3, 3)
+	vexecute(t, c, encodeV(0b001100, 1, 2, 3, vOPIVV, 1))
+	vcheck(t, c, 1, 16, 17, 10, 0, 13, 13, 13, 13, 13)
+	c.SetRegister(Ra0, 5)
+	vexecute(t, c, encodeV(0b001100, 1, 2, Ra0, vOPIVX, 1))
+	vcheck(t, c, 1, 16, 15, 15, 15, 15, 15, 15, 15, 15)
+	// vcompress.vm
+	vset(c, 5, 8, 0b10100110)
+	vexecute(t, c, encodeV(0b010111, 1, 2, 5, vOPMVV, 1))
+	vcheck(t, c, 1, 16, 11, 12, 15, 17)
+	// vcpop.m and vfirst.m
+	vexecute(t, c, encodeV(0b010000, 1, 5, 0b10000, vOPMVV, Ra0))
+	if c.GetRegister(Ra0) != 4 {
+		t.FailNow()
+	}
+	vexecute(t, c, encodeV(0b010000, 1, 5, 0b10001, vOPMVV, Ra0))
+	if c.GetRegister(Ra0) != 1 {
+		t.FailNow()
+	}
+	vset(c, 6, 8, 0)
+	vexecute(t, c, encodeV(0b010000, 1, 6, 0b10001, vOPMVV, Ra0))
+	if c.GetRegister(Ra0) != math.MaxUint64 {
+		t.FailNow()
+	}
+	// vmsbf.m, vmsif.m, vmsof.m
+	vexecute(t, c, encodeV(0b010100, 1, 5, 0b00001, vOPMVV, 6))
+	vcheck(t, c, 6, 8, 0b00000001)
+	vexecute(t, c, encodeV(0b010100, 1, 5, 0b00011, vOPMVV, 6))
+	vcheck(t, c, 6, 8, 0b00000011)
+	vexecute(t, c, encodeV(0b010100, 1, 5, 0b00010, vOPMVV, 6))
+	vcheck(t, c, 6, 8, 0b00000010)
+	// viota.m and vid.v
+	vexecute(t, c, encodeV(0b010100, 1, 5, 0b10000, vOPMVV, 1))
+	vcheck(t, c, 1, 16, 0, 0, 1, 2, 2, 2, 3, 3)
+	vexecute(t, c, encodeV(0b010100, 1, 0, 0b10001, vOPMVV, 1))
+	vcheck(t, c, 1, 16, 0, 1, 2, 3, 4, 5, 6, 7)
+	// vmandn.mm, vmxnor.mm
+	vset(c, 6, 8, 0b11110000)
+	vexecute(t, c, encodeV(0b011000, 1, 5, 6, vOPMVV, 7))
+	vcheck(t, c, 7, 8, 0b00000110)
+	vexecute(t, c, encodeV(0b011111, 1, 5, 6, vOPMVV, 7))
+	vcheck(t, c, 7, 8, 0b10101001)
+}
+
+func TestVectorFloat(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 4, 32, 8)
+	f32 := func(f float32) uint64 { return uint64(math.Float32bits(f)) }
+	f64 := func(f float64) uint64 { return math.Float64bits(f) }
+	vset(c, 2, 32, f32(1.5), f32(-2), f32(float32(math.Inf(1))), f32(0))
+	vset(c, 3, 32, f32(0.25), f32(3), f32(float32(math.Inf(1))), f32(-0))
+	// vfadd.vf
+	c.SetRegisterFloatAsFloat32(Rfa0, 2)
+	vexecute(t, c, encodeV(0b000000, 1, 2, Rfa0, vOPFVF, 1))
+	vcheck(t, c, 1, 32, f32(3.5), f32(0), f32(float32(math.Inf(1))), f32(2))
+	// vfsub.vv, inf - inf is invalid.
+	vexecute(t, c, encodeV(0b000010, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(1.25), f32(-5), uint64(NaN32), f32(0))
+	if c.GetCSR().Get(CSRfflags) != FFlagsNV {
+		t.FailNow()
+	}
+	// vfdiv.vv by zero.
+	c.GetCSR().Set(CSRfflags, 0)
+	vset(c, 3, 32, f32(0.25), f32(3), f32(1), f32(0))
+	vset(c, 2, 32, f32(1), f32(-3), f32(2), f32(1))
+	vexecute(t, c, encodeV(0b100000, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(4), f32(-1), f32(2), f32(float32(math.Inf(1))))
+	if c.GetCSR().Get(CSRfflags) != FFlagsDZ {
+		t.FailNow()
+	}
+	// vfmacc.vv
+	vset(c, 1, 32, f32(1), f32(1), f32(1), f32(1))
+	vexecute(t, c, encodeV(0b101100, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(1.25), f32(-8), f32(3), f32(1))
+	// vfmin.vv orders -0 before +0.
+	vset(c, 2, 32, f32(float32(math.Copysign(0, -1))), uint64(NaN32))
+	vset(c, 3, 32, f32(0), f32(5))
+	vexecute(t, c, encodeV(0b000100, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(float32(math.Copysign(0, -1))), f32(5))
+	// vmflt.vv
+	vset(c, 2, 32, f32(1), f32(2), f32(3), f32(4))
+	vset(c, 3, 32, f32(2), f32(2), f32(2), uint64(NaN32))
+	vset(c, 4, 8, 0)
+	vexecute(t, c, encodeV(0b011011, 1, 2, 3, vOPFVV, 4))
+	vcheck(t, c, 4, 8, 0b0001)
+	// vfredosum.vs
+	vset(c, 3, 32, f32(10))
+	vexecute(t, c, encodeV(0b000011, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(20))
+	// vfcvt.x.f.v honours frm, vfcvt.rtz.x.f.v truncates.
+	vset(c, 2, 32, f32(2.5), f32(-2.5), f32(1e10), f32(-0.5))
+	c.GetCSR().Set(CSRfrm, 0b011)
+	vexecute(t, c, encodeV(0b010010, 1, 2, 0b00001, vOPFVV, 1))
+	vcheck(t, c, 1, 32, 3, 0xfffffffe, 0x7fffffff, 0)
+	vexecute(t, c, encodeV(0b010010, 1, 2, 0b00111, vOPFVV, 1))
+	vcheck(t, c, 1, 32, 2, 0xfffffffe, 0x7fffffff, 0)
+	// vfwcvt.f.f.v and vfncvt.f.f.w
+	vexecute(t, c, encodeV(0b010010, 1, 2, 0b01100, vOPFVV, 8))
+	vcheck(t, c, 8, 64, f64(2.5), f64(-2.5), f64(1e10), f64(-0.5))
+	vexecute(t, c, encodeV(0b010010, 1, 8, 0b10100, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(2.5), f32(-2.5), f32(1e10), f32(-0.5))
+	// vfwmul.vf
+	c.SetRegisterFloatAsFloat32(Rfa0, 3)
+	vexecute(t, c, encodeV(0b111000, 1, 2, Rfa0, vOPFVF, 8))
+	vcheck(t, c, 8, 64, f64(7.5), f64(-7.5), f64(3e10), f64(-1.5))
+	// vfsqrt.v of a negative number is invalid.
+	c.GetCSR().Set(CSRfflags, 0)
+	vset(c, 2, 32, f32(4), f32(-1))
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00000, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(2), uint64(NaN32))
+	if c.GetCSR().Get(CSRfflags) != FFlagsNV {
+		t.FailNow()
+	}
+	// vfmv.f.s NaN-boxes the element, vfmv.s.f checks the NaN-boxing.
+	vexecute(t, c, encodeV(0b010000, 1, 2, 0, vOPFVV, Rfa1))
+	if c.GetRegisterFloat(Rfa1) != 0xffffffff00000000|f32(4) {
+		t.FailNow()
+	}
+	c.SetRegisterFloat(Rfa1, f64(1))
+	vexecute(t, c, encodeV(0b010000, 1, 0, Rfa1, vOPFVF, 2))
+	vcheck(t, c, 2, 32, uint64(NaN32))
+	// Floating-point operations are not supported for SEW=16.
+	vsetvli(t, c, 4, 16, 8)
+	if err := execute(c, encodeV(0b000000, 1, 2, 3, vOPFVV, 1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestVectorFloatRounding(t *testing.T) {
+	c := newVectorCPU()
+	vsetvli(t, c, 2, 32, 8)
+	f32 := func(f float32) uint64 { return uint64(math.Float32bits(f)) }
+	// vfadd.vv rounds with frm and raises the inexact flag.
+	vset(c, 2, 32, f32(1), f32(-1))
+	vset(c, 3, 32, f32(0x1p-30), f32(-0x1p-30))
+	c.GetCSR().Set(CSRfrm, FRoundRUP)
+	vexecute(t, c, encodeV(0b000000, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(1+0x1p-23), f32(-1))
+	c.GetCSR().Set(CSRfrm, FRoundRTZ)
+	vexecute(t, c, encodeV(0b000000, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(1), f32(-1))
+	if c.GetCSR().Get(CSRfflags) != FFlagsNX {
+		t.Fatal(c.GetCSR().Get(CSRfflags))
+	}
+	// vfmul.vv overflows to the largest finite value towards zero, and to infinity to nearest.
+	c.GetCSR().Set(CSRfflags, 0)
+	vset(c, 2, 32, f32(math.MaxFloat32), f32(0x1p-100))
+	vset(c, 3, 32, f32(2), f32(0x1p-100))
+	vexecute(t, c, encodeV(0b100100, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(math.MaxFloat32), 0)
+	if c.GetCSR().Get(CSRfflags) != FFlagsOF|FFlagsUF|FFlagsNX {
+		t.Fatal(c.GetCSR().Get(CSRfflags))
+	}
+	c.GetCSR().Set(CSRfrm, FRoundRNE)
+	vexecute(t, c, encodeV(0b100100, 1, 2, 3, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(float32(math.Inf(1))), 0)
+	// vfrsqrt7.v
+	c.GetCSR().Set(CSRfflags, 0)
+	vset(c, 2, 32, f32(4), f32(float32(math.Inf(1))))
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00100, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(0.498046875), 0)
+	vset(c, 2, 32, f32(-1), 0)
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00100, vOPFVV, 1))
+	vcheck(t, c, 1, 32, uint64(NaN32), f32(float32(math.Inf(1))))
+	if c.GetCSR().Get(CSRfflags) != FFlagsNV|FFlagsDZ {
+		t.Fatal(c.GetCSR().Get(CSRfflags))
+	}
+	// vfrec7.v, with a subnormal result and the reciprocal of the smallest subnormal.
+	c.GetCSR().Set(CSRfflags, 0)
+	vset(c, 2, 32, f32(1), f32(float32(math.Inf(-1))))
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00101, vOPFVV, 1))
+	vcheck(t, c, 1, 32, f32(0.99609375), f32(float32(math.Copysign(0, -1))))
+	vset(c, 2, 32, f32(0x1p127), 1)
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00101, vOPFVV, 1))
+	vcheck(t, c, 1, 32, 0x003fc000, f32(float32(math.Inf(1))))
+	if c.GetCSR().Get(CSRfflags) != FFlagsOF|FFlagsNX {
+		t.Fatal(c.GetCSR().Get(CSRfflags))
+	}
+	c.GetCSR().Set(CSRfrm, FRoundRTZ)
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00101, vOPFVV, 1))
+	vcheck(t, c, 1, 32, 0x003fc000, f32(math.MaxFloat32))
+	// The same in double precision.
+	vsetvli(t, c, 1, 64, 8)
+	vset(c, 2, 64, math.Float64bits(4))
+	vexecute(t, c, encodeV(0b010011, 1, 2, 0b00100, vOPFVV, 1))
+	vcheck(t, c, 1, 64, math.Float64bits(0.498046875))
+	// A reserved rounding mode in frm is illegal.
+	c.GetCSR().Set(CSRfrm, 0b101)
+	if err := execute(c, encodeV(0b000000, 1, 2, 3, vOPFVV, 1)); err != ErrAbnormalInstruction {
+		t.Fatal(err)
+	}
+}
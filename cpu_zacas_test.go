@@ -0,0 +1,174 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func TestAtomicByteHalfword(t *testing.T) {
+	const op = 0b0101111
+	amo := func(funct5 uint64, funct3 uint64) uint64 { return encodeR(op, funct3, funct5<<2, Ra0, Ra1, Ra2) }
+	c := newCostCPU(nil, nil)
+	c.SetRegister(Ra1, 0x100)
+	for _, e := range []struct {
+		name string
+		i    uint64
+		m    uint64
+		b    uint64
+		r    uint64
+		n    uint64
+	}{
+		{"amoswap.b", amo(0b00001, 0b000), 0x80, 0x7f, 0xffffffffffffff80, 0x7f},
+		{"amoadd.b", amo(0b00000, 0b000), 0xff, 0x102, 0xffffffffffffffff, 0x01},
+		{"amoxor.b", amo(0b00100, 0b000), 0x0f, 0xff, 0x0f, 0xf0},
+		{"amoand.b", amo(0b01100, 0b000), 0x3c, 0x0f, 0x3c, 0x0c},
+		{"amoor.b", amo(0b01000, 0b000), 0x30, 0x03, 0x30, 0x33},
+		{"amomin.b", amo(0b10000, 0b000), 0x80, 0x01, 0xffffffffffffff80, 0x80},
+		{"amomax.b", amo(0b10100, 0b000), 0x80, 0x01, 0xffffffffffffff80, 0x01},
+		{"amominu.b", amo(0b11000, 0b000), 0x80, 0x01, 0xffffffffffffff80, 0x01},
+		{"amomaxu.b", amo(0b11100, 0b000), 0x80, 0x01, 0xffffffffffffff80, 0x80},
+		{"amoswap.h", amo(0b00001, 0b001), 0x8000, 0x1234, 0xffffffffffff8000, 0x1234},
+		{"amoadd.h", amo(0b00000, 0b001), 0xffff, 0x2, 0xffffffffffffffff, 0x1},
+		{"amomin.h", amo(0b10000, 0b001), 0x7fff, 0x8000, 0x7fff, 0x8000},
+		{"amomaxu.h", amo(0b11100, 0b001), 0x7fff, 0x8000, 0x7fff, 0x8000},
+	} {
+		// The neighbouring bytes must not be touched.
+		c.GetMemory().SetUint64(0x100, 0x5a5a5a5a5a5a0000|e.m)
+		c.SetRegister(Ra2, e.b)
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if v := c.GetRegister(Ra0); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+		if v, _ := c.GetMemory().GetUint64(0x100); v != 0x5a5a5a5a5a5a0000|e.n {
+			t.Fatalf("%s: memory %#x", e.name, v)
+		}
+	}
+	c.SetISA(c.GetISA() &^ ISAZabha)
+	if err := execute(c, amo(0b00000, 0b000)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestAtomicCompareAndSwap(t *testing.T) {
+	const op = 0b0101111
+	cas := func(funct3 uint64, rd uint64, rs2 uint64) uint64 {
+		return encodeR(op, funct3, 0b00101<<2, rd, Ra1, rs2)
+	}
+	c := newCostCPU(nil, nil)
+	c.SetRegister(Ra1, 0x100)
+	// amocas.w, a failed comparison leaves memory alone.
+	c.GetMemory().SetUint64(0x100, 0x1111111180000000)
+	c.SetRegister(Ra0, 0)
+	c.SetRegister(Ra2, 42)
+	if err := execute(c, cas(0b010, Ra0, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint64(0x100); v != 0x1111111180000000 || c.GetRegister(Ra0) != 0xffffffff80000000 {
+		t.FailNow()
+	}
+	if err := execute(c, cas(0b010, Ra0, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint64(0x100); v != 0x111111110000002a || c.GetRegister(Ra0) != 0xffffffff80000000 {
+		t.FailNow()
+	}
+	// amocas.d
+	c.SetRegister(Ra0, 0x111111110000002a)
+	c.SetRegister(Ra2, 0xffffffffffffffff)
+	if err := execute(c, cas(0b011, Ra0, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint64(0x100); v != 0xffffffffffffffff || c.GetRegister(Ra0) != 0x111111110000002a {
+		t.FailNow()
+	}
+	// amocas.q a4, a2, (a1) compares a4:a5 and swaps in a2:a3.
+	c.GetMemory().SetUint64(0x108, 0x2222)
+	c.SetRegister(Ra2, 0x3333)
+	c.SetRegister(Ra3, 0x4444)
+	if err := execute(c, encodeR(op, 0b100, 0b00101<<2, Ra4, Ra1, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegister(Ra4) != 0xffffffffffffffff || c.GetRegister(Ra5) != 0x2222 {
+		t.FailNow()
+	}
+	if v, _ := c.GetMemory().GetUint64(0x100); v != 0xffffffffffffffff {
+		t.FailNow()
+	}
+	if err := execute(c, encodeR(op, 0b100, 0b00101<<2, Ra4, Ra1, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	lo, _ := c.GetMemory().GetUint64(0x100)
+	hi, _ := c.GetMemory().GetUint64(0x108)
+	if lo != 0x3333 || hi != 0x4444 {
+		t.FailNow()
+	}
+	// The register pairs must be even.
+	if err := execute(c, encodeR(op, 0b100, 0b00101<<2, Ra5, Ra1, Ra2)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	// amocas.h needs both Zacas and Zabha.
+	c.GetMemory().SetUint16(0x100, 0x8001)
+	c.SetRegister(Ra0, 0xffffffffffff8001)
+	c.SetRegister(Ra2, 0x7ffe)
+	if err := execute(c, cas(0b001, Ra0, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint16(0x100); v != 0x7ffe || c.GetRegister(Ra0) != 0xffffffffffff8001 {
+		t.FailNow()
+	}
+	c.SetISA(c.GetISA() &^ ISAZabha)
+	if err := execute(c, cas(0b001, Ra0, Ra2)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	// An LR reservation is not disturbed by a failed compare-and-swap.
+	if err := execute(c, encodeR(op, 0b011, 0b00010<<2, Ra0, Ra1, Rzero)); err != nil {
+		t.Fatal(err)
+	}
+	c.SetRegister(Ra0, 0)
+	if err := execute(c, cas(0b011, Ra0, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute(c, encodeR(op, 0b011, 0b00011<<2, Ra0, Ra1, Ra2)); err != nil || c.GetRegister(Ra0) != 0 {
+		t.FailNow()
+	}
+}
+
+func TestAtomicCompareAndSwapRV32(t *testing.T) {
+	const op = 0b0101111
+	c := newRV32CPU()
+	c.SetRegister(Ra1, 0x100)
+	c.GetMemory().SetUint64(0x100, 0x8000000100000002)
+	// amocas.d a4, a2, (a1) with the pairs a4:a5 and a2:a3.
+	c.SetRegister(Ra4, 2)
+	c.SetRegister(Ra5, 0x80000001)
+	c.SetRegister(Ra2, 0xdeadbeef)
+	c.SetRegister(Ra3, 0xcafebabe)
+	if err := execute(c, encodeR(op, 0b011, 0b00101<<2, Ra4, Ra1, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint64(0x100); v != 0xcafebabedeadbeef {
+		t.Fatalf("%#x", v)
+	}
+	if c.GetRegister(Ra4) != 2 || c.GetRegister(Ra5) != 0xffffffff80000001 {
+		t.FailNow()
+	}
+	// The pair at x0 reads as zero and discards the result, x1 is not written.
+	c.SetRegister(Rra, 0x1234)
+	if err := execute(c, encodeR(op, 0b011, 0b00101<<2, Rzero, Ra1, Ra2)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegister(Rra) != 0x1234 {
+		t.FailNow()
+	}
+	// Odd registers are reserved, amocas.q does not exist on RV32.
+	for _, i := range []uint64{
+		encodeR(op, 0b011, 0b00101<<2, Ra5, Ra1, Ra2),
+		encodeR(op, 0b011, 0b00101<<2, Ra4, Ra1, Ra3),
+		encodeR(op, 0b100, 0b00101<<2, Ra4, Ra1, Ra2),
+	} {
+		if err := execute(c, i); err != ErrAbnormalInstruction {
+			t.Fatalf("%#08x", i)
+		}
+	}
+}
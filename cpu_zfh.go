@@ -0,0 +1,363 @@
+package rv64
+
+// https://github.com/riscv/riscv-isa-manual/releases/download/Ratified-IMAFDQC/riscv-spec-20191213.pdf
+//
+// Zfh: Half-precision floating-point.
+// Zfhmin: Minimal half-precision floating-point, only FLH, FSH, FMV.X.H, FMV.H.X and the conversions between half,
+// single and double precision.
+
+import (
+	"fmt"
+	"math"
+)
+
+func float16Wide(h uint16) float64 {
+	return float64(Float16ToFloat32(h))
+}
+
+type isaZfh struct{}
+
+func (_ *isaZfh) flh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, imm := IType(i)
+	imm = SignExtend(imm, 11)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s imm: ----(%#016x)", c.GetPC(), "flh", c.LogF(rd), c.LogI(rs1), imm))
+	a := c.GetRegister(rs1) + imm
+	v, err := c.GetMemory().GetUint16(a)
+	if err != nil {
+		return 0, err
+	}
+	c.SetRegisterFloatAsFloat16(rd, v)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fsh(c *CPU, i uint64) (uint64, error) {
+	rs1, rs2, imm := SType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s rs1: %s rs2: %s imm: ----(%#016x)", c.GetPC(), "fsh", c.LogI(rs1), c.LogF(rs2), imm))
+	a := c.GetRegister(rs1) + imm
+	err := c.GetMemory().SetUint16(a, uint16(c.GetRegisterFloat(rs2)))
+	if err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fma(c *CPU, i uint64, name string, negp bool, negd bool) (uint64, error) {
+	rd, rs1, rs2, rs3 := R4Type(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s rs3: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2), c.LogF(rs3)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	a := uint64(c.GetRegisterFloatAsFloat16(rs1))
+	b := uint64(c.GetRegisterFloatAsFloat16(rs2))
+	d := uint64(c.GetRegisterFloatAsFloat16(rs3))
+	if negp {
+		a ^= 1 << 15
+	}
+	if negd {
+		d ^= 1 << 15
+	}
+	r, flags := SoftMulAdd(FloatFormatH, a, b, d, rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) fmaddh(c *CPU, i uint64) (uint64, error) {
+	return z.fma(c, i, "fmadd.h", false, false)
+}
+
+func (z *isaZfh) fmsubh(c *CPU, i uint64) (uint64, error) {
+	return z.fma(c, i, "fmsub.h", false, true)
+}
+
+func (z *isaZfh) fnmsubh(c *CPU, i uint64) (uint64, error) {
+	return z.fma(c, i, "fnmsub.h", true, false)
+}
+
+func (z *isaZfh) fnmaddh(c *CPU, i uint64) (uint64, error) {
+	return z.fma(c, i, "fnmadd.h", true, true)
+}
+
+func (_ *isaZfh) arith(c *CPU, i uint64, name string, f func(FloatFormat, uint64, uint64, uint64) (uint64, uint64)) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	a := uint64(c.GetRegisterFloatAsFloat16(rs1))
+	b := uint64(c.GetRegisterFloatAsFloat16(rs2))
+	r, flags := f(FloatFormatH, a, b, rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) faddh(c *CPU, i uint64) (uint64, error) {
+	return z.arith(c, i, "fadd.h", SoftAdd)
+}
+
+func (z *isaZfh) fsubh(c *CPU, i uint64) (uint64, error) {
+	return z.arith(c, i, "fsub.h", SoftSub)
+}
+
+func (z *isaZfh) fmulh(c *CPU, i uint64) (uint64, error) {
+	return z.arith(c, i, "fmul.h", SoftMul)
+}
+
+func (z *isaZfh) fdivh(c *CPU, i uint64) (uint64, error) {
+	return z.arith(c, i, "fdiv.h", SoftDiv)
+}
+
+func (_ *isaZfh) fsqrth(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fsqrt.h", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftSqrt(FloatFormatH, uint64(c.GetRegisterFloatAsFloat16(rs1)), rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) sgnj(c *CPU, i uint64, name string, f func(a uint16, b uint16) uint16) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	a := c.GetRegisterFloatAsFloat16(rs1)
+	b := c.GetRegisterFloatAsFloat16(rs2)
+	c.SetRegisterFloatAsFloat16(rd, a&0x7fff|f(a, b)&0x8000)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) fsgnjh(c *CPU, i uint64) (uint64, error) {
+	return z.sgnj(c, i, "fsgnj.h", func(a uint16, b uint16) uint16 { return b })
+}
+
+func (z *isaZfh) fsgnjnh(c *CPU, i uint64) (uint64, error) {
+	return z.sgnj(c, i, "fsgnjn.h", func(a uint16, b uint16) uint16 { return ^b })
+}
+
+func (z *isaZfh) fsgnjxh(c *CPU, i uint64) (uint64, error) {
+	return z.sgnj(c, i, "fsgnjx.h", func(a uint16, b uint16) uint16 { return a ^ b })
+}
+
+// minmax returns the lesser or the greater operand. -0 is less than +0, a NaN operand is ignored and two NaNs give the
+// canonical NaN.
+func (_ *isaZfh) minmax(c *CPU, i uint64, name string, max bool) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	a := c.GetRegisterFloatAsFloat16(rs1)
+	b := c.GetRegisterFloatAsFloat16(rs2)
+	if IsSNaN16(a) || IsSNaN16(b) {
+		c.SetFloatFlag(FFlagsNV, 1)
+	}
+	fa, fb := float16Wide(a), float16Wide(b)
+	var r uint16
+	switch {
+	case IsNaN16(a) && IsNaN16(b):
+		r = NaN16
+	case IsNaN16(a):
+		r = b
+	case IsNaN16(b):
+		r = a
+	case fa == fb:
+		if (a&0x8000 != 0) != max {
+			r = a
+		} else {
+			r = b
+		}
+	case (fa < fb) != max:
+		r = a
+	default:
+		r = b
+	}
+	c.SetRegisterFloatAsFloat16(rd, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) fminh(c *CPU, i uint64) (uint64, error) {
+	return z.minmax(c, i, "fmin.h", false)
+}
+
+func (z *isaZfh) fmaxh(c *CPU, i uint64) (uint64, error) {
+	return z.minmax(c, i, "fmax.h", true)
+}
+
+func (_ *isaZfh) cvtx(c *CPU, i uint64, name string, signed bool, n uint) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftToInt(FloatFormatH, uint64(c.GetRegisterFloatAsFloat16(rs1)), signed, n, rm)
+	c.SetRegister(rd, r)
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) fcvtwh(c *CPU, i uint64) (uint64, error) {
+	return z.cvtx(c, i, "fcvt.w.h", true, 32)
+}
+
+func (z *isaZfh) fcvtwuh(c *CPU, i uint64) (uint64, error) {
+	return z.cvtx(c, i, "fcvt.wu.h", false, 32)
+}
+
+func (z *isaZfh) fcvtlh(c *CPU, i uint64) (uint64, error) {
+	return z.cvtx(c, i, "fcvt.l.h", true, 64)
+}
+
+func (z *isaZfh) fcvtluh(c *CPU, i uint64) (uint64, error) {
+	return z.cvtx(c, i, "fcvt.lu.h", false, 64)
+}
+
+func (_ *isaZfh) cvth(c *CPU, i uint64, name string, signed bool, n uint) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogF(rd), c.LogI(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftFromInt(FloatFormatH, c.GetRegister(rs1), signed, n, rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) fcvthw(c *CPU, i uint64) (uint64, error) {
+	return z.cvth(c, i, "fcvt.h.w", true, 32)
+}
+
+func (z *isaZfh) fcvthwu(c *CPU, i uint64) (uint64, error) {
+	return z.cvth(c, i, "fcvt.h.wu", false, 32)
+}
+
+func (z *isaZfh) fcvthl(c *CPU, i uint64) (uint64, error) {
+	return z.cvth(c, i, "fcvt.h.l", true, 64)
+}
+
+func (z *isaZfh) fcvthlu(c *CPU, i uint64) (uint64, error) {
+	return z.cvth(c, i, "fcvt.h.lu", false, 64)
+}
+
+func (_ *isaZfh) fcvths(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.h.s", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftConvert(FloatFormatS, FloatFormatH, uint64(math.Float32bits(c.GetRegisterFloatAsFloat32(rs1))), rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fcvthd(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.h.d", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftConvert(FloatFormatD, FloatFormatH, math.Float64bits(c.GetRegisterFloatAsFloat64(rs1)), rm)
+	c.SetRegisterFloatAsFloat16(rd, uint16(r))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fcvtsh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.s.h", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftConvert(FloatFormatH, FloatFormatS, uint64(c.GetRegisterFloatAsFloat16(rs1)), rm)
+	c.SetRegisterFloatAsFloat32(rd, math.Float32frombits(uint32(r)))
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fcvtdh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fcvt.d.h", c.LogF(rd), c.LogF(rs1), c.LogF(rs2)))
+	rm, err := c.GetRoundingMode(i)
+	if err != nil {
+		return 0, err
+	}
+	r, flags := SoftConvert(FloatFormatH, FloatFormatD, uint64(c.GetRegisterFloatAsFloat16(rs1)), rm)
+	c.SetRegisterFloat(rd, r)
+	c.SetFloatFlag(flags, 1)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fmvxh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmv.x.h", c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	c.SetRegister(rd, SignExtend(uint64(uint16(c.GetRegisterFloat(rs1))), 15))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) fmvhx(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fmv.h.x", c.LogF(rd), c.LogI(rs1), c.LogF(rs2)))
+	c.SetRegisterFloatAsFloat16(rd, uint16(c.GetRegister(rs1)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZfh) cmp(c *CPU, i uint64, name string, quiet bool, f func(a float64, b float64) bool) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), name, c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	a := c.GetRegisterFloatAsFloat16(rs1)
+	b := c.GetRegisterFloatAsFloat16(rs2)
+	if IsSNaN16(a) || IsSNaN16(b) || !quiet && (IsNaN16(a) || IsNaN16(b)) {
+		c.SetFloatFlag(FFlagsNV, 1)
+	}
+	if f(float16Wide(a), float16Wide(b)) {
+		c.SetRegister(rd, 1)
+	} else {
+		c.SetRegister(rd, 0)
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (z *isaZfh) feqh(c *CPU, i uint64) (uint64, error) {
+	return z.cmp(c, i, "feq.h", true, func(a float64, b float64) bool { return a == b })
+}
+
+func (z *isaZfh) flth(c *CPU, i uint64) (uint64, error) {
+	return z.cmp(c, i, "flt.h", false, func(a float64, b float64) bool { return a < b })
+}
+
+func (z *isaZfh) fleh(c *CPU, i uint64) (uint64, error) {
+	return z.cmp(c, i, "fle.h", false, func(a float64, b float64) bool { return a <= b })
+}
+
+func (_ *isaZfh) fclassh(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "fclass.h", c.LogI(rd), c.LogF(rs1), c.LogF(rs2)))
+	c.SetRegister(rd, FClassH(c.GetRegisterFloatAsFloat16(rs1)))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
@@ -0,0 +1,189 @@
+package rv64
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat16Conversion(t *testing.T) {
+	for _, e := range []struct {
+		f     float64
+		h     uint16
+		flags uint64
+	}{
+		{1, 0x3c00, 0},
+		{-2, 0xc000, 0},
+		{65504, 0x7bff, 0},
+		{65520, 0x7c00, FFlagsOF | FFlagsNX},
+		{math.Inf(-1), 0xfc00, 0},
+		{math.NaN(), NaN16, 0},
+		{math.Ldexp(1, -14), 0x0400, 0},
+		{math.Ldexp(1, -24), 0x0001, 0},
+		{math.Ldexp(1, -25), 0x0000, FFlagsUF | FFlagsNX},
+		{math.Ldexp(3, -26), 0x0001, FFlagsUF | FFlagsNX},
+		{math.Copysign(0, -1), 0x8000, 0},
+		// Ties to even.
+		{1 + math.Ldexp(1, -11), 0x3c00, FFlagsNX},
+		{1 + math.Ldexp(3, -11), 0x3c02, FFlagsNX},
+		{0.1, 0x2e66, FFlagsNX},
+	} {
+		h, flags := Float64ToFloat16(e.f)
+		if h != e.h || flags != e.flags {
+			t.Fatalf("%v: %#04x %#x != %#04x %#x", e.f, h, flags, e.h, e.flags)
+		}
+		if !math.IsNaN(e.f) && flags == 0 && float64(Float16ToFloat32(h)) != e.f {
+			t.Fatalf("%#04x: %v != %v", h, Float16ToFloat32(h), e.f)
+		}
+	}
+	if FClassH(0x0001) != 0b00_00100000 || FClassH(0xfc00) != 0b00_00000001 || FClassH(0x7d00) != 0b01_00000000 {
+		t.FailNow()
+	}
+}
+
+func TestZfh(t *testing.T) {
+	const (
+		opfp   = 0b1010011
+		loadfp = 0b0000111
+	)
+	r := func(funct5, funct3, rs2 uint64) uint64 { return encodeR(opfp, funct3, funct5<<2|0b10, Ra0, Ra1, rs2) }
+	c := newVectorCPU()
+	// NaN boxing.
+	c.SetRegisterFloatAsFloat16(Ra1, 0x3c00)
+	if c.GetRegisterFloat(Ra1) != 0xffffffffffff3c00 {
+		t.FailNow()
+	}
+	c.SetRegisterFloat(Ra2, 0xffffffff00003c00)
+	if c.GetRegisterFloatAsFloat16(Ra2) != NaN16 {
+		t.FailNow()
+	}
+	for _, e := range []struct {
+		name  string
+		i     uint64
+		a     uint16
+		b     uint16
+		r     uint16
+		flags uint64
+	}{
+		{"fadd.h", r(0b00000, 0, Ra2), 0x3c00, 0x4000, 0x4200, 0},
+		{"fadd.h", r(0b00000, 0, Ra2), 0x7bff, 0x7bff, 0x7c00, FFlagsOF | FFlagsNX},
+		{"fsub.h", r(0b00001, 0, Ra2), 0x7c00, 0x7c00, NaN16, FFlagsNV},
+		{"fmul.h", r(0b00010, 0, Ra2), 0x3e00, 0x4000, 0x4200, 0},
+		{"fdiv.h", r(0b00011, 0, Ra2), 0x3c00, 0x4200, 0x3555, FFlagsNX},
+		{"fdiv.h", r(0b00011, 0, Ra2), 0xbc00, 0x0000, 0xfc00, FFlagsDZ},
+		{"fsqrt.h", r(0b01011, 0, 0), 0x4400, 0, 0x4000, 0},
+		{"fsqrt.h", r(0b01011, 0, 0), 0xbc00, 0, NaN16, FFlagsNV},
+		{"fsgnjn.h", r(0b00100, 1, Ra2), 0x3c00, 0x3c00, 0xbc00, 0},
+		{"fsgnjx.h", r(0b00100, 2, Ra2), 0xbc00, 0xbc00, 0x3c00, 0},
+		{"fmin.h", r(0b00101, 0, Ra2), 0x0000, 0x8000, 0x8000, 0},
+		{"fmax.h", r(0b00101, 1, Ra2), 0x7d00, 0x3c00, 0x3c00, FFlagsNV},
+		{"fmax.h", r(0b00101, 1, Ra2), 0x7e00, 0x7e00, NaN16, 0},
+		{"fcvt.h.s", r(0b01000, 0, 0b00000), 0, 0, 0x3555, FFlagsNX},
+		{"fcvt.h.w", r(0b11010, 0, 0b00000), 0, 0, 0xd140, 0},
+		{"fcvt.h.lu", r(0b11010, 0, 0b00011), 0, 0, 0x7c00, FFlagsOF | FFlagsNX},
+	} {
+		c.SetRegisterFloatAsFloat16(Ra1, e.a)
+		c.SetRegisterFloatAsFloat16(Ra2, e.b)
+		switch e.name {
+		case "fcvt.h.s":
+			c.SetRegisterFloatAsFloat32(Ra1, 1.0/3)
+		case "fcvt.h.w":
+			c.SetRegister(Ra1, uint64(0xffffffffffffffd6))
+		case "fcvt.h.lu":
+			c.SetRegister(Ra1, 1<<40)
+		}
+		c.ClrFloatFlag()
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if r := c.GetRegisterFloatAsFloat16(Ra0); r != e.r {
+			t.Fatalf("%s: %#04x != %#04x", e.name, r, e.r)
+		}
+		if f := c.GetCSR().Get(CSRfflags); f != e.flags {
+			t.Fatalf("%s: flags %#x != %#x", e.name, f, e.flags)
+		}
+	}
+	// fmadd.h a0, a1, a2, a3
+	c.SetRegisterFloatAsFloat16(Ra1, 0x4000)
+	c.SetRegisterFloatAsFloat16(Ra2, 0x4200)
+	c.SetRegisterFloatAsFloat16(Ra3, 0xbc00)
+	if err := execute(c, Ra3<<27|0b10<<25|Ra2<<20|Ra1<<15|Ra0<<7|0b1000011); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat16(Ra0) != 0x4500 {
+		t.FailNow()
+	}
+	// Integer results.
+	for _, e := range []struct {
+		name  string
+		i     uint64
+		a     uint16
+		b     uint16
+		r     uint64
+		flags uint64
+	}{
+		{"fcvt.w.h", r(0b11000, 0, 0b00000), 0xc540, 0, 0xfffffffffffffffb, FFlagsNX},
+		{"fcvt.wu.h", r(0b11000, 0, 0b00001), 0xc540, 0, 0, FFlagsNV},
+		{"fcvt.l.h", r(0b11000, 0, 0b00010), 0x7c00, 0, 0x7fffffffffffffff, FFlagsNV},
+		{"fcvt.lu.h", r(0b11000, 0, 0b00011), 0x7bff, 0, 65504, 0},
+		{"fmv.x.h", r(0b11100, 0, 0), 0xbc00, 0, 0xffffffffffffbc00, 0},
+		{"fclass.h", r(0b11100, 1, 0), 0x8001, 0, 0b00_00000100, 0},
+		{"feq.h", r(0b10100, 2, Ra2), 0x7e00, 0x3c00, 0, 0},
+		{"flt.h", r(0b10100, 1, Ra2), 0x7e00, 0x3c00, 0, FFlagsNV},
+		{"fle.h", r(0b10100, 0, Ra2), 0x3c00, 0x3c00, 1, 0},
+	} {
+		c.SetRegisterFloatAsFloat16(Ra1, e.a)
+		c.SetRegisterFloatAsFloat16(Ra2, e.b)
+		c.ClrFloatFlag()
+		if err := execute(c, e.i); err != nil {
+			t.Fatal(e.name, err)
+		}
+		if r := c.GetRegister(Ra0); r != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, r, e.r)
+		}
+		if f := c.GetCSR().Get(CSRfflags); f != e.flags {
+			t.Fatalf("%s: flags %#x != %#x", e.name, f, e.flags)
+		}
+	}
+	// fcvt.d.h and fcvt.s.h widen exactly.
+	c.SetRegisterFloatAsFloat16(Ra1, 0x0001)
+	if err := execute(c, encodeR(opfp, 0, 0b0100001, Ra0, Ra1, 0b00010)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat64(Ra0) != math.Ldexp(1, -24) {
+		t.FailNow()
+	}
+	if err := execute(c, encodeR(opfp, 0, 0b0100000, Ra0, Ra1, 0b00010)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloatAsFloat32(Ra0) != float32(math.Ldexp(1, -24)) {
+		t.FailNow()
+	}
+	// flh and fsh.
+	c.GetMemory().SetUint16(0x100, 0xc000)
+	c.SetRegister(Ra1, 0x100)
+	if err := execute(c, encodeI(loadfp, 0b001, 0, Ra0, Ra1)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetRegisterFloat(Ra0) != 0xffffffffffffc000 {
+		t.FailNow()
+	}
+	// fsh a0, 2(a1)
+	if err := execute(c, Ra0<<20|Ra1<<15|0b001<<12|2<<7|0b0100111); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetMemory().GetUint16(0x102); v != 0xc000 {
+		t.FailNow()
+	}
+	// Zfhmin keeps the transfers and conversions but not the arithmetic.
+	c.SetISA(c.GetISA() &^ ISAZfh)
+	if err := execute(c, r(0b00000, 0, Ra2)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	if err := execute(c, r(0b01000, 0, 0b00001)); err != nil {
+		t.Fatal(err)
+	}
+	c.SetISA(c.GetISA() &^ ISAZfhmin)
+	if err := execute(c, encodeI(loadfp, 0b001, 0, Ra0, Ra1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,107 @@
+package rv64
+
+// Zicond: Integer conditional operations.
+// Zicbom: Cache-block management instructions.
+// Zicboz: Cache-block zero instructions.
+// Zihintpause: Pause hint.
+
+import (
+	"fmt"
+)
+
+type isaZicond struct{}
+
+func (_ *isaZicond) czeroeqz(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "czero.eqz", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	if c.GetRegister(rs2) == 0 {
+		c.SetRegister(rd, 0)
+	} else {
+		c.SetRegister(rd, c.GetRegister(rs1))
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZicond) czeronez(c *CPU, i uint64) (uint64, error) {
+	rd, rs1, rs2 := RType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rd: %s rs1: %s rs2: %s", c.GetPC(), "czero.nez", c.LogI(rd), c.LogI(rs1), c.LogI(rs2)))
+	if c.GetRegister(rs2) != 0 {
+		c.SetRegister(rd, 0)
+	} else {
+		c.SetRegister(rd, c.GetRegister(rs1))
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// The memory is always coherent, cbo.clean, cbo.flush and cbo.inval only act on the simulated caches of the CPU, if
+// any. The block is cleaned, cleaned and invalidated, or invalidated, in every level of the data cache hierarchy.
+type isaZicbom struct{}
+
+// cboManage applies a cache-block management operation to the block holding the address a. A block whose address
+// does not translate is left alone, the instructions never fault.
+func cboManage(c *CPU, a uint64, clean bool, inval bool) {
+	if c.caches == nil {
+		return
+	}
+	n := c.GetCacheBlockSize()
+	a &^= n - 1
+	if c.GetXLEN() == 32 {
+		a &= 0xffffffff
+	}
+	if c.isPaging() {
+		var err error
+		if a, err = c.Translate(a, AccessLoad); err != nil {
+			return
+		}
+	}
+	if fastenCacheable(c.fasten, a) {
+		c.caches.manage(c.GetPC(), a, n, clean, inval)
+	}
+}
+
+func (_ *isaZicbom) cboclean(c *CPU, i uint64) (uint64, error) {
+	_, rs1, _ := IType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rs1: %s", c.GetPC(), "cbo.clean", c.LogI(rs1)))
+	cboManage(c, c.GetRegister(rs1), true, false)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZicbom) cboflush(c *CPU, i uint64) (uint64, error) {
+	_, rs1, _ := IType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rs1: %s", c.GetPC(), "cbo.flush", c.LogI(rs1)))
+	cboManage(c, c.GetRegister(rs1), true, true)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+func (_ *isaZicbom) cboinval(c *CPU, i uint64) (uint64, error) {
+	_, rs1, _ := IType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rs1: %s", c.GetPC(), "cbo.inval", c.LogI(rs1)))
+	cboManage(c, c.GetRegister(rs1), false, true)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZicboz struct{}
+
+func (_ *isaZicboz) cbozero(c *CPU, i uint64) (uint64, error) {
+	_, rs1, _ := IType(i)
+	Debugln(fmt.Sprintf("%#08x % 10s  rs1: %s", c.GetPC(), "cbo.zero", c.LogI(rs1)))
+	n := c.GetCacheBlockSize()
+	if err := c.GetMemory().SetZero(c.GetRegister(rs1)&^(n-1), n); err != nil {
+		return 0, err
+	}
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+type isaZihintpause struct{}
+
+func (_ *isaZihintpause) pause(c *CPU, _ uint64) (uint64, error) {
+	Debugln(fmt.Sprintf("%#08x % 10s", c.GetPC(), "pause"))
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
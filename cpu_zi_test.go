@@ -0,0 +1,82 @@
+package rv64
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConditional(t *testing.T) {
+	const op = 0b0110011
+	c := newVectorCPU()
+	for _, e := range []struct {
+		name string
+		i    uint64
+		a    uint64
+		b    uint64
+		r    uint64
+	}{
+		{"czero.eqz", encodeR(op, 0b101, 0b0000111, Ra0, Ra1, Ra2), 42, 0, 0},
+		{"czero.eqz", encodeR(op, 0b101, 0b0000111, Ra0, Ra1, Ra2), 42, 1 << 63, 42},
+		{"czero.nez", encodeR(op, 0b111, 0b0000111, Ra0, Ra1, Ra2), 42, 0, 42},
+		{"czero.nez", encodeR(op, 0b111, 0b0000111, Ra0, Ra1, Ra2), 42, 1 << 63, 0},
+	} {
+		if v := run(t, c, e.i, e.a, e.b); v != e.r {
+			t.Fatalf("%s: %#x != %#x", e.name, v, e.r)
+		}
+	}
+	c.SetISA(c.GetISA() &^ ISAZicond)
+	if err := execute(c, encodeR(op, 0b101, 0b0000111, Ra0, Ra1, Ra2)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+}
+
+func TestCacheBlock(t *testing.T) {
+	cbo := func(op uint64) uint64 { return encodeI(0b0001111, 0b010, op, Rzero, Ra1) }
+	c := newVectorCPU()
+	ones := bytes.Repeat([]byte{0xff}, 0x100)
+	c.GetMemory().SetByte(0x100, ones)
+	// cbo.clean, cbo.flush and cbo.inval have no visible effect.
+	c.SetRegister(Ra1, 0x120)
+	for _, op := range []uint64{0b000, 0b001, 0b010} {
+		if err := execute(c, cbo(op)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// cbo.zero clears the aligned block containing the address.
+	if err := execute(c, cbo(0b100)); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := c.GetMemory().GetByte(0x100, 0x100)
+	if !bytes.Equal(b[:0x40], make([]byte, 0x40)) || !bytes.Equal(b[0x40:], ones[0x40:]) {
+		t.FailNow()
+	}
+	for _, n := range []uint64{0, 8, 48, 8192} {
+		if err := c.SetCacheBlockSize(n); err != ErrCacheBlockSize {
+			t.Fatal(n)
+		}
+	}
+	if err := c.SetCacheBlockSize(16); err != nil {
+		t.Fatal(err)
+	}
+	c.SetRegister(Ra1, 0x14f)
+	if err := execute(c, cbo(0b100)); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = c.GetMemory().GetByte(0x100, 0x100)
+	if !bytes.Equal(b[0x40:0x50], make([]byte, 0x10)) || !bytes.Equal(b[0x50:], ones[0x50:]) {
+		t.FailNow()
+	}
+	// cbo.zero must write rd = x0.
+	if err := execute(c, encodeI(0b0001111, 0b010, 0b100, Ra0, Ra1)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	c.SetISA(c.GetISA() &^ ISAZicboz)
+	if err := execute(c, cbo(0b100)); err != ErrAbnormalInstruction {
+		t.FailNow()
+	}
+	// pause
+	c.SetPC(0x100)
+	if err := execute(c, 0x0100000f); err != nil || c.GetPC() != 0x104 {
+		t.FailNow()
+	}
+}
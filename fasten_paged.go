@@ -0,0 +1,176 @@
+package rv64
+
+import (
+	"io"
+)
+
+const pagedSize = 0x1000
+
+// Paged is a memory made of 4 KiB pages which are shared copy-on-write between the forks of a memory. Pages never
+// written read as zero and take no space.
+//
+// Reset brings the memory back to its content when it was created or last forked, only the pages written since then
+// are restored, which makes it cheap to run many short executions from the same state.
+type Paged struct {
+	pages []*[pagedSize]byte
+	owned []bool // The page is a private copy, it can be written in place
+	base  []*[pagedSize]byte
+	dirty []uint64
+	size  uint64
+}
+
+// page returns page i for writing, copying it first if it is shared.
+func (p *Paged) page(i uint64) *[pagedSize]byte {
+	if !p.owned[i] {
+		n := new([pagedSize]byte)
+		if p.pages[i] != nil {
+			*n = *p.pages[i]
+		}
+		p.pages[i] = n
+		p.owned[i] = true
+		p.dirty = append(p.dirty, i)
+	}
+	return p.pages[i]
+}
+
+func (p *Paged) Get(a uint64) (byte, error) {
+	if a >= p.size {
+		return 0x00, ErrOutOfMemory
+	}
+	e := p.pages[a/pagedSize]
+	if e == nil {
+		return 0x00, nil
+	}
+	return e[a%pagedSize], nil
+}
+
+func (p *Paged) Set(a uint64, v byte) error {
+	if a >= p.size {
+		return ErrOutOfMemory
+	}
+	p.page(a / pagedSize)[a%pagedSize] = v
+	return nil
+}
+
+func (p *Paged) GetSized(a uint64, l uint64) (uint64, error) {
+	// Accesses crossing a page boundary, or out of the memory, go byte by byte.
+	if a >= p.size || a%pagedSize+l > pagedSize || a+l > p.size {
+		return fastenGetBytes(p, a, l)
+	}
+	e := p.pages[a/pagedSize]
+	if e == nil {
+		return 0, nil
+	}
+	o := a % pagedSize
+	var r uint64
+	for j := l; j > 0; j-- {
+		r = r<<8 | uint64(e[o+j-1])
+	}
+	return r, nil
+}
+
+func (p *Paged) SetSized(a uint64, l uint64, v uint64) error {
+	if a >= p.size || a%pagedSize+l > pagedSize || a+l > p.size {
+		return fastenSetBytes(p, a, l, v)
+	}
+	e := p.page(a / pagedSize)
+	o := a % pagedSize
+	for j := uint64(0); j < l; j++ {
+		e[o+j] = byte(v >> (8 * j))
+	}
+	return nil
+}
+
+func (p *Paged) Len() uint64 {
+	return p.size
+}
+
+// Fork returns a copy of the memory sharing its pages. Both memories copy a page on their first write to it, and
+// reset to the content they have now.
+func (p *Paged) Fork() *Paged {
+	for i := range p.owned {
+		p.owned[i] = false
+	}
+	p.base = append(p.base[:0], p.pages...)
+	p.dirty = p.dirty[:0]
+	return &Paged{
+		pages: append([]*[pagedSize]byte{}, p.pages...),
+		owned: make([]bool, len(p.pages)),
+		base:  append([]*[pagedSize]byte{}, p.pages...),
+		size:  p.size,
+	}
+}
+
+// Reset restores the pages written since the memory was created or last forked.
+func (p *Paged) Reset() {
+	for _, i := range p.dirty {
+		p.pages[i] = p.base[i]
+		p.owned[i] = false
+	}
+	p.dirty = p.dirty[:0]
+}
+
+func (p *Paged) Snapshot(w io.Writer) error {
+	if err := snapshotWrite(w, p.size); err != nil {
+		return err
+	}
+	zero := make([]byte, pagedSize)
+	for _, e := range p.pages {
+		b := zero
+		if e != nil {
+			b = e[:]
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Paged) Restore(r io.Reader) error {
+	return snapshotRestore(p, r)
+}
+
+// decode reads the pages, those left zero are not allocated unless the memory holds something else there.
+func (p *Paged) decode(r io.Reader) (func(), error) {
+	var n uint64
+	if err := snapshotRead(r, &n); err != nil {
+		return nil, err
+	}
+	if n != p.size {
+		return nil, ErrSnapshotFormat
+	}
+	pages := make([]*[pagedSize]byte, len(p.pages))
+	zero := [pagedSize]byte{}
+	for i := range pages {
+		e := new([pagedSize]byte)
+		if _, err := io.ReadFull(r, e[:]); err != nil {
+			return nil, err
+		}
+		if *e != zero {
+			pages[i] = e
+		}
+	}
+	return func() {
+		for i, e := range pages {
+			if e == nil && p.pages[i] == nil {
+				continue
+			}
+			if e == nil {
+				e = &zero
+			}
+			*p.page(uint64(i)) = *e
+		}
+	}, nil
+}
+
+// NewPaged returns a memory of n bytes, rounded up to whole pages.
+func NewPaged(n uint64) *Paged {
+	c := (n + pagedSize - 1) / pagedSize
+	return &Paged{
+		pages: make([]*[pagedSize]byte, c),
+		owned: make([]bool, c),
+		base:  make([]*[pagedSize]byte, c),
+		size:  c * pagedSize,
+	}
+}
@@ -0,0 +1,29 @@
+package rv64
+
+// Wrap32 truncates addresses to 32 bits before passing them to the underlying Fasten, it is the view of memory of an
+// RV32 hart.
+type Wrap32 struct {
+	Fasten
+}
+
+func (w *Wrap32) Get(a uint64) (byte, error) {
+	return w.Fasten.Get(a & 0xffffffff)
+}
+
+func (w *Wrap32) Set(a uint64, v byte) error {
+	return w.Fasten.Set(a&0xffffffff, v)
+}
+
+func (w *Wrap32) GetSized(a uint64, l uint64) (uint64, error) {
+	if s, ok := w.Fasten.(SizedFasten); ok {
+		return s.GetSized(a&0xffffffff, l)
+	}
+	return fastenGetBytes(w, a, l)
+}
+
+func (w *Wrap32) SetSized(a uint64, l uint64, v uint64) error {
+	if s, ok := w.Fasten.(SizedFasten); ok {
+		return s.SetSized(a&0xffffffff, l, v)
+	}
+	return fastenSetBytes(w, a, l, v)
+}
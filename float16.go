@@ -0,0 +1,79 @@
+package rv64
+
+import (
+	"math"
+)
+
+// IEEE 754 binary16. Go has no half-precision type, so values are carried as their raw uint16 bits. Every binary16
+// value is exactly representable in float32 and float64, which are used to compare them.
+
+func IsNaN16(h uint16) bool {
+	return h&0x7c00 == 0x7c00 && h&0x03ff != 0x00
+}
+
+func IsQNaN16(h uint16) bool {
+	return IsNaN16(h) && h&0x0200 != 0x00
+}
+
+func IsSNaN16(h uint16) bool {
+	return IsNaN16(h) && h&0x0200 == 0x00
+}
+
+func IsSubmoduleFloat16(h uint16) bool {
+	return h&0x7c00 == 0 && h&0x03ff != 0
+}
+
+func FClassH(h uint16) uint64 {
+	s := h&0x8000 != 0
+	if IsSNaN16(h) {
+		return 0b01_00000000
+	}
+	if IsQNaN16(h) {
+		return 0b10_00000000
+	}
+	if s {
+		if h&0x7fff == 0x7c00 {
+			return 0b00_00000001
+		} else if h&0x7fff == 0 {
+			return 0b00_00001000
+		} else if IsSubmoduleFloat16(h) {
+			return 0b00_00000100
+		} else {
+			return 0b00_00000010
+		}
+	}
+	if h == 0x7c00 {
+		return 0b00_10000000
+	} else if h == 0 {
+		return 0b00_00010000
+	} else if IsSubmoduleFloat16(h) {
+		return 0b00_00100000
+	} else {
+		return 0b00_01000000
+	}
+}
+
+// Float16ToFloat32 widens a binary16 value. The conversion is exact and keeps the payload of NaNs.
+func Float16ToFloat32(h uint16) float32 {
+	s := uint32(h&0x8000) << 16
+	e := uint32(h>>10) & 0x1f
+	m := uint32(h) & 0x03ff
+	switch e {
+	case 0x1f:
+		return math.Float32frombits(s | 0x7f800000 | m<<13)
+	case 0x00:
+		f := float32(math.Ldexp(float64(m), -24))
+		if s != 0 {
+			f = -f
+		}
+		return f
+	}
+	return math.Float32frombits(s | (e+127-15)<<23 | m<<13)
+}
+
+// Float64ToFloat16 rounds f to the nearest binary16 value, ties to even. It returns the accrued exception flags of
+// the conversion. A NaN always converts to the canonical NaN.
+func Float64ToFloat16(f float64) (uint16, uint64) {
+	h, flags := SoftConvert(FloatFormatD, FloatFormatH, math.Float64bits(f), FRoundRNE)
+	return uint16(h), flags
+}
@@ -0,0 +1,119 @@
+package rv64
+
+// duplicate returns a copy of the CPU with its own CSRs and vector registers. Everything else, memory and System
+// included, is shared.
+func (c *CPU) duplicate() *CPU {
+	n := &CPU{}
+	*n = *c
+	n.csr = NewCSRStandard()
+	copyCSR(n.csr, c.csr)
+	n.vreg = append([]byte{}, c.vreg...)
+	if m, ok := c.cost.(CostCloner); ok {
+		n.cost = m.Clone()
+	}
+	if c.caches != nil {
+		n.caches = c.caches.clone()
+	}
+	return n
+}
+
+func copyCSR(dst CSR, src CSR) {
+	d, ok0 := dst.(*CSRStandard)
+	s, ok1 := src.(*CSRStandard)
+	if ok0 && ok1 {
+		d.m = s.m
+		return
+	}
+	for i := uint64(0); i < 0x1000; i++ {
+		dst.Set(i, src.Get(i))
+	}
+}
+
+// Fork returns a copy of a standalone CPU, which can run a different input from the same warmed-up state. Registers
+// and CSRs are copied, the memory must be a Paged memory and is forked copy-on-write, and the System must be a
+// SystemStandard, which is copied. A CostCloner cost model is copied too, the fork has its own empty L1 caches and the
+// cycle limit and the lower cache levels are shared with the parent. It fails with ErrForkUnsupported otherwise.
+//
+// Reset brings the fork back to its state right after Fork. The memory of the parent resets to the same point, so a
+// parent which is itself a fork resets its registers there too.
+func (c *CPU) Fork() (*CPU, error) {
+	p, ok := c.fasten.(*Paged)
+	if !ok {
+		return nil, ErrForkUnsupported
+	}
+	s, ok := c.system.(*SystemStandard)
+	if !ok {
+		return nil, ErrForkUnsupported
+	}
+	n := c.duplicate()
+	n.fasten = p.Fork()
+	n.system = s.fork()
+	n.origin = n.capture()
+	if c.origin != nil {
+		c.origin = c.capture()
+	}
+	return n, nil
+}
+
+// capture returns a copy of the state of a CPU with a SystemStandard, for Reset.
+func (c *CPU) capture() *CPU {
+	o := c.duplicate()
+	o.system = c.system.(*SystemStandard).fork()
+	o.origin = nil
+	return o
+}
+
+// Reset restores the state a forked CPU had right after Fork. Only the memory pages written since are copied back.
+// It does nothing on a CPU which was not forked.
+func (c *CPU) Reset() {
+	o := c.origin
+	if o == nil {
+		return
+	}
+	var (
+		csr    = c.csr
+		vreg   = c.vreg
+		fasten = c.fasten
+		system = c.system
+	)
+	*c = *o
+	c.csr = csr
+	copyCSR(c.csr, o.csr)
+	c.vreg = vreg
+	if len(c.vreg) != len(o.vreg) {
+		c.vreg = make([]byte, len(o.vreg))
+	}
+	copy(c.vreg, o.vreg)
+	c.fasten = fasten
+	c.fasten.(*Paged).Reset()
+	c.system = system
+	c.system.(*SystemStandard).reset(o.system.(*SystemStandard))
+	if m, ok := o.cost.(CostCloner); ok {
+		c.cost = m.Clone()
+	}
+	if o.caches != nil {
+		c.caches = o.caches.clone()
+	}
+	c.origin = o
+}
+
+// fork returns a copy of the System.
+func (s *SystemStandard) fork() *SystemStandard {
+	n := NewSystemStandard()
+	n.reset(s)
+	return n
+}
+
+// reset copies the state of o into the System.
+func (s *SystemStandard) reset(o *SystemStandard) {
+	s.ExitCode = o.ExitCode
+	s.tidClear = map[uint64]uint64{}
+	for k, v := range o.tidClear {
+		s.tidClear[k] = v
+	}
+	s.futex = map[uint64]*futexWait{}
+	for k, v := range o.futex {
+		e := *v
+		s.futex[k] = &e
+	}
+}
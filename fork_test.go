@@ -0,0 +1,139 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func TestPaged(t *testing.T) {
+	p := NewPaged(3*0x1000 - 1)
+	if p.Len() != 3*0x1000 {
+		t.Fatal(p.Len())
+	}
+	mem := &Memory{Fasten: p}
+	mem.SetUint64(0x0ffc, 0x0123456789abcdef)
+	if v, _ := mem.GetUint64(0x0ffc); v != 0x0123456789abcdef {
+		t.Fatal(v)
+	}
+	if _, err := mem.GetUint32(0x2ffe); err != ErrOutOfMemory {
+		t.Fatal(err)
+	}
+	f := p.Fork()
+	fmem := &Memory{Fasten: f}
+	// Writes after the fork are private to each memory.
+	fmem.SetUint32(0x1000, 1)
+	mem.SetUint32(0x1000, 2)
+	fmem.SetUint32(0x2000, 3)
+	if v, _ := fmem.GetUint32(0x1000); v != 1 {
+		t.Fatal(v)
+	}
+	if v, _ := mem.GetUint32(0x1000); v != 2 {
+		t.Fatal(v)
+	}
+	if v, _ := mem.GetUint32(0x2000); v != 0 {
+		t.Fatal(v)
+	}
+	if len(f.dirty) != 2 {
+		t.Fatal(f.dirty)
+	}
+	// Both reset to the content at the fork.
+	f.Reset()
+	p.Reset()
+	for _, m := range []*Memory{mem, fmem} {
+		if v, _ := m.GetUint64(0x0ffc); v != 0x0123456789abcdef {
+			t.Fatal(v)
+		}
+		if v, _ := m.GetUint32(0x1000); v != 0x01234567 {
+			t.Fatal(v)
+		}
+		if v, _ := m.GetUint32(0x2000); v != 0 {
+			t.Fatal(v)
+		}
+	}
+	if len(f.dirty) != 0 || len(p.dirty) != 0 {
+		t.FailNow()
+	}
+}
+
+// newForkCPU returns the CPU of newSnapshotCPU on a Paged memory.
+func newForkCPU() *CPU {
+	c := newSnapshotCPU()
+	p := NewPaged(0x1000)
+	for a := uint64(0); a < 0x1000; a++ {
+		v, _ := c.fasten.Get(a)
+		p.Set(a, v)
+	}
+	c.SetFasten(p)
+	return c
+}
+
+func TestFork(t *testing.T) {
+	c := newForkCPU()
+	for i := 0; i < 150; i++ {
+		c.Step()
+	}
+	pc := c.GetPC()
+	instret := c.GetCSR().Get(CSRinstret)
+	f, err := c.Fork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if r, err := f.Run(); r != uint8(5050%256) || err != nil {
+			t.Fatal(r, err)
+		}
+		if v, _ := f.GetMemory().GetUint32(0x800); v != 5050 {
+			t.Fatal(v)
+		}
+		f.Reset()
+		if f.GetPC() != pc || f.GetCSR().Get(CSRinstret) != instret || f.GetStatus() != StatusRunning {
+			t.FailNow()
+		}
+		if f.GetSystem().Code() != 0 {
+			t.FailNow()
+		}
+	}
+	// A different input in the fork, the loop ends after adding 100 down to 63.
+	f.SetRegister(Rt1, 1)
+	if r, err := f.Run(); r != uint8(3097%256) || err != nil {
+		t.Fatal(r, err)
+	}
+	// The parent is untouched.
+	if c.GetPC() != pc || c.GetCSR().Get(CSRinstret) != instret || c.GetStatus() != StatusRunning {
+		t.FailNow()
+	}
+	if r, err := c.Run(); r != uint8(5050%256) || err != nil {
+		t.Fatal(r, err)
+	}
+	// The memory must be Paged.
+	if _, err := newSnapshotCPU().Fork(); err != ErrForkUnsupported {
+		t.Fatal(err)
+	}
+}
+
+func TestForkOfFork(t *testing.T) {
+	c := newForkCPU()
+	f, err := c.Fork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetRegister(Ra0, 1)
+	f.GetMemory().SetUint8(0x100, 1)
+	g, err := f.Fork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetRegister(Ra0, 2)
+	g.GetMemory().SetUint8(0x100, 2)
+	// Registers and memory both reset to the last fork.
+	for _, e := range []*CPU{f, g} {
+		e.SetRegister(Ra0, 3)
+		e.GetMemory().SetUint8(0x100, 3)
+		e.Reset()
+		if v, _ := e.GetMemory().GetUint8(0x100); e.GetRegister(Ra0) != 1 || v != 1 {
+			t.Fatal(e.GetRegister(Ra0), v)
+		}
+	}
+	if v, _ := c.GetMemory().GetUint8(0x100); c.GetRegister(Ra0) != 0 || v != 0 {
+		t.FailNow()
+	}
+}
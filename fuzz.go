@@ -0,0 +1,267 @@
+package rv64
+
+//go:generate go run fuzz_edge_gen.go
+
+import (
+	"debug/elf"
+	"fmt"
+	"strings"
+)
+
+const (
+	fuzzStack = 4 * 1024 * 1024 // Memory past the highest segment of an ELF, for the heap and the stack
+	sysRead   = 63
+)
+
+// Crash is a fault of the program on an input: an illegal instruction, an access out of the memory, an unknown
+// system call or any other error stopping the emulator, at the pc of the failing instruction.
+type Crash struct {
+	PC     uint64
+	Reason string
+}
+
+func (e *Crash) Error() string {
+	return fmt.Sprintf("Crash at %#x: %s", e.PC, e.Reason)
+}
+
+// Fuzzer runs a program on the inputs of the Go fuzzing engine. The program is loaded once, each input then runs on a
+// fork of it which is reset in between, so that only the memory pages written by the previous input are restored.
+//
+// An input is either copied to Size bytes of memory at Data, and its length stored as an XLEN-bit integer at Length
+// when not zero, or read by the program from stdin with the read system call when Stdin is set. Each input runs at
+// most Budget cycles, one per instruction without a cost model, a program running out of them is not a crash.
+//
+// The engine only sees the coverage of Go code. The control flow edges of the program, the taken jumps and both ways
+// of the conditional branches, are hashed into fuzzEdges blocks of Go code which the Fuzzer reaches in turn. Inputs
+// reaching new edges, or an edge a new number of times, are kept in the corpus.
+//
+// A Fuzzer is not safe for concurrent use, the engine runs the inputs of a process one after the other.
+type Fuzzer struct {
+	Budget uint64
+	Data   uint64
+	Size   uint64
+	Length uint64
+	Stdin  bool
+
+	cpu     *CPU
+	fork    *CPU
+	symbols map[string]Symbol
+}
+
+// GetCPU returns the CPU the inputs are forked from. Changes made to it after the first input are not seen.
+func (f *Fuzzer) GetCPU() *CPU {
+	return f.cpu
+}
+
+// SetInputSymbols makes the inputs copied to the symbol data and their length stored at the symbol length, which may
+// be empty, of the ELF program. For example, in C:
+//
+//	unsigned char fuzz_data[4096];
+//	unsigned long fuzz_size;
+func (f *Fuzzer) SetInputSymbols(data string, length string) error {
+	d, ok := f.symbols[data]
+	if !ok {
+		return ErrSymbolNotFound
+	}
+	f.Data = d.Addr
+	f.Size = d.Size
+	f.Length = 0
+	if length != "" {
+		l, ok := f.symbols[length]
+		if !ok {
+			return ErrSymbolNotFound
+		}
+		f.Length = l.Addr
+	}
+	return nil
+}
+
+// inject writes the input to the memory of the fork.
+func (f *Fuzzer) inject(data []byte) error {
+	c := f.fork
+	if f.Size == 0 {
+		return nil
+	}
+	if uint64(len(data)) > f.Size {
+		data = data[:f.Size]
+	}
+	if err := c.GetMemory().SetByte(f.Data, data); err != nil {
+		return err
+	}
+	if f.Length == 0 {
+		return nil
+	}
+	if c.GetXLEN() == 32 {
+		return c.GetMemory().SetUint32(f.Length, uint32(len(data)))
+	}
+	return c.GetMemory().SetUint64(f.Length, uint64(len(data)))
+}
+
+// Exec runs the program on an input and returns its exit code. A fault of the program is returned as a *Crash, which
+// fails the fuzz target so that the engine saves the input to testdata/fuzz:
+//
+//	func FuzzParser(f *testing.F) {
+//		fz, err := rv64.NewFuzzerELF("testdata/parser")
+//		...
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if _, err := fz.Exec(data); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+func (f *Fuzzer) Exec(data []byte) (code uint8, err error) {
+	if f.fork == nil {
+		n, err := f.cpu.Fork()
+		if err != nil {
+			return 0, err
+		}
+		f.fork = n
+	}
+	c := f.fork
+	c.Reset()
+	if err := f.inject(data); err != nil {
+		return 0, err
+	}
+	c.SetCycleLimit(0)
+	if f.Budget != 0 {
+		c.SetCycleLimit(c.GetCSR().Get(CSRcycle) + f.Budget)
+	}
+	// Reset expects the SystemStandard of the fork back.
+	system := c.GetSystem().(*SystemStandard)
+	if f.Stdin {
+		c.SetSystem(&fuzzStdin{SystemStandard: system, data: data})
+	}
+	defer c.SetSystem(system)
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Crash{PC: c.GetPC(), Reason: strings.TrimSpace(fmt.Sprint(r))}
+		}
+	}()
+	for c.GetStatus() != StatusExit {
+		pc := c.GetPC()
+		// A failed fetch makes Step panic.
+		i, _ := c.PipelineInstructionFetch()
+		n := c.Step()
+		if c.GetStatus() == StatusOutOfGas {
+			break
+		}
+		if t, ok := c.fasten.(Ticker); ok {
+			t.Tick(n)
+		}
+		if t, ok := c.GetSystem().(Ticker); ok {
+			t.Tick(n)
+		}
+		next := c.GetPC()
+		if next != pc+uint64(len(i)) || fuzzBranch(i) {
+			fuzzEdge(fuzzHash(pc, next) % fuzzEdges)
+		}
+	}
+	return c.GetSystem().Code(), nil
+}
+
+// fuzzBranch reports whether the instruction is a conditional branch, whose fall through is an edge as well.
+func fuzzBranch(i []byte) bool {
+	if len(i) == 2 {
+		// c.beqz and c.bnez.
+		return i[0]&0b11 == 0b01 && i[1]>>6 == 0b11
+	}
+	return len(i) == 4 && i[0]&0x7f == 0b1100011
+}
+
+// fuzzHash mixes the two ends of an edge, the order matters.
+func fuzzHash(a uint64, b uint64) uint64 {
+	h := (a ^ b<<1) * 0x9e3779b97f4a7c15
+	return h ^ h>>32
+}
+
+// fuzzStdin serves the input of a Fuzzer to the read system call on fd 0.
+type fuzzStdin struct {
+	*SystemStandard
+	data []byte
+}
+
+func (s *fuzzStdin) HandleCall(c *CPU) (uint64, error) {
+	if c.GetRegister(Ra7) != sysRead || c.GetRegister(Ra0) != 0 {
+		return s.SystemStandard.HandleCall(c)
+	}
+	n := c.GetRegister(Ra2)
+	if n > uint64(len(s.data)) {
+		n = uint64(len(s.data))
+	}
+	if err := c.GetMemory().SetByte(c.GetRegister(Ra1), s.data[:n]); err != nil {
+		return 0, err
+	}
+	s.data = s.data[n:]
+	return s.ret(c, n)
+}
+
+// NewFuzzer returns a Fuzzer running the inputs from the current state of a standalone CPU, which must be able to
+// Fork.
+func NewFuzzer(c *CPU) (*Fuzzer, error) {
+	if _, ok := c.fasten.(*Paged); !ok {
+		return nil, ErrForkUnsupported
+	}
+	if _, ok := c.system.(*SystemStandard); !ok {
+		return nil, ErrForkUnsupported
+	}
+	return &Fuzzer{cpu: c, symbols: map[string]Symbol{}}, nil
+}
+
+// NewFuzzerELF loads an ELF program into a Paged memory reaching 4 MiB past its highest segment, with argc and argv
+// at the top of the stack, and returns a Fuzzer running it from its entry point. Its symbols can hold the input.
+func NewFuzzerELF(name string) (*Fuzzer, error) {
+	e, err := elf.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	c.SetSystem(NewSystemStandard())
+	if e.Class == elf.ELFCLASS32 {
+		c.SetXLEN(32)
+	}
+	var top uint64
+	for _, p := range e.Progs {
+		if p.Type == elf.PT_LOAD && p.Vaddr+p.Memsz > top {
+			top = p.Vaddr + p.Memsz
+		}
+	}
+	c.SetFasten(NewPaged(top + fuzzStack))
+	for _, p := range e.Progs {
+		if p.Type == elf.PT_LOAD {
+			mem := make([]byte, p.Memsz)
+			if _, err := p.ReadAt(mem[:p.Filesz], 0); err != nil {
+				return nil, err
+			}
+			if err := c.GetMemory().SetByte(p.Vaddr, mem); err != nil {
+				return nil, err
+			}
+		}
+	}
+	c.SetPC(e.Entry)
+	// The stack holds argc, argv and an empty envp, aligned to 16 bytes.
+	c.SetRegister(Rsp, c.GetMemory().Len())
+	c.PushString(name)
+	argv := c.GetRegister(Rsp)
+	c.SetRegister(Rsp, argv&^15)
+	for _, v := range []uint64{0, 0, argv, 1} {
+		if c.GetXLEN() == 32 {
+			c.PushUint32(uint32(v))
+		} else {
+			c.PushUint64(v)
+		}
+	}
+	f, err := NewFuzzer(c)
+	if err != nil {
+		return nil, err
+	}
+	// Symbols are optional.
+	syms, _ := e.Symbols()
+	for _, s := range syms {
+		if s.Value != 0 {
+			f.symbols[s.Name] = Symbol{Name: s.Name, Addr: s.Value, Size: s.Size}
+		}
+	}
+	return f, nil
+}
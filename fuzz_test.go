@@ -0,0 +1,159 @@
+package rv64
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fuzzProgram returns a program checking whether its input, at 0x11000 with its length at 0x11100 or read from stdin,
+// starts with "RV!", in which case it executes an illegal instruction. It exits with the length of the input.
+func fuzzProgram(stdin bool) []uint64 {
+	lui := uint64(0x11<<12 | Ra1<<7 | 0b0110111)
+	prog := []uint64{
+		lui, // lui a1, 0x11
+		encodeI(0b0000011, 0b011, 0x100, Ra2, Ra1), // ld a2, 0x100(a1)
+	}
+	if stdin {
+		prog = []uint64{
+			lui,                       // lui a1, 0x11
+			addi(Ra0, Rzero, 0),       // li a0, 0
+			addi(Ra2, Rzero, 16),      // li a2, 16
+			addi(Ra7, Rzero, sysRead), // li a7, read
+			encodeI(0b1110011, 0b000, 0, Rzero, Rzero), // ecall
+			addi(Ra2, Ra0, 0),                          // mv a2, a0
+		}
+	}
+	return append(prog, []uint64{
+		addi(Rt0, Rzero, 3),                        // 0x00 li t0, 3
+		encodeB(0b100, Ra2, Rt0, 0x2c),             // 0x04 blt a2, t0, 0x30
+		encodeI(0b0000011, 0b100, 0, Rt1, Ra1),     // 0x08 lbu t1, 0(a1)
+		addi(Rt0, Rzero, 'R'),                      // 0x0c li t0, 'R'
+		encodeB(0b001, Rt1, Rt0, 0x20),             // 0x10 bne t1, t0, 0x30
+		encodeI(0b0000011, 0b100, 1, Rt1, Ra1),     // 0x14 lbu t1, 1(a1)
+		addi(Rt0, Rzero, 'V'),                      // 0x18 li t0, 'V'
+		encodeB(0b001, Rt1, Rt0, 0x14),             // 0x1c bne t1, t0, 0x30
+		encodeI(0b0000011, 0b100, 2, Rt1, Ra1),     // 0x20 lbu t1, 2(a1)
+		addi(Rt0, Rzero, '!'),                      // 0x24 li t0, '!'
+		encodeB(0b001, Rt1, Rt0, 0x08),             // 0x28 bne t1, t0, 0x30
+		0b0001011,                                  // 0x2c custom-0, illegal
+		addi(Ra0, Ra2, 0),                          // 0x30 mv a0, a2
+		addi(Ra7, Rzero, sysExitGroup),             // 0x34 li a7, exit_group
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero), // 0x38 ecall
+	}...)
+}
+
+// writeFuzzELF writes an ELF program loading the instructions at 0x10000, in a segment of 8 KiB.
+func writeFuzzELF(dir string, prog []uint64) (string, error) {
+	code := make([]byte, 4*len(prog))
+	for i, e := range prog {
+		binary.LittleEndian.PutUint32(code[4*i:], uint32(e))
+	}
+	h := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_RISCV),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     0x10000,
+		Phoff:     64,
+		Ehsize:    64,
+		Phentsize: 56,
+		Phnum:     1,
+	}
+	copy(h.Ident[:], elf.ELFMAG)
+	h.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	h.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	h.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	p := elf.Prog64{
+		Type:   uint32(elf.PT_LOAD),
+		Flags:  uint32(elf.PF_R | elf.PF_W | elf.PF_X),
+		Off:    120,
+		Vaddr:  0x10000,
+		Paddr:  0x10000,
+		Filesz: uint64(len(code)),
+		Memsz:  0x2000,
+	}
+	b := &bytes.Buffer{}
+	binary.Write(b, binary.LittleEndian, h)
+	binary.Write(b, binary.LittleEndian, p)
+	b.Write(code)
+	name := filepath.Join(dir, "fuzz")
+	return name, os.WriteFile(name, b.Bytes(), 0644)
+}
+
+func newTestFuzzer(dir string, stdin bool) (*Fuzzer, error) {
+	name, err := writeFuzzELF(dir, fuzzProgram(stdin))
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFuzzerELF(name)
+	if err != nil {
+		return nil, err
+	}
+	if stdin {
+		f.Stdin = true
+	} else {
+		f.Data = 0x11000
+		f.Size = 16
+		f.Length = 0x11100
+	}
+	return f, nil
+}
+
+func TestFuzzer(t *testing.T) {
+	for _, stdin := range []bool{false, true} {
+		f, err := newTestFuzzer(t.TempDir(), stdin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r, err := f.Exec([]byte("RVx")); r != 3 || err != nil {
+			t.Fatal(r, err)
+		}
+		// Longer inputs are truncated, or read partially.
+		if r, err := f.Exec(bytes.Repeat([]byte{'a'}, 20)); r != 16 || err != nil {
+			t.Fatal(r, err)
+		}
+		_, err = f.Exec([]byte("RV!"))
+		if e, ok := err.(*Crash); !ok || e.PC != f.GetCPU().GetPC()+uint64(len(fuzzProgram(stdin))-4)*4 {
+			t.Fatal(err)
+		}
+		// The crash does not outlive its input.
+		if r, err := f.Exec([]byte("RV")); r != 2 || err != nil {
+			t.Fatal(r, err)
+		}
+		// Running out of the budget is not a crash.
+		f.Budget = 5
+		if _, err := f.Exec([]byte("RV!")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := newTestFuzzer(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetInputSymbols("fuzz_data", ""); err != ErrSymbolNotFound {
+		t.Fatal(err)
+	}
+	// The memory must be Paged.
+	if _, err := NewFuzzer(newSnapshotCPU()); err != ErrForkUnsupported {
+		t.Fatal(err)
+	}
+}
+
+func FuzzFuzzer(f *testing.F) {
+	fz, err := newTestFuzzer(f.TempDir(), false)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add([]byte(""))
+	f.Add([]byte("RV"))
+	f.Add([]byte("RVx"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, err := fz.Exec(data)
+		if (err != nil) != bytes.HasPrefix(data, []byte("RV!")) {
+			t.Fatal(data, err)
+		}
+	})
+}
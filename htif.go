@@ -0,0 +1,141 @@
+package rv64
+
+import (
+	"fmt"
+	"io"
+)
+
+// The Host-Target Interface is the way riscv-tests "-p-" binaries, the proxy kernel and many bare-metal programs talk
+// to the host. The target writes a command to the tohost symbol and, if the command has a response, waits for the
+// host to write it to the fromhost symbol.
+//
+// |63      56|55      48|47                                                                                      0|
+// | device   | command  | payload                                                                                 |
+//
+// Device Command Description
+// 0      0       Syscall proxy. If bit 0 of the payload is set the program exits with code payload >> 1, otherwise
+//                the payload is the address of 8 uint64 holding the syscall number and its arguments, the return
+//                value is written to the first of them.
+// 1      0       Console, read a character.
+// 1      1       Console, write the character in the low 8 bits of the payload.
+//
+// Test binaries exit with code 0 on success and with the number of the failed test otherwise.
+
+const (
+	HtifDeviceSyscall = 0
+	HtifDeviceConsole = 1
+)
+
+// Htif is a System for programs using the Host-Target Interface. Environment calls are not handled by the host, they
+// trap into the program like on bare metal.
+type Htif struct {
+	cpu      *CPU
+	tohost   uint64
+	fromhost uint64
+	r        io.Reader
+	w        io.Writer
+	exit     uint64
+}
+
+func (h *Htif) HandleCall(c *CPU) (uint64, error) {
+	c.Trap(ExceptionEcallM, 0)
+	return 1, nil
+}
+
+// Code returns the exit code of the program, codes which do not fit in a byte are reported as 0xff.
+func (h *Htif) Code() uint8 {
+	if h.exit > 0xff {
+		return 0xff
+	}
+	return uint8(h.exit)
+}
+
+func (h *Htif) syscall(mem *Memory, a uint64) error {
+	args := make([]uint64, 8)
+	for i := range args {
+		v, err := mem.GetUint64(a + uint64(i)*8)
+		if err != nil {
+			return err
+		}
+		args[i] = v
+	}
+	var r uint64
+	switch args[0] {
+	case 64:
+		// write(fd, buf, count)
+		if args[1] != 1 && args[1] != 2 {
+			r = uint64(0xfffffffffffffff7) // -EBADF
+			break
+		}
+		b, err := mem.GetByte(args[2], args[3])
+		if err != nil {
+			return err
+		}
+		h.w.Write(b)
+		r = args[3]
+	case 93:
+		// exit(code)
+		h.exit = args[1]
+		h.cpu.SetStatus(StatusExit)
+	default:
+		r = uint64(0xffffffffffffffda) // -ENOSYS
+	}
+	return mem.SetUint64(a, r)
+}
+
+func (h *Htif) respond(mem *Memory, v uint64) {
+	if h.fromhost != 0 {
+		mem.SetUint64(h.fromhost, v)
+	}
+}
+
+// Tick polls tohost, executes the command found there and clears it.
+func (h *Htif) Tick(n uint64) {
+	mem := &Memory{Fasten: h.cpu.fasten}
+	cmd, err := mem.GetUint64(h.tohost)
+	if err != nil || cmd == 0 {
+		return
+	}
+	mem.SetUint64(h.tohost, 0)
+	device := InstructionPart(cmd, 56, 63)
+	command := InstructionPart(cmd, 48, 55)
+	payload := InstructionPart(cmd, 0, 47)
+	switch device {
+	case HtifDeviceSyscall:
+		if payload&1 != 0 {
+			h.exit = payload >> 1
+			if h.exit != 0 {
+				Println(fmt.Sprintf("*** FAILED *** (tohost = %d)", h.exit))
+			}
+			h.cpu.SetStatus(StatusExit)
+			return
+		}
+		if err := h.syscall(mem, payload); err != nil {
+			Panicln(err)
+		}
+		h.respond(mem, device<<56|command<<48|1)
+	case HtifDeviceConsole:
+		switch command {
+		case 0:
+			b := make([]byte, 1)
+			if n, _ := h.r.Read(b); n == 1 {
+				h.respond(mem, device<<56|command<<48|0x100|uint64(b[0]))
+			}
+		case 1:
+			h.w.Write([]byte{byte(payload)})
+			h.respond(mem, device<<56|command<<48|0x100|payload&0xff)
+		}
+	}
+}
+
+// NewHtif returns a HTIF for a program whose tohost and fromhost symbols are at the given physical addresses, a
+// missing fromhost is 0. The console reads from r and writes to w.
+func NewHtif(c *CPU, tohost uint64, fromhost uint64, r io.Reader, w io.Writer) *Htif {
+	return &Htif{
+		cpu:      c,
+		tohost:   tohost,
+		fromhost: fromhost,
+		r:        r,
+		w:        w,
+	}
+}
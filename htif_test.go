@@ -0,0 +1,69 @@
+package rv64
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHtif(t *testing.T) {
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	c.SetFasten(NewLinear(1024 * 1024))
+	out := &bytes.Buffer{}
+	h := NewHtif(c, 0x1000, 0x1040, bytes.NewReader([]byte("x")), out)
+	c.SetSystem(h)
+	mem := c.GetMemory()
+
+	// Console.
+	mem.SetUint64(0x1000, HtifDeviceConsole<<56|1<<48|'A')
+	h.Tick(1)
+	if out.String() != "A" {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint64(0x1000); v != 0 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint64(0x1040); v != HtifDeviceConsole<<56|1<<48|0x100|'A' {
+		t.FailNow()
+	}
+	mem.SetUint64(0x1000, HtifDeviceConsole<<56|0<<48)
+	h.Tick(1)
+	if v, _ := mem.GetUint64(0x1040); v != HtifDeviceConsole<<56|0x100|'x' {
+		t.FailNow()
+	}
+
+	// Syscall proxy, write(1, "hi", 2).
+	mem.SetByte(0x3000, []byte("hi"))
+	mem.SetUint64(0x2000, 64)
+	mem.SetUint64(0x2008, 1)
+	mem.SetUint64(0x2010, 0x3000)
+	mem.SetUint64(0x2018, 2)
+	mem.SetUint64(0x1000, 0x2000)
+	h.Tick(1)
+	if out.String() != "Ahi" {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint64(0x2000); v != 2 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint64(0x1040); v != 1 {
+		t.FailNow()
+	}
+
+	// Environment calls trap into the program.
+	c.GetCSR().Set(CSRmtvec, 0x4000)
+	c.SetPC(0x5000)
+	if _, err := c.PipelineExecute([]byte{0x73, 0x00, 0x00, 0x00}); err != nil {
+		t.FailNow()
+	}
+	if c.GetPC() != 0x4000 || c.GetCSR().Get(CSRmcause) != ExceptionEcallM || c.GetCSR().Get(CSRmepc) != 0x5000 {
+		t.FailNow()
+	}
+
+	// Test 3 failed.
+	mem.SetUint64(0x1000, 3<<1|1)
+	h.Tick(1)
+	if c.GetStatus() != 1 || h.Code() != 3 {
+		t.FailNow()
+	}
+}
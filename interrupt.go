@@ -0,0 +1,86 @@
+package rv64
+
+// https://github.com/riscv/riscv-isa-manual/releases/download/Priv-v1.12/riscv-privileged-20211203.pdf
+// Chapter 3.1.6, 3.1.7, 3.1.9, 3.1.15
+//
+// Interrupts are checked between instructions. An interrupt i is taken if bit i is set in both mip and mie, and
+// interrupts are globally enabled by mstatus.MIE. Multiple simultaneous interrupts are taken in the following
+// decreasing priority order: MEI, MSI, MTI, SEI, SSI, STI.
+//
+// The emulator does not model privilege levels, every trap is taken into machine mode and mideleg is ignored.
+
+const (
+	MstatusSIE  = 1 << 1
+	MstatusMIE  = 1 << 3
+	MstatusSPIE = 1 << 5
+	MstatusMPIE = 1 << 7
+	MstatusMPP  = 3 << 11
+)
+
+const (
+	InterruptSSI = 1  // Supervisor software interrupt
+	InterruptMSI = 3  // Machine software interrupt
+	InterruptSTI = 5  // Supervisor timer interrupt
+	InterruptMTI = 7  // Machine timer interrupt
+	InterruptSEI = 9  // Supervisor external interrupt
+	InterruptMEI = 11 // Machine external interrupt
+)
+
+const (
+	ExceptionEcallU = 8  // Environment call from U-mode
+	ExceptionEcallS = 9  // Environment call from S-mode
+	ExceptionEcallM = 11 // Environment call from M-mode
+)
+
+// SetInterruptPending sets or clears bit i of mip. It is used by the interrupt controllers to drive the read-only bits
+// of mip.
+func (c *CPU) SetInterruptPending(i uint64, b int) {
+	mip := c.GetCSR().Get(CSRmip)
+	if b == 0 {
+		c.GetCSR().Set(CSRmip, mip&^(1<<i))
+	} else {
+		c.GetCSR().Set(CSRmip, mip|(1<<i))
+	}
+}
+
+// Trap transfers control to the trap handler in mtvec. The cause is the mcause value, with the interrupt bit set for
+// interrupts.
+func (c *CPU) Trap(cause uint64, tval uint64) {
+	csr := c.GetCSR()
+	mstatus := csr.Get(CSRmstatus)
+	mpie := uint64(0)
+	if mstatus&MstatusMIE != 0 {
+		mpie = MstatusMPIE
+	}
+	csr.Set(CSRmstatus, mstatus&^(MstatusMIE|MstatusMPIE)|mpie|MstatusMPP)
+	csr.Set(CSRmepc, c.GetPC())
+	csr.Set(CSRmcause, cause)
+	csr.Set(CSRmtval, tval)
+	mtvec := csr.Get(CSRmtvec)
+	base := mtvec &^ 3
+	// When MODE=Vectored, asynchronous interrupts set pc to BASE+4×cause.
+	if mtvec&3 == 1 && cause>>63 != 0 {
+		c.SetPC(base + 4*(cause&^(1<<63)))
+		return
+	}
+	c.SetPC(base)
+}
+
+// Interrupt takes the highest priority pending and enabled interrupt, if any. It reports whether a trap was taken.
+func (c *CPU) Interrupt() bool {
+	if c.GetCSR().Get(CSRmstatus)&MstatusMIE == 0 {
+		return false
+	}
+	p := c.GetCSR().Get(CSRmip) & c.GetCSR().Get(CSRmie)
+	if p == 0 {
+		return false
+	}
+	for _, i := range []uint64{InterruptMEI, InterruptMSI, InterruptMTI, InterruptSEI, InterruptSSI, InterruptSTI} {
+		if p&(1<<i) != 0 {
+			Debugln("Interrupt:", i)
+			c.Trap(1<<63|i, 0)
+			return true
+		}
+	}
+	return false
+}
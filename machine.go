@@ -0,0 +1,310 @@
+package rv64
+
+import (
+	"sync"
+)
+
+// Machine hosts several harts sharing one memory and one System. Each hart is a CPU with its own registers, CSRs and
+// load reservation. By default the harts are scheduled round-robin, each one executes a quantum of instructions before
+// the next one runs, so an execution is fully reproducible. In parallel mode every hart runs on its own goroutine and
+// the interleaving is decided by the Go scheduler.
+//
+// The timers of the shared devices and of the System advance with the cycles of every hart. A hart whose status is
+// StatusHalt is no longer scheduled, the machine stops as soon as a hart reaches StatusExit or StatusOutOfGas, or every
+// hart is halted. The cycle limit of each hart applies to its own cycle CSR.
+//
+// A hart whose status is StatusWait is parked, its time CSR follows the timers until the System wakes it or its
+// deadline passes. When every hart left is parked, time jumps to the nearest deadline, and without one the machine
+// stops with ErrDeadlock.
+type Machine struct {
+	harts    []*CPU
+	fasten   Fasten
+	system   System
+	quantum  uint64
+	parallel bool
+	mu       sync.Mutex
+	cond     *sync.Cond
+	wg       sync.WaitGroup
+	running  bool
+	done     bool
+	err      error
+}
+
+// waiter is a System which parks harts with StatusWait.
+type waiter interface {
+	// deadline returns the value of the time CSR of the parked hart c at which it times out, 0 for none.
+	deadline(c *CPU) uint64
+}
+
+// GetHart returns the hart whose mhartid is i.
+func (m *Machine) GetHart(i uint64) *CPU { return m.harts[i] }
+
+// GetHarts returns the harts of the machine, ordered by mhartid.
+func (m *Machine) GetHarts() []*CPU { return m.harts }
+
+// GetQuantum returns the number of instructions a hart executes before the round-robin scheduler switches to the
+// next one.
+func (m *Machine) GetQuantum() uint64 { return m.quantum }
+
+// SetQuantum sets the quantum of the scheduler, a quantum of 0 is taken as 1 so that the harts make progress.
+func (m *Machine) SetQuantum(n uint64) {
+	if n == 0 {
+		n = 1
+	}
+	m.quantum = n
+}
+
+// GetParallel reports whether each hart runs on its own goroutine.
+func (m *Machine) GetParallel() bool  { return m.parallel }
+func (m *Machine) SetParallel(b bool) { m.parallel = b }
+
+func (m *Machine) GetFasten() Fasten { return m.fasten }
+func (m *Machine) SetFasten(f Fasten) {
+	m.fasten = f
+	for _, c := range m.harts {
+		c.SetFasten(&hartFasten{Fasten: f, machine: m, cpu: c})
+	}
+}
+
+func (m *Machine) GetSystem() System { return m.system }
+func (m *Machine) SetSystem(s System) {
+	m.system = s
+	for _, c := range m.harts {
+		c.SetSystem(s)
+	}
+}
+
+// Clone adds a hart which is a copy of c. Registers, CSRs and configuration are copied, the load reservation is not.
+// The new hart is scheduled after the existing ones.
+func (m *Machine) Clone(c *CPU) *CPU {
+	n := c.duplicate()
+	n.lraddr = 0
+	n.hartid = uint64(len(m.harts))
+	n.origin = nil
+	n.fasten = &hartFasten{Fasten: m.fasten, machine: m, cpu: n}
+	m.harts = append(m.harts, n)
+	if m.running && m.parallel {
+		m.spawn(n)
+	}
+	return n
+}
+
+// exited reports whether the machine has to stop.
+func (m *Machine) exited() bool {
+	for _, c := range m.harts {
+		if c.GetStatus() == StatusExit || c.GetStatus() == StatusOutOfGas {
+			return true
+		}
+	}
+	for _, c := range m.harts {
+		if c.GetStatus() != StatusHalt {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidate clears the load reservation of every hart but c whose reservation granule overlaps the l bytes written
+// at a. A store from another hart makes a pending sc fail.
+func (m *Machine) invalidate(c *CPU, a uint64, l uint64) {
+	for _, e := range m.harts {
+		if e == c || e.GetLoadReservation() == 0 {
+			continue
+		}
+		r := e.GetLoadReservation() &^ 7
+		if a < r+8 && r < a+l {
+			e.SetLoadReservation(0)
+		}
+	}
+}
+
+// tick advances the timers by n cycles, along with the time CSR of the parked harts. A parked hart whose deadline
+// passed is scheduled again.
+func (m *Machine) tick(n uint64) {
+	if t, ok := m.fasten.(Ticker); ok {
+		t.Tick(n)
+	}
+	if t, ok := m.system.(Ticker); ok {
+		t.Tick(n)
+	}
+	w, ok := m.system.(waiter)
+	for _, c := range m.harts {
+		if c.GetStatus() != StatusWait {
+			continue
+		}
+		c.GetCSR().Set(CSRtime, c.GetCSR().Get(CSRtime)+n)
+		if ok {
+			if d := w.deadline(c); d != 0 && c.GetCSR().Get(CSRtime) >= d {
+				c.SetStatus(StatusRunning)
+			}
+		}
+	}
+}
+
+// idle is called when no hart is running. When harts are parked, time jumps to the nearest deadline, and without one
+// ErrDeadlock is returned.
+func (m *Machine) idle() error {
+	var n uint64
+	w, ok := m.system.(waiter)
+	for _, c := range m.harts {
+		if c.GetStatus() != StatusWait || !ok {
+			continue
+		}
+		if d := w.deadline(c); d != 0 {
+			if t := c.GetCSR().Get(CSRtime); n == 0 || d-t < n {
+				n = d - t
+			}
+		}
+	}
+	if n == 0 {
+		return ErrDeadlock
+	}
+	m.tick(n)
+	return nil
+}
+
+// runnable reports whether a hart is running.
+func (m *Machine) runnable() bool {
+	for _, c := range m.harts {
+		if c.GetStatus() == StatusRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// result returns the exit code of the System, or ErrOutOfGas when a hart ran out of gas.
+func (m *Machine) result() (uint8, error) {
+	for _, c := range m.harts {
+		if c.GetStatus() == StatusOutOfGas {
+			Debugln("Out of gas")
+			return 0, ErrOutOfGas
+		}
+	}
+	Debugln("Exit:", m.system.Code())
+	return m.system.Code(), nil
+}
+
+// Run executes the harts until the machine stops, and returns the exit code of the System. The error is ErrOutOfGas
+// when a hart ran out of gas, and ErrDeadlock when every hart left waits with no deadline.
+func (m *Machine) Run() (uint8, error) {
+	m.running = true
+	defer func() { m.running = false }()
+	if m.parallel {
+		return m.runParallel()
+	}
+	for !m.exited() {
+		if !m.runnable() {
+			if err := m.idle(); err != nil {
+				return m.system.Code(), err
+			}
+		}
+		// Harts cloned during the round join it.
+		for i := 0; i < len(m.harts); i++ {
+			c := m.harts[i]
+			for j := uint64(0); j < m.quantum && c.GetStatus() == StatusRunning; j++ {
+				m.tick(c.Step())
+			}
+			if c.GetStatus() == StatusExit || c.GetStatus() == StatusOutOfGas {
+				break
+			}
+		}
+	}
+	return m.result()
+}
+
+// runParallel runs each hart on its own goroutine. Instructions are executed under a single lock, so that every
+// instruction, and in particular every atomic memory operation, is seen as a whole by the other harts.
+func (m *Machine) runParallel() (uint8, error) {
+	m.done = false
+	m.err = nil
+	m.mu.Lock()
+	for _, c := range m.harts {
+		m.spawn(c)
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+	if m.err != nil {
+		return m.system.Code(), m.err
+	}
+	return m.result()
+}
+
+// spawn starts the goroutine of the hart c, the caller holds the lock. A parked hart sleeps until another hart
+// steps, the last hart to park moves the time forward.
+func (m *Machine) spawn(c *CPU) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for {
+			if m.done || c.GetStatus() == StatusHalt {
+				m.done = m.done || m.exited()
+				m.cond.Broadcast()
+				return
+			}
+			if c.GetStatus() == StatusExit || c.GetStatus() == StatusOutOfGas {
+				m.done = true
+				m.cond.Broadcast()
+				return
+			}
+			if c.GetStatus() == StatusWait {
+				if m.runnable() {
+					m.cond.Wait()
+				} else if m.err = m.idle(); m.err != nil {
+					m.done = true
+				}
+				continue
+			}
+			m.tick(c.Step())
+			m.cond.Broadcast()
+			// Let the other goroutines take the lock.
+			m.mu.Unlock()
+			m.mu.Lock()
+		}
+	}()
+}
+
+// NewMachine returns a machine with n harts. The harts are configured as by NewCPU and have their own CSRStandard.
+func NewMachine(n uint64) *Machine {
+	m := &Machine{
+		harts:   make([]*CPU, n),
+		quantum: 1,
+	}
+	m.cond = sync.NewCond(&m.mu)
+	for i := uint64(0); i < n; i++ {
+		c := NewCPU()
+		c.SetCSR(NewCSRStandard())
+		c.SetHartID(i)
+		c.mach = m
+		m.harts[i] = c
+	}
+	return m
+}
+
+// hartFasten is the view of the shared memory of a single hart. Writes go through it so that the reservations held by
+// the other harts can be invalidated.
+type hartFasten struct {
+	Fasten
+	machine *Machine
+	cpu     *CPU
+}
+
+func (h *hartFasten) Set(a uint64, v byte) error {
+	h.machine.invalidate(h.cpu, a, 1)
+	return h.Fasten.Set(a, v)
+}
+
+func (h *hartFasten) cacheable(a uint64) bool {
+	return fastenCacheable(h.Fasten, a)
+}
+
+func (h *hartFasten) GetSized(a uint64, l uint64) (uint64, error) {
+	return fastenGet(h.Fasten, a, l)
+}
+
+func (h *hartFasten) SetSized(a uint64, l uint64, v uint64) error {
+	h.machine.invalidate(h.cpu, a, l)
+	return fastenSet(h.Fasten, a, l, v)
+}
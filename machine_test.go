@@ -0,0 +1,147 @@
+package rv64
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func encodeB(funct3 uint64, rs1 uint64, rs2 uint64, imm int64) uint64 {
+	u := uint64(imm)
+	return InstructionPart(u, 12, 12)<<31 | InstructionPart(u, 5, 10)<<25 | rs2<<20 | rs1<<15 | funct3<<12 |
+		InstructionPart(u, 1, 4)<<8 | InstructionPart(u, 11, 11)<<7 | 0b1100011
+}
+
+// newCounterMachine returns a machine whose harts all increment the word at 0x400 n times with lr.w/sc.w, then count
+// themselves done at 0x408. Hart 0 waits for the others and exits with the value of the counter.
+func newCounterMachine(harts uint64, n uint64) *Machine {
+	const amo = 0b0101111
+	prog := []uint64{
+		encodeI(0b1110011, 0b010, CSRmhartid, Rt0, Rzero), // csrr t0, mhartid
+		encodeR(amo, 0b010, 0b00010<<2, Rt2, Ra1, Rzero),  // lr.w t2, (a1)
+		encodeI(0b0010011, 0b000, 1, Rt2, Rt2),            // addi t2, t2, 1
+		encodeR(amo, 0b010, 0b00011<<2, Rt3, Ra1, Rt2),    // sc.w t3, t2, (a1)
+		encodeB(0b001, Rt3, Rzero, -12),                   // bnez t3, 0x04
+		encodeI(0b0010011, 0b000, 0xfff, Rt1, Rt1),        // addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -20),                   // bnez t1, 0x04
+		encodeR(amo, 0b010, 0b00000<<2, Rzero, Ra2, Rt4),  // amoadd.w zero, t4, (a2)
+		encodeB(0b001, Rt0, Rzero, 0),                     // bnez t0, 0x20
+		encodeI(0b0000011, 0b010, 0, Rt5, Ra2),            // lw t5, 0(a2)
+		encodeB(0b001, Rt5, Rs1, -4),                      // bne t5, s1, 0x24
+		encodeI(0b0000011, 0b010, 0, Ra0, Ra1),            // lw a0, 0(a1)
+		encodeI(0b0010011, 0b000, 94, Ra7, Rzero),         // li a7, 94
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero),        // ecall
+	}
+	m := NewMachine(harts)
+	m.SetFasten(NewLinear(0x1000))
+	m.SetSystem(NewSystemStandard())
+	for i, e := range prog {
+		m.GetHart(0).GetMemory().SetUint32(uint64(4*i), uint32(e))
+	}
+	for _, c := range m.GetHarts() {
+		c.SetRegister(Ra1, 0x400)
+		c.SetRegister(Ra2, 0x408)
+		c.SetRegister(Rt1, n)
+		c.SetRegister(Rt4, 1)
+		c.SetRegister(Rs1, harts)
+	}
+	return m
+}
+
+func TestMachine(t *testing.T) {
+	for _, q := range []uint64{0, 1, 3, 100} {
+		m := newCounterMachine(4, 50)
+		m.SetQuantum(q)
+		if r, err := m.Run(); r != 200 || err != nil {
+			t.Fatalf("quantum %d: %d %v", q, r, err)
+		}
+		for i, c := range m.GetHarts() {
+			if c.GetRegister(Rt0) != uint64(i) {
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestMachineDeterministic(t *testing.T) {
+	trace := func() []uint64 {
+		m := newCounterMachine(3, 40)
+		m.SetQuantum(2)
+		m.Run()
+		r := []uint64{}
+		for _, c := range m.GetHarts() {
+			r = append(r, c.GetCSR().Get(CSRinstret), c.GetPC())
+		}
+		return r
+	}
+	a := trace()
+	b := trace()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal(a, b)
+		}
+	}
+}
+
+func TestMachineParallel(t *testing.T) {
+	m := newCounterMachine(4, 50)
+	m.SetParallel(true)
+	if r, err := m.Run(); r != 200 || err != nil {
+		t.Fatal(r, err)
+	}
+}
+
+func TestMachineReservation(t *testing.T) {
+	m := NewMachine(2)
+	m.SetFasten(NewLinear(0x1000))
+	a, b := m.GetHart(0), m.GetHart(1)
+	a.SetLoadReservation(0x100)
+	b.SetLoadReservation(0x100)
+	// A store by the holder itself keeps the reservation.
+	a.GetMemory().SetUint32(0x104, 1)
+	if a.GetLoadReservation() != 0x100 || b.GetLoadReservation() != 0 {
+		t.FailNow()
+	}
+	// Stores outside the reservation granule do not matter.
+	b.GetMemory().SetUint64(0x108, 1)
+	if a.GetLoadReservation() != 0x100 {
+		t.FailNow()
+	}
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, 1)
+	b.GetMemory().SetByte(0xfc, data)
+	if a.GetLoadReservation() != 0 {
+		t.FailNow()
+	}
+}
+
+// tickFasten counts the cycles its timers are given.
+type tickFasten struct {
+	Fasten
+	n uint64
+}
+
+func (f *tickFasten) Tick(n uint64) { f.n += n }
+
+func TestMachineTick(t *testing.T) {
+	// The timers go on once hart 0 stopped.
+	m := NewMachine(2)
+	f := &tickFasten{Fasten: NewLinear(0x1000)}
+	m.SetFasten(f)
+	m.SetSystem(NewSystemStandard())
+	for i, e := range []uint64{
+		addi(Rt1, Rzero, 10),
+		addi(Rt1, Rt1, 0xfff),
+		encodeB(0b001, Rt1, Rzero, -4),
+		addi(Ra7, Rzero, sysExitGroup),
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero),
+	} {
+		m.GetHart(0).GetMemory().SetUint32(uint64(4*i), uint32(e))
+	}
+	m.GetHart(0).SetStatus(StatusHalt)
+	if _, err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if n := m.GetHart(1).GetCSR().Get(CSRcycle); n != 23 || f.n != n {
+		t.Fatal(n, f.n)
+	}
+}
@@ -1,8 +1,46 @@
 package rv64
 
-import (
-	"encoding/binary"
-)
+// SizedFasten is implemented by a Fasten that serves a naturally sized access of 1, 2, 4 or 8 bytes in one operation.
+// Memory-mapped devices need it, since their registers have side effects that must not be repeated for every byte.
+type SizedFasten interface {
+	GetSized(uint64, uint64) (uint64, error)
+	SetSized(uint64, uint64, uint64) error
+}
+
+func fastenGet(f Fasten, a uint64, l uint64) (uint64, error) {
+	if s, ok := f.(SizedFasten); ok {
+		return s.GetSized(a, l)
+	}
+	return fastenGetBytes(f, a, l)
+}
+
+func fastenGetBytes(f Fasten, a uint64, l uint64) (uint64, error) {
+	var r uint64 = 0
+	for i := uint64(0); i < l; i++ {
+		b, err := f.Get(a + i)
+		if err != nil {
+			return 0, err
+		}
+		r |= uint64(b) << (8 * i)
+	}
+	return r, nil
+}
+
+func fastenSet(f Fasten, a uint64, l uint64, v uint64) error {
+	if s, ok := f.(SizedFasten); ok {
+		return s.SetSized(a, l, v)
+	}
+	return fastenSetBytes(f, a, l, v)
+}
+
+func fastenSetBytes(f Fasten, a uint64, l uint64, v uint64) error {
+	for i := uint64(0); i < l; i++ {
+		if err := f.Set(a+i, byte(v>>(8*i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 type Memory struct {
 	Fasten
@@ -31,57 +69,64 @@ func (m *Memory) SetByte(a uint64, b []byte) error {
 }
 
 func (m *Memory) GetUint8(a uint64) (uint8, error) {
-	mem, err := m.Get(a)
+	mem, err := fastenGet(m.Fasten, a, 1)
 	if err != nil {
 		return 0, err
 	}
-	return mem, nil
+	return uint8(mem), nil
 }
 
 func (m *Memory) SetUint8(a uint64, n uint8) error {
-	return m.Set(a, n)
+	return fastenSet(m.Fasten, a, 1, uint64(n))
 }
 
 func (m *Memory) GetUint16(a uint64) (uint16, error) {
-	mem, err := m.GetByte(a, 2)
+	mem, err := fastenGet(m.Fasten, a, 2)
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint16(mem), nil
+	return uint16(mem), nil
 }
 
 func (m *Memory) SetUint16(a uint64, n uint16) error {
-	mem := make([]byte, 2)
-	binary.LittleEndian.PutUint16(mem, n)
-	return m.SetByte(a, mem)
+	return fastenSet(m.Fasten, a, 2, uint64(n))
 }
 
 func (m *Memory) GetUint32(a uint64) (uint32, error) {
-	mem, err := m.GetByte(a, 4)
+	mem, err := fastenGet(m.Fasten, a, 4)
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint32(mem), nil
+	return uint32(mem), nil
 }
 
 func (m *Memory) SetUint32(a uint64, n uint32) error {
-	mem := make([]byte, 4)
-	binary.LittleEndian.PutUint32(mem, n)
-	return m.SetByte(a, mem)
+	return fastenSet(m.Fasten, a, 4, uint64(n))
 }
 
 func (m *Memory) GetUint64(a uint64) (uint64, error) {
-	mem, err := m.GetByte(a, 8)
+	mem, err := fastenGet(m.Fasten, a, 8)
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint64(mem), nil
+	return mem, nil
 }
 
 func (m *Memory) SetUint64(a uint64, n uint64) error {
-	mem := make([]byte, 8)
-	binary.LittleEndian.PutUint64(mem, n)
-	return m.SetByte(a, mem)
+	return fastenSet(m.Fasten, a, 8, n)
+}
+
+// SetZero clears l bytes starting at a.
+func (m *Memory) SetZero(a uint64, l uint64) error {
+	if l%8 != 0 {
+		return m.SetByte(a, make([]byte, l))
+	}
+	for i := uint64(0); i < l; i += 8 {
+		if err := fastenSet(m.Fasten, a+i, 8, 0); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func NewMemoryLinear(size uint64) *Memory {
@@ -38,3 +38,15 @@ func NaNGnixob(f float64) float32 {
 	}
 	return math.Float32frombits(uint32(u))
 }
+
+func NaNBoxing16(h uint16) float64 {
+	return math.Float64frombits(0xffffffffffff0000 | uint64(h))
+}
+
+func NaNGnixob16(f float64) uint16 {
+	u := math.Float64bits(f)
+	if (u >> 16) != 0xffffffffffff {
+		return NaN16
+	}
+	return uint16(u)
+}
@@ -0,0 +1,192 @@
+package rv64
+
+// https://github.com/riscv/riscv-plic-spec/blob/master/riscv-plic.adoc
+//
+// The platform-level interrupt controller routes the interrupt lines of the peripherals to the harts. Two contexts
+// are provided, context 0 is the machine mode of hart 0 and drives MEIP, context 1 is the supervisor mode of hart 0
+// and drives SEIP.
+//
+// Offset    Name       Description
+// 0x000000  priority   Priority of the interrupt source n at 0x000000+4*n, source 0 does not exist.
+// 0x001000  pending    Pending bits of the interrupt sources, 32 sources per word.
+// 0x002000  enable     Enable bits of the interrupt sources for context n at 0x002000+0x80*n.
+// 0x200000  threshold  Priority threshold for context n at 0x200000+0x1000*n.
+// 0x200004  claim      Claim/complete for context n at 0x200004+0x1000*n.
+//
+// Interrupt lines are level triggered. A claimed source is not pending again until its completion is signaled, and
+// only then if the line is still asserted.
+
+const (
+	PlicBase      = 0x0c000000
+	PlicSize      = 0x04000000
+	PlicPriority  = 0x000000
+	PlicPending   = 0x001000
+	PlicEnable    = 0x002000
+	PlicThreshold = 0x200000
+	PlicClaim     = 0x200004
+	PlicSources   = 1024
+	PlicContexts  = 2
+)
+
+// Interrupter is the interface used by peripherals to assert and deassert their interrupt lines.
+type Interrupter interface {
+	Assert(uint64)
+	Deassert(uint64)
+}
+
+// Plic is the platform-level interrupt controller device of a CPU.
+type Plic struct {
+	cpu       *CPU
+	priority  [PlicSources]uint64
+	pending   [PlicSources / 32]uint64
+	enable    [PlicContexts][PlicSources / 32]uint64
+	threshold [PlicContexts]uint64
+	claim     [PlicContexts]uint64
+	level     [PlicSources]bool
+	service   [PlicSources]bool
+}
+
+func (p *Plic) Assert(i uint64) {
+	if i == 0 || i >= PlicSources {
+		return
+	}
+	p.level[i] = true
+	if !p.service[i] {
+		p.pending[i/32] |= 1 << (i % 32)
+	}
+	p.Update()
+}
+
+func (p *Plic) Deassert(i uint64) {
+	if i == 0 || i >= PlicSources {
+		return
+	}
+	p.level[i] = false
+	p.pending[i/32] &^= 1 << (i % 32)
+	p.Update()
+}
+
+// best returns the pending and enabled source with the highest priority above the threshold of the context, or 0.
+// Ties are broken by the lowest source id.
+func (p *Plic) best(ctx uint64) uint64 {
+	var r uint64 = 0
+	var m uint64 = p.threshold[ctx]
+	for i := uint64(1); i < PlicSources; i++ {
+		if p.pending[i/32]&p.enable[ctx][i/32]&(1<<(i%32)) == 0 {
+			continue
+		}
+		if p.priority[i] > m {
+			r = i
+			m = p.priority[i]
+		}
+	}
+	return r
+}
+
+// Update drives MEIP and SEIP of mip from the current state of the PLIC.
+func (p *Plic) Update() {
+	for ctx, irq := range []uint64{InterruptMEI, InterruptSEI} {
+		if p.best(uint64(ctx)) != 0 {
+			p.cpu.SetInterruptPending(irq, 1)
+		} else {
+			p.cpu.SetInterruptPending(irq, 0)
+		}
+	}
+}
+
+func (p *Plic) complete(ctx uint64, i uint64) {
+	if i == 0 || i >= PlicSources || p.enable[ctx][i/32]&(1<<(i%32)) == 0 {
+		return
+	}
+	p.service[i] = false
+	if p.level[i] {
+		p.pending[i/32] |= 1 << (i % 32)
+	}
+}
+
+// register returns the 32-bit register at the offset.
+func (p *Plic) register(a uint64) *uint64 {
+	switch {
+	case a >= PlicPriority && a < PlicPriority+4*PlicSources:
+		return &p.priority[(a-PlicPriority)/4]
+	case a >= PlicPending && a < PlicPending+PlicSources/8:
+		return &p.pending[(a-PlicPending)/4]
+	case a >= PlicEnable && a < PlicEnable+0x80*PlicContexts:
+		ctx := (a - PlicEnable) / 0x80
+		o := (a - PlicEnable) % 0x80
+		if o >= PlicSources/8 {
+			return nil
+		}
+		return &p.enable[ctx][o/4]
+	case a >= PlicThreshold && a < PlicThreshold+0x1000*PlicContexts:
+		ctx := (a - PlicThreshold) / 0x1000
+		switch (a - PlicThreshold) % 0x1000 {
+		case 0:
+			return &p.threshold[ctx]
+		case 4:
+			return &p.claim[ctx]
+		}
+	}
+	return nil
+}
+
+func (p *Plic) isClaim(a uint64) bool {
+	return a >= PlicThreshold && a < PlicThreshold+0x1000*PlicContexts && (a-PlicThreshold)%0x1000 == 4
+}
+
+// Read reads a 32-bit register, other sizes are not supported by the PLIC and read as zero.
+func (p *Plic) Read(offset uint64, size uint64) (uint64, error) {
+	if size != 4 || offset%4 != 0 {
+		return 0, nil
+	}
+	r := p.register(offset)
+	if r == nil {
+		return 0, nil
+	}
+	// Reading the claim register claims the interrupt.
+	if p.isClaim(offset) {
+		ctx := (offset - PlicThreshold) / 0x1000
+		i := p.best(ctx)
+		if i != 0 {
+			p.pending[i/32] &^= 1 << (i % 32)
+			p.service[i] = true
+		}
+		p.claim[ctx] = i
+		p.Update()
+	}
+	return *r, nil
+}
+
+// Write writes a 32-bit register, other sizes are not supported by the PLIC and ignored.
+func (p *Plic) Write(offset uint64, size uint64, value uint64) error {
+	if size != 4 || offset%4 != 0 {
+		return nil
+	}
+	r := p.register(offset)
+	if r == nil {
+		return nil
+	}
+	// The pending bits are read-only.
+	if offset >= PlicPending && offset < PlicPending+PlicSources/8 {
+		return nil
+	}
+	// Writing the claim register signals the completion.
+	if p.isClaim(offset) {
+		p.complete((offset-PlicThreshold)/0x1000, value&0xffffffff)
+		p.Update()
+		return nil
+	}
+	*r = value & 0xffffffff
+	p.priority[0] = 0
+	p.enable[0][0] &^= 1
+	p.enable[1][0] &^= 1
+	p.Update()
+	return nil
+}
+
+// NewPlic returns a PLIC driving the external interrupts of the CPU. It should be attached to the bus at PlicBase.
+func NewPlic(c *CPU) *Plic {
+	return &Plic{
+		cpu: c,
+	}
+}
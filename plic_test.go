@@ -0,0 +1,62 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func TestPlic(t *testing.T) {
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	p := NewPlic(c)
+	bus := NewBus()
+	bus.Attach(PlicBase, PlicSize, p)
+	c.SetFasten(bus)
+	mem := c.GetMemory()
+	mem.SetUint32(PlicBase+PlicPriority+4*3, 1)
+	mem.SetUint32(PlicBase+PlicPriority+4*5, 2)
+	mem.SetUint32(PlicBase+PlicEnable, 1<<3|1<<5)
+	p.Assert(3)
+	p.Assert(5)
+	if v, _ := mem.GetUint32(PlicBase + PlicPending); v != 1<<3|1<<5 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 1<<InterruptMEI {
+		t.FailNow()
+	}
+	// The source with the highest priority is claimed first.
+	if v, _ := mem.GetUint32(PlicBase + PlicClaim); v != 5 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint32(PlicBase + PlicClaim); v != 3 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint32(PlicBase + PlicClaim); v != 0 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 0 {
+		t.FailNow()
+	}
+	// Source 5 is still asserted, it is pending again once completed. Source 3 is not.
+	p.Deassert(3)
+	mem.SetUint32(PlicBase+PlicClaim, 3)
+	mem.SetUint32(PlicBase+PlicClaim, 5)
+	if v, _ := mem.GetUint32(PlicBase + PlicPending); v != 1<<5 {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 1<<InterruptMEI {
+		t.FailNow()
+	}
+	// A threshold masks interrupts with a lower or equal priority.
+	mem.SetUint32(PlicBase+PlicThreshold, 2)
+	if c.GetCSR().Get(CSRmip) != 0 {
+		t.FailNow()
+	}
+	// The supervisor context.
+	mem.SetUint32(PlicBase+PlicEnable+0x80, 1<<5)
+	if c.GetCSR().Get(CSRmip) != 1<<InterruptSEI {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint32(PlicBase + PlicClaim + 0x1000); v != 5 {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,559 @@
+package rv64
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// Snapshots.
+//
+// A snapshot holds the architectural state of the harts, the content of the memory and devices, and the state of the
+// System. It does not describe the configuration: a snapshot is restored into a CPU or Machine built the same way as
+// the one it was taken from, with the same memory regions and devices attached at the same addresses. The simulation
+// models, CostModel, cycle limit and Caches, are not part of it.
+//
+// All values are little-endian. The file starts with the magic "RV64SNAP", the version and the kind, 0 for a CPU, 1
+// for a Machine.
+//
+// Restore first decodes the whole snapshot, then commits it, so that a truncated or invalid snapshot leaves the CPU
+// or Machine as it was. Snapshotters of other packages are the exception, they restore their state as they read it.
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot. Restore rejects other versions.
+const SnapshotVersion uint32 = 1
+
+const (
+	snapshotMagic   = "RV64SNAP"
+	snapshotCPU     = 0
+	snapshotMachine = 1
+	snapshotHarts   = 1024 // Harts a Machine snapshot may hold
+)
+
+// Snapshotter is implemented by the memories, devices and Systems whose state can be saved in a snapshot. Restore
+// reads back what Snapshot wrote.
+type Snapshotter interface {
+	Snapshot(w io.Writer) error
+	Restore(r io.Reader) error
+}
+
+// snapshotDecoder is implemented by the Snapshotters of the package. decode reads their state into temporaries, the
+// function returned commits it.
+type snapshotDecoder interface {
+	decode(r io.Reader) (func(), error)
+}
+
+// snapshotDecode decodes the state of s. A Snapshotter without decode is restored at once.
+func snapshotDecode(s Snapshotter, r io.Reader) (func(), error) {
+	if d, ok := s.(snapshotDecoder); ok {
+		return d.decode(r)
+	}
+	return func() {}, s.Restore(r)
+}
+
+// snapshotRestore restores the state of d, which is left alone unless the whole of it was read.
+func snapshotRestore(d snapshotDecoder, r io.Reader) error {
+	commit, err := d.decode(r)
+	if err != nil {
+		return err
+	}
+	commit()
+	return nil
+}
+
+func snapshotWrite(w io.Writer, v ...interface{}) error {
+	for _, e := range v {
+		if err := binary.Write(w, binary.LittleEndian, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func snapshotRead(r io.Reader, v ...interface{}) error {
+	for _, e := range v {
+		if err := binary.Read(r, binary.LittleEndian, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotState is the part of the state of a CPU with a fixed size.
+type snapshotState struct {
+	Reg0   [32]uint64
+	Reg1   [32]uint64
+	Reg1h  [32]uint64
+	PC     uint64
+	LRAddr uint64
+	Status uint64
+	Paging uint64
+	ISA    uint64
+	XLEN   uint64
+	VLEN   uint64
+	CBSize uint64
+	HartID uint64
+}
+
+func (c *CPU) snapshotState(w io.Writer) error {
+	s := snapshotState{
+		Reg0: c.reg0, Reg1: c.reg1, Reg1h: c.reg1h,
+		PC: c.pc, LRAddr: c.lraddr, Status: c.status, Paging: c.paging, ISA: c.isa, XLEN: c.xlen, VLEN: c.vlen,
+		CBSize: c.cbsize, HartID: c.hartid,
+	}
+	var csr [0x1000]uint64
+	if e, ok := c.csr.(*CSRStandard); ok {
+		csr = e.m
+	} else {
+		for i := range csr {
+			csr[i] = c.csr.Get(uint64(i))
+		}
+	}
+	return snapshotWrite(w, &s, &csr, c.vreg)
+}
+
+// cpuState is the decoded state of a hart.
+type cpuState struct {
+	snapshotState
+	csr  [0x1000]uint64
+	vreg []byte
+}
+
+func decodeState(r io.Reader) (*cpuState, error) {
+	s := &cpuState{}
+	if err := snapshotRead(r, &s.snapshotState, &s.csr); err != nil {
+		return nil, err
+	}
+	if s.XLEN != 32 && s.XLEN != 64 {
+		return nil, ErrSnapshotFormat
+	}
+	if s.VLEN < 64 || s.VLEN > 65536 || s.VLEN&(s.VLEN-1) != 0 {
+		return nil, ErrSnapshotFormat
+	}
+	if s.CBSize < 16 || s.CBSize > 4096 || s.CBSize&(s.CBSize-1) != 0 {
+		return nil, ErrSnapshotFormat
+	}
+	s.vreg = make([]byte, 32*s.VLEN/8)
+	if err := snapshotRead(r, s.vreg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *CPU) commitState(s *cpuState) {
+	c.reg0, c.reg1, c.reg1h = s.Reg0, s.Reg1, s.Reg1h
+	c.pc, c.lraddr, c.status, c.paging, c.isa, c.xlen = s.PC, s.LRAddr, s.Status, s.Paging, s.ISA, s.XLEN
+	c.cbsize, c.hartid = s.CBSize, s.HartID
+	c.vlen, c.vreg = s.VLEN, s.vreg
+	if e, ok := c.csr.(*CSRStandard); ok {
+		e.m = s.csr
+	} else {
+		for i := range s.csr {
+			c.csr.Set(uint64(i), s.csr[i])
+		}
+	}
+}
+
+// snapshotter returns the Snapshotter saving the state of v, looking through the view of the memory of a hart.
+func snapshotter(v interface{}) (Snapshotter, error) {
+	if h, ok := v.(*hartFasten); ok {
+		v = h.Fasten
+	}
+	s, ok := v.(Snapshotter)
+	if !ok {
+		return nil, ErrSnapshotUnsupported
+	}
+	return s, nil
+}
+
+func snapshotHeader(w io.Writer, kind uint32) error {
+	if _, err := io.WriteString(w, snapshotMagic); err != nil {
+		return err
+	}
+	return snapshotWrite(w, SnapshotVersion, kind)
+}
+
+func restoreHeader(r io.Reader, kind uint32) error {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return err
+	}
+	var version, k uint32
+	if err := snapshotRead(r, &version, &k); err != nil {
+		return err
+	}
+	if string(magic) != snapshotMagic || version != SnapshotVersion || k != kind {
+		return ErrSnapshotFormat
+	}
+	return nil
+}
+
+// snapshotShared writes the state shared by the harts, the memory then the System.
+func snapshotShared(w io.Writer, f Fasten, s System) error {
+	for _, e := range []interface{}{f, s} {
+		t, err := snapshotter(e)
+		if err != nil {
+			return err
+		}
+		if err := t.Snapshot(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeShared(r io.Reader, f Fasten, s System) (func(), error) {
+	commits := []func(){}
+	for _, e := range []interface{}{f, s} {
+		t, err := snapshotter(e)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := snapshotDecode(t, r)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return func() {
+		for _, e := range commits {
+			e()
+		}
+	}, nil
+}
+
+// Snapshot writes the state of the CPU, its memory and its System to w. It fails with ErrSnapshotUnsupported if the
+// memory, one of the devices attached to it, or the System does not implement Snapshotter.
+func (c *CPU) Snapshot(w io.Writer) error {
+	if err := snapshotHeader(w, snapshotCPU); err != nil {
+		return err
+	}
+	if err := c.snapshotState(w); err != nil {
+		return err
+	}
+	return snapshotShared(w, c.fasten, c.system)
+}
+
+// Restore reads a snapshot written by Snapshot.
+func (c *CPU) Restore(r io.Reader) error {
+	if err := restoreHeader(r, snapshotCPU); err != nil {
+		return err
+	}
+	s, err := decodeState(r)
+	if err != nil {
+		return err
+	}
+	commit, err := decodeShared(r, c.fasten, c.system)
+	if err != nil {
+		return err
+	}
+	c.commitState(s)
+	commit()
+	return nil
+}
+
+// Snapshot writes the state of every hart, of the shared memory and of the System to w.
+func (m *Machine) Snapshot(w io.Writer) error {
+	if err := snapshotHeader(w, snapshotMachine); err != nil {
+		return err
+	}
+	if err := snapshotWrite(w, uint64(len(m.harts))); err != nil {
+		return err
+	}
+	for _, c := range m.harts {
+		if err := c.snapshotState(w); err != nil {
+			return err
+		}
+	}
+	return snapshotShared(w, m.fasten, m.system)
+}
+
+// Restore reads a snapshot written by Snapshot. The harts created by clone since the machine was built are cloned
+// again from hart 0 before their state is restored.
+func (m *Machine) Restore(r io.Reader) error {
+	if err := restoreHeader(r, snapshotMachine); err != nil {
+		return err
+	}
+	var n uint64
+	if err := snapshotRead(r, &n); err != nil {
+		return err
+	}
+	if n < uint64(len(m.harts)) || n > snapshotHarts {
+		return ErrSnapshotFormat
+	}
+	states := make([]*cpuState, n)
+	for i := range states {
+		s, err := decodeState(r)
+		if err != nil {
+			return err
+		}
+		states[i] = s
+	}
+	commit, err := decodeShared(r, m.fasten, m.system)
+	if err != nil {
+		return err
+	}
+	for uint64(len(m.harts)) < n {
+		m.Clone(m.harts[0])
+	}
+	for i, c := range m.harts {
+		c.commitState(states[i])
+	}
+	commit()
+	return nil
+}
+
+func (l *Linear) Snapshot(w io.Writer) error {
+	if err := snapshotWrite(w, l.Len()); err != nil {
+		return err
+	}
+	_, err := w.Write(l.data)
+	return err
+}
+
+func (l *Linear) Restore(r io.Reader) error {
+	return snapshotRestore(l, r)
+}
+
+func (l *Linear) decode(r io.Reader) (func(), error) {
+	var n uint64
+	if err := snapshotRead(r, &n); err != nil {
+		return nil, err
+	}
+	if n != l.Len() {
+		return nil, ErrSnapshotFormat
+	}
+	data := make([]byte, len(l.data))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return func() { copy(l.data, data) }, nil
+}
+
+func (r *Ram) Snapshot(w io.Writer) error {
+	s, err := snapshotter(r.fasten)
+	if err != nil {
+		return err
+	}
+	return s.Snapshot(w)
+}
+
+func (r *Ram) Restore(rd io.Reader) error {
+	return snapshotRestore(r, rd)
+}
+
+func (r *Ram) decode(rd io.Reader) (func(), error) {
+	s, err := snapshotter(r.fasten)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotDecode(s, rd)
+}
+
+// Snapshot writes the state of the attached devices in the order of their addresses.
+func (b *Bus) Snapshot(w io.Writer) error {
+	if err := snapshotWrite(w, uint64(len(b.regions))); err != nil {
+		return err
+	}
+	for _, e := range b.regions {
+		s, err := snapshotter(e.device)
+		if err != nil {
+			return err
+		}
+		if err := snapshotWrite(w, e.base, e.size); err != nil {
+			return err
+		}
+		if err := s.Snapshot(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bus) Restore(r io.Reader) error {
+	return snapshotRestore(b, r)
+}
+
+func (b *Bus) decode(r io.Reader) (func(), error) {
+	var n uint64
+	if err := snapshotRead(r, &n); err != nil {
+		return nil, err
+	}
+	if n != uint64(len(b.regions)) {
+		return nil, ErrSnapshotFormat
+	}
+	commits := []func(){}
+	for _, e := range b.regions {
+		var base, size uint64
+		if err := snapshotRead(r, &base, &size); err != nil {
+			return nil, err
+		}
+		if base != e.base || size != e.size {
+			return nil, ErrSnapshotFormat
+		}
+		s, err := snapshotter(e.device)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := snapshotDecode(s, r)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return func() {
+		for _, e := range commits {
+			e()
+		}
+	}, nil
+}
+
+func (l *Clint) Snapshot(w io.Writer) error {
+	return snapshotWrite(w, l.msip, l.mtimecmp)
+}
+
+func (l *Clint) Restore(r io.Reader) error {
+	return snapshotRestore(l, r)
+}
+
+func (l *Clint) decode(r io.Reader) (func(), error) {
+	n := *l
+	if err := snapshotRead(r, &n.msip, &n.mtimecmp); err != nil {
+		return nil, err
+	}
+	return func() { l.msip, l.mtimecmp = n.msip, n.mtimecmp }, nil
+}
+
+func (p *Plic) Snapshot(w io.Writer) error {
+	return snapshotWrite(w, &p.priority, &p.pending, &p.enable, &p.threshold, &p.claim, &p.level, &p.service)
+}
+
+func (p *Plic) Restore(r io.Reader) error {
+	return snapshotRestore(p, r)
+}
+
+func (p *Plic) decode(r io.Reader) (func(), error) {
+	n := *p
+	err := snapshotRead(r, &n.priority, &n.pending, &n.enable, &n.threshold, &n.claim, &n.level, &n.service)
+	if err != nil {
+		return nil, err
+	}
+	return func() { *p = n }, nil
+}
+
+// Snapshot writes the registers and the receive FIFO of the UART. The bytes the host has sent but the UART has not
+// received yet are not part of it.
+func (u *Uart) Snapshot(w io.Writer) error {
+	regs := [7]uint8{u.ier, u.lcr, u.mcr, u.scr, u.dll, u.dlm, u.fcr}
+	return snapshotWrite(w, &regs, u.thri, uint64(len(u.fifo)), u.fifo)
+}
+
+func (u *Uart) Restore(r io.Reader) error {
+	return snapshotRestore(u, r)
+}
+
+func (u *Uart) decode(r io.Reader) (func(), error) {
+	var regs [7]uint8
+	var thri bool
+	var n uint64
+	if err := snapshotRead(r, &regs, &thri, &n); err != nil {
+		return nil, err
+	}
+	if n > 16 {
+		return nil, ErrSnapshotFormat
+	}
+	fifo := make([]byte, n)
+	if err := snapshotRead(r, fifo); err != nil {
+		return nil, err
+	}
+	return func() {
+		u.ier, u.lcr, u.mcr, u.scr, u.dll, u.dlm, u.fcr = regs[0], regs[1], regs[2], regs[3], regs[4], regs[5], regs[6]
+		u.thri = thri
+		u.fifo = fifo
+	}, nil
+}
+
+func (h *Htif) Snapshot(w io.Writer) error {
+	return snapshotWrite(w, h.exit)
+}
+
+func (h *Htif) Restore(r io.Reader) error {
+	return snapshotRestore(h, r)
+}
+
+func (h *Htif) decode(r io.Reader) (func(), error) {
+	exit := h.exit
+	if err := snapshotRead(r, &exit); err != nil {
+		return nil, err
+	}
+	return func() { h.exit = exit }, nil
+}
+
+// Snapshot writes the exit code, the tid addresses to clear and the futex waits of the threads.
+func (s *SystemStandard) Snapshot(w io.Writer) error {
+	tids := []uint64{}
+	for k := range s.tidClear {
+		tids = append(tids, k)
+	}
+	sort.Slice(tids, func(i, j int) bool { return tids[i] < tids[j] })
+	if err := snapshotWrite(w, s.ExitCode, uint64(len(tids))); err != nil {
+		return err
+	}
+	for _, k := range tids {
+		if err := snapshotWrite(w, k, s.tidClear[k]); err != nil {
+			return err
+		}
+	}
+	waits := []uint64{}
+	for k := range s.futex {
+		waits = append(waits, k)
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	if err := snapshotWrite(w, uint64(len(waits))); err != nil {
+		return err
+	}
+	for _, k := range waits {
+		e := s.futex[k]
+		if err := snapshotWrite(w, k, e.addr, e.bits, e.deadline, e.woken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SystemStandard) Restore(r io.Reader) error {
+	return snapshotRestore(s, r)
+}
+
+// decode reads the state of the threads, there is at most one entry by hart.
+func (s *SystemStandard) decode(r io.Reader) (func(), error) {
+	n := NewSystemStandard()
+	var l uint64
+	if err := snapshotRead(r, &n.ExitCode, &l); err != nil {
+		return nil, err
+	}
+	if l > snapshotHarts {
+		return nil, ErrSnapshotFormat
+	}
+	for ; l > 0; l-- {
+		var k, a uint64
+		if err := snapshotRead(r, &k, &a); err != nil {
+			return nil, err
+		}
+		n.tidClear[k] = a
+	}
+	if err := snapshotRead(r, &l); err != nil {
+		return nil, err
+	}
+	if l > snapshotHarts {
+		return nil, ErrSnapshotFormat
+	}
+	for ; l > 0; l-- {
+		var k uint64
+		e := &futexWait{}
+		if err := snapshotRead(r, &k, &e.addr, &e.bits, &e.deadline, &e.woken); err != nil {
+			return nil, err
+		}
+		n.futex[k] = e
+	}
+	return func() { s.ExitCode, s.tidClear, s.futex = n.ExitCode, n.tidClear, n.futex }, nil
+}
@@ -0,0 +1,169 @@
+package rv64
+
+import (
+	"bytes"
+	"testing"
+)
+
+// newSnapshotCPU returns a CPU summing 1 to 100 in a0, storing the partial sums at 0x800, then exiting with the sum.
+func newSnapshotCPU() *CPU {
+	return newCostCPU([]uint64{
+		addi(Rt1, Rzero, 100),                       // 0x00 li t1, 100
+		encodeR(0b0110011, 0b000, 0, Ra0, Ra0, Rt1), // 0x04 add a0, a0, t1
+		encodeS(0b010, Ra1, Ra0, 0),                 // 0x08 sw a0, 0(a1)
+		addi(Rt1, Rt1, 0xfff),                       // 0x0c addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -12),             // 0x10 bnez t1, 0x04
+		addi(Ra7, Rzero, sysExitGroup),              // 0x14 li a7, exit_group
+		encodeI(0b1110011, 0b000, 0, Rzero, Rzero),  // 0x18 ecall
+	}, []uint64{4, 4, 4, 4, 4, 4, 4})
+}
+
+func TestSnapshotCPU(t *testing.T) {
+	c := newSnapshotCPU()
+	for i := 0; i < 150; i++ {
+		c.Step()
+	}
+	w := &bytes.Buffer{}
+	if err := c.Snapshot(w); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := c.Run(); r != uint8(5050%256) || err != nil {
+		t.Fatal(r, err)
+	}
+	// The snapshot is restored into a CPU built the same way. Its program is altered, the memory is restored too.
+	d := newSnapshotCPU()
+	d.GetMemory().SetUint32(0x04, 0)
+	if err := d.Restore(bytes.NewReader(w.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := d.Run(); r != uint8(5050%256) || err != nil {
+		t.Fatal(r, err)
+	}
+	if d.GetCSR().Get(CSRinstret) != c.GetCSR().Get(CSRinstret) || d.GetPC() != c.GetPC() {
+		t.FailNow()
+	}
+	if v, _ := d.GetMemory().GetUint32(0x800); v != 5050 {
+		t.Fatal(v)
+	}
+}
+
+func TestSnapshotMachine(t *testing.T) {
+	m := newCounterMachine(2, 20)
+	for i := 0; i < 30; i++ {
+		for _, c := range m.GetHarts() {
+			c.Step()
+		}
+	}
+	w := &bytes.Buffer{}
+	if err := m.Snapshot(w); err != nil {
+		t.Fatal(err)
+	}
+	n := newCounterMachine(2, 20)
+	if err := n.Restore(bytes.NewReader(w.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := m.Run()
+	b, _ := n.Run()
+	if a != 40 || b != 40 {
+		t.Fatal(a, b)
+	}
+	for i, c := range m.GetHarts() {
+		if c.GetCSR().Get(CSRinstret) != n.GetHart(uint64(i)).GetCSR().Get(CSRinstret) {
+			t.FailNow()
+		}
+	}
+}
+
+func TestSnapshotBus(t *testing.T) {
+	newBus := func(size uint64) (*CPU, *Bus) {
+		c := NewCPU()
+		c.SetCSR(NewCSRStandard())
+		c.SetSystem(NewSystemStandard())
+		b := NewBus()
+		b.AttachMemory(0, NewLinear(size))
+		b.Attach(ClintBase, ClintSize, NewClint(c))
+		c.SetFasten(b)
+		return c, b
+	}
+	c, _ := newBus(0x100)
+	c.GetMemory().SetUint64(0x10, 0x0123456789abcdef)
+	c.GetMemory().SetUint64(ClintBase+ClintMtimecmp, 42)
+	w := &bytes.Buffer{}
+	if err := c.Snapshot(w); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := newBus(0x100)
+	if err := d.Restore(bytes.NewReader(w.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := d.GetMemory().GetUint64(0x10); v != 0x0123456789abcdef {
+		t.Fatal(v)
+	}
+	if v, _ := d.GetMemory().GetUint64(ClintBase + ClintMtimecmp); v != 42 {
+		t.Fatal(v)
+	}
+	// The configuration must match.
+	d, _ = newBus(0x200)
+	if err := d.Restore(bytes.NewReader(w.Bytes())); err != ErrSnapshotFormat {
+		t.Fatal(err)
+	}
+	// And so must the version.
+	b := append([]byte{}, w.Bytes()...)
+	b[8] = 2
+	if err := d.Restore(bytes.NewReader(b)); err != ErrSnapshotFormat {
+		t.Fatal(err)
+	}
+	// Devices without a state to save make the snapshot fail.
+	_, bus := newBus(0x100)
+	bus.Attach(0x10000000, 0x100, NewRam(&Wrap32{Fasten: NewLinear(0x100)}))
+	e := NewCPU()
+	e.SetCSR(NewCSRStandard())
+	e.SetSystem(NewSystemStandard())
+	e.SetFasten(bus)
+	if err := e.Snapshot(&bytes.Buffer{}); err != ErrSnapshotUnsupported {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotInvalid(t *testing.T) {
+	c := newSnapshotCPU()
+	for i := 0; i < 50; i++ {
+		c.Step()
+	}
+	w := &bytes.Buffer{}
+	if err := c.Snapshot(w); err != nil {
+		t.Fatal(err)
+	}
+	// A truncated snapshot changes nothing, the registers are read before the memory.
+	d := newSnapshotCPU()
+	d.GetMemory().SetUint32(0x800, 7)
+	if err := d.Restore(bytes.NewReader(w.Bytes()[:w.Len()-1])); err == nil {
+		t.FailNow()
+	}
+	if v, _ := d.GetMemory().GetUint32(0x800); v != 7 || d.GetPC() != 0 || d.GetRegister(Ra0) != 0 {
+		t.FailNow()
+	}
+	// Lengths are checked before anything is allocated.
+	h := &bytes.Buffer{}
+	snapshotHeader(h, snapshotMachine)
+	snapshotWrite(h, uint64(1)<<40)
+	m := newCounterMachine(2, 20)
+	if err := m.Restore(bytes.NewReader(h.Bytes())); err != ErrSnapshotFormat || len(m.GetHarts()) != 2 {
+		t.Fatal(err)
+	}
+	u := NewUart(nil, nil, nil, 0)
+	b := &bytes.Buffer{}
+	snapshotWrite(b, [7]uint8{}, false, uint64(1)<<40)
+	if err := u.Restore(b); err != ErrSnapshotFormat {
+		t.Fatal(err)
+	}
+	// Neither does a truncated snapshot clone harts.
+	n := newCounterMachine(3, 20)
+	w.Reset()
+	if err := n.Snapshot(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Restore(bytes.NewReader(w.Bytes()[:w.Len()-1])); err == nil || len(m.GetHarts()) != 2 {
+		t.Fatal(err)
+	}
+}
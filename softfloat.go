@@ -0,0 +1,491 @@
+package rv64
+
+// A software implementation of IEEE 754 binary floating-point arithmetic. Go's native float32 and float64 operations
+// always round to nearest, ties to even, and do not report exceptions, so they can't implement the rm field of the
+// floating-point instructions nor the fflags CSR.
+//
+// Every operation first computes its exact result as m × 2^e with an arbitrary precision integer m, then rounds it once
+// to the destination format under the requested rounding mode. Division and square root are computed to two bits past
+// the destination precision, plus a sticky bit which is set when the remainder is not zero, which is enough for every
+// rounding mode to give the same result as the exact quotient. Tininess is detected after rounding, as RISC-V requires.
+// A NaN result is always the canonical NaN of the destination format.
+
+import (
+	"math/big"
+)
+
+// FloatFormat describes an IEEE 754 binary interchange format by the widths of its exponent and fraction fields.
+type FloatFormat struct {
+	Exp  uint
+	Frac uint
+}
+
+var (
+	FloatFormatH = FloatFormat{Exp: 5, Frac: 10}
+	FloatFormatS = FloatFormat{Exp: 8, Frac: 23}
+	FloatFormatD = FloatFormat{Exp: 11, Frac: 52}
+	FloatFormatQ = FloatFormat{Exp: 15, Frac: 112}
+)
+
+// Float128 holds the raw bits of a binary128 value, for which Go has no native type.
+type Float128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+func (f Float128) big() *big.Int {
+	u := new(big.Int).SetUint64(f.Hi)
+	return u.Lsh(u, 64).Or(u, new(big.Int).SetUint64(f.Lo))
+}
+
+func float128(u *big.Int) Float128 {
+	lo := new(big.Int).And(u, new(big.Int).SetUint64(0xffffffffffffffff))
+	return Float128{Hi: new(big.Int).Rsh(u, 64).Uint64(), Lo: lo.Uint64()}
+}
+
+func (f FloatFormat) bias() int {
+	return 1<<(f.Exp-1) - 1
+}
+
+// softfloat is an unpacked floating-point number. A finite number is m × 2^e, zero when m is zero.
+type softfloat struct {
+	neg  bool
+	inf  bool
+	nan  bool
+	snan bool
+	m    *big.Int
+	e    int
+}
+
+func (x *softfloat) zero() bool {
+	return !x.inf && !x.nan && x.m.Sign() == 0
+}
+
+// unpack decodes the raw bits of a value in format f.
+func (f FloatFormat) unpack(u uint64) *softfloat {
+	return f.unpackBig(new(big.Int).SetUint64(u))
+}
+
+func (f FloatFormat) unpackBig(u *big.Int) *softfloat {
+	frac := new(big.Int).And(u, f.mask(f.Frac))
+	exp := new(big.Int).Rsh(u, f.Frac).Uint64() & (1<<f.Exp - 1)
+	x := &softfloat{neg: u.Bit(int(f.Exp+f.Frac)) != 0, m: new(big.Int)}
+	switch exp {
+	case 1<<f.Exp - 1:
+		if frac.Sign() == 0 {
+			x.inf = true
+		} else {
+			x.nan = true
+			x.snan = frac.Bit(int(f.Frac-1)) == 0
+		}
+	case 0:
+		x.m = frac
+		x.e = 1 - f.bias() - int(f.Frac)
+	default:
+		x.m = frac.SetBit(frac, int(f.Frac), 1)
+		x.e = int(exp) - f.bias() - int(f.Frac)
+	}
+	return x
+}
+
+// mask returns 2^n - 1.
+func (f FloatFormat) mask(n uint) *big.Int {
+	m := new(big.Int).Lsh(big.NewInt(1), n)
+	return m.Sub(m, big.NewInt(1))
+}
+
+// pack encodes a sign, a biased exponent and a fraction.
+func (f FloatFormat) pack(neg bool, exp uint64, frac *big.Int) *big.Int {
+	u := new(big.Int).SetUint64(exp)
+	u.Lsh(u, f.Frac).Or(u, frac)
+	if neg {
+		u.SetBit(u, int(f.Exp+f.Frac), 1)
+	}
+	return u
+}
+
+func (f FloatFormat) nan() *big.Int {
+	return f.pack(false, 1<<f.Exp-1, new(big.Int).Lsh(big.NewInt(1), f.Frac-1))
+}
+
+// roundShift drops the low n bits of m under the rounding mode rm, where neg is the sign of the number m belongs to.
+// It reports whether any nonzero bit was dropped.
+func roundShift(m *big.Int, n int, rm uint64, neg bool) (*big.Int, bool) {
+	if n <= 0 {
+		return new(big.Int).Lsh(m, uint(-n)), false
+	}
+	q := new(big.Int).Rsh(m, uint(n))
+	r := new(big.Int).Sub(m, new(big.Int).Lsh(q, uint(n)))
+	if r.Sign() == 0 {
+		return q, false
+	}
+	h := new(big.Int).Lsh(big.NewInt(1), uint(n-1))
+	inc := false
+	switch rm {
+	case FRoundRNE:
+		c := r.Cmp(h)
+		inc = c > 0 || c == 0 && q.Bit(0) == 1
+	case FRoundRMM:
+		inc = r.Cmp(h) >= 0
+	case FRoundRDN:
+		inc = neg
+	case FRoundRUP:
+		inc = !neg
+	}
+	if inc {
+		q.Add(q, big.NewInt(1))
+	}
+	return q, true
+}
+
+// round rounds x to format f and returns the raw bits of the result with the accrued exception flags.
+func (f FloatFormat) round(x *softfloat, rm uint64) (uint64, uint64) {
+	u, flags := f.roundBig(x, rm)
+	return u.Uint64(), flags
+}
+
+func (f FloatFormat) roundBig(x *softfloat, rm uint64) (*big.Int, uint64) {
+	switch {
+	case x.nan:
+		return f.nan(), 0
+	case x.inf:
+		return f.pack(x.neg, 1<<f.Exp-1, new(big.Int)), 0
+	case x.m.Sign() == 0:
+		return f.pack(x.neg, 0, new(big.Int)), 0
+	}
+	p := int(f.Frac) + 1
+	emin := 1 - f.bias()
+	emax := f.bias()
+	lead := x.e + x.m.BitLen() - 1
+	// The exponent of the unit in the last place of the result, which is fixed for subnormal numbers.
+	q := lead - (p - 1)
+	if q < emin-int(f.Frac) {
+		q = emin - int(f.Frac)
+	}
+	m, inexact := roundShift(x.m, q-x.e, rm, x.neg)
+	if m.BitLen() > p {
+		m.Rsh(m, 1)
+		q++
+	}
+	var flags uint64
+	if inexact {
+		flags |= FFlagsNX
+		if lead < emin {
+			// Tininess after rounding: the result rounded to p bits with an unbounded exponent is below the
+			// smallest normal number.
+			u, _ := roundShift(x.m, x.m.BitLen()-p, rm, x.neg)
+			if lead+u.BitLen()-p < emin {
+				flags |= FFlagsUF
+			}
+		}
+	}
+	if m.Sign() == 0 {
+		return f.pack(x.neg, 0, m), flags
+	}
+	if q+m.BitLen()-1 > emax {
+		flags |= FFlagsOF | FFlagsNX
+		if rm == FRoundRTZ || rm == FRoundRDN && !x.neg || rm == FRoundRUP && x.neg {
+			return f.pack(x.neg, 1<<f.Exp-2, f.mask(f.Frac)), flags
+		}
+		return f.pack(x.neg, 1<<f.Exp-1, new(big.Int)), flags
+	}
+	if m.BitLen() < p {
+		return f.pack(x.neg, 0, m), flags
+	}
+	exp := uint64(q + int(f.Frac) + f.bias())
+	return f.pack(x.neg, exp, m.SetBit(m, int(f.Frac), 0)), flags
+}
+
+// nanFlags returns NV if any of the operands is a signaling NaN.
+func nanFlags(x ...*softfloat) uint64 {
+	for _, e := range x {
+		if e.snan {
+			return FFlagsNV
+		}
+	}
+	return 0
+}
+
+func anyNaN(x ...*softfloat) bool {
+	for _, e := range x {
+		if e.nan {
+			return true
+		}
+	}
+	return false
+}
+
+func invalid() *softfloat {
+	return &softfloat{nan: true, m: new(big.Int)}
+}
+
+// add returns the exact sum a + b. The sign of an exact zero sum is positive, except under RDN where it is negative,
+// unless both operands are zeros of the same sign.
+func softAdd(a *softfloat, b *softfloat, rm uint64) (*softfloat, uint64) {
+	if anyNaN(a, b) {
+		return invalid(), nanFlags(a, b)
+	}
+	if a.inf && b.inf && a.neg != b.neg {
+		return invalid(), FFlagsNV
+	}
+	if a.inf {
+		return a, 0
+	}
+	if b.inf {
+		return b, 0
+	}
+	e := a.e
+	if b.e < e {
+		e = b.e
+	}
+	ma := new(big.Int).Lsh(a.m, uint(a.e-e))
+	mb := new(big.Int).Lsh(b.m, uint(b.e-e))
+	if a.neg {
+		ma.Neg(ma)
+	}
+	if b.neg {
+		mb.Neg(mb)
+	}
+	ma.Add(ma, mb)
+	r := &softfloat{neg: ma.Sign() < 0, m: ma.Abs(ma), e: e}
+	if r.m.Sign() == 0 {
+		r.neg = rm == FRoundRDN
+		if a.zero() && b.zero() && a.neg == b.neg {
+			r.neg = a.neg
+		}
+	}
+	return r, 0
+}
+
+func softMul(a *softfloat, b *softfloat) (*softfloat, uint64) {
+	if anyNaN(a, b) {
+		return invalid(), nanFlags(a, b)
+	}
+	neg := a.neg != b.neg
+	if a.inf && b.zero() || a.zero() && b.inf {
+		return invalid(), FFlagsNV
+	}
+	if a.inf || b.inf {
+		return &softfloat{neg: neg, inf: true, m: new(big.Int)}, 0
+	}
+	return &softfloat{neg: neg, m: new(big.Int).Mul(a.m, b.m), e: a.e + b.e}, 0
+}
+
+func softDiv(f FloatFormat, a *softfloat, b *softfloat) (*softfloat, uint64) {
+	if anyNaN(a, b) {
+		return invalid(), nanFlags(a, b)
+	}
+	neg := a.neg != b.neg
+	switch {
+	case a.inf && b.inf, a.zero() && b.zero():
+		return invalid(), FFlagsNV
+	case a.inf:
+		return &softfloat{neg: neg, inf: true, m: new(big.Int)}, 0
+	case b.zero():
+		return &softfloat{neg: neg, inf: true, m: new(big.Int)}, FFlagsDZ
+	case b.inf, a.zero():
+		return &softfloat{neg: neg, m: new(big.Int)}, 0
+	}
+	k := int(f.Frac) + 3 + b.m.BitLen() - a.m.BitLen()
+	if k < 0 {
+		k = 0
+	}
+	q, r := new(big.Int).QuoRem(new(big.Int).Lsh(a.m, uint(k)), b.m, new(big.Int))
+	q.Lsh(q, 1)
+	if r.Sign() != 0 {
+		q.SetBit(q, 0, 1)
+	}
+	return &softfloat{neg: neg, m: q, e: a.e - b.e - k - 1}, 0
+}
+
+func softSqrt(f FloatFormat, a *softfloat) (*softfloat, uint64) {
+	switch {
+	case a.nan:
+		return invalid(), nanFlags(a)
+	case a.zero():
+		return a, 0
+	case a.neg:
+		return invalid(), FFlagsNV
+	case a.inf:
+		return a, 0
+	}
+	m := new(big.Int).Set(a.m)
+	e := a.e
+	if e&1 != 0 {
+		m.Lsh(m, 1)
+		e--
+	}
+	k := int(f.Frac) + 4 - m.BitLen()/2
+	if k < 0 {
+		k = 0
+	}
+	m.Lsh(m, uint(2*k))
+	s := new(big.Int).Sqrt(m)
+	s.Lsh(s, 1)
+	if new(big.Int).Mul(s, s).Cmp(new(big.Int).Lsh(m, 2)) != 0 {
+		s.SetBit(s, 0, 1)
+	}
+	return &softfloat{m: s, e: (e-2*k)/2 - 1}, 0
+}
+
+// SoftAdd returns a + b in format f rounded with rm, and the accrued exception flags.
+func SoftAdd(f FloatFormat, a uint64, b uint64, rm uint64) (uint64, uint64) {
+	r, flags := softAdd(f.unpack(a), f.unpack(b), rm)
+	u, more := f.round(r, rm)
+	return u, flags | more
+}
+
+// SoftSub returns a - b in format f rounded with rm, and the accrued exception flags.
+func SoftSub(f FloatFormat, a uint64, b uint64, rm uint64) (uint64, uint64) {
+	return SoftAdd(f, a, b^1<<(f.Exp+f.Frac), rm)
+}
+
+// SoftMul returns a × b in format f rounded with rm, and the accrued exception flags.
+func SoftMul(f FloatFormat, a uint64, b uint64, rm uint64) (uint64, uint64) {
+	r, flags := softMul(f.unpack(a), f.unpack(b))
+	u, more := f.round(r, rm)
+	return u, flags | more
+}
+
+// SoftDiv returns a / b in format f rounded with rm, and the accrued exception flags.
+func SoftDiv(f FloatFormat, a uint64, b uint64, rm uint64) (uint64, uint64) {
+	r, flags := softDiv(f, f.unpack(a), f.unpack(b))
+	u, more := f.round(r, rm)
+	return u, flags | more
+}
+
+// SoftSqrt returns the square root of a in format f rounded with rm, and the accrued exception flags.
+func SoftSqrt(f FloatFormat, a uint64, rm uint64) (uint64, uint64) {
+	r, flags := softSqrt(f, f.unpack(a))
+	u, more := f.round(r, rm)
+	return u, flags | more
+}
+
+// SoftMulAdd returns a × b + c in format f with a single rounding, and the accrued exception flags. The multiplication
+// of infinity and zero is invalid even when c is a quiet NaN.
+func SoftMulAdd(f FloatFormat, a uint64, b uint64, c uint64, rm uint64) (uint64, uint64) {
+	r, flags := softMulAdd(f.unpack(a), f.unpack(b), f.unpack(c), rm)
+	u, more := f.round(r, rm)
+	return u, flags | more
+}
+
+// SoftConvert converts a from format f to format t rounded with rm, and the accrued exception flags.
+func SoftConvert(f FloatFormat, t FloatFormat, a uint64, rm uint64) (uint64, uint64) {
+	x := f.unpack(a)
+	u, flags := t.round(x, rm)
+	return u, flags | nanFlags(x)
+}
+
+// SoftFromInt converts the n-bit signed or unsigned integer a to format f rounded with rm, and the accrued exception
+// flags.
+func SoftFromInt(f FloatFormat, a uint64, signed bool, n uint, rm uint64) (uint64, uint64) {
+	return f.round(softFromInt(a, signed, n), rm)
+}
+
+// SoftToInt converts a in format f to an n-bit signed or unsigned integer rounded with rm, and the accrued exception
+// flags. NaNs and out of range values raise NV and give the largest or smallest integer, a NaN counts as positive.
+// The result is sign extended to 64 bits.
+func SoftToInt(f FloatFormat, a uint64, signed bool, n uint, rm uint64) (uint64, uint64) {
+	return softToInt(f.unpack(a), signed, n, rm)
+}
+
+func softMulAdd(x *softfloat, y *softfloat, z *softfloat, rm uint64) (*softfloat, uint64) {
+	p, flags := softMul(x, y)
+	if z.nan {
+		return invalid(), flags | nanFlags(x, y, z)
+	}
+	r, more := softAdd(p, z, rm)
+	return r, flags | more
+}
+
+func softFromInt(a uint64, signed bool, n uint) *softfloat {
+	x := &softfloat{m: new(big.Int)}
+	if n < 64 {
+		a &= 1<<n - 1
+	}
+	if signed && a>>(n-1)&1 != 0 {
+		x.neg = true
+		a = -a
+		if n < 64 {
+			a &= 1<<n - 1
+		}
+	}
+	x.m.SetUint64(a)
+	return x
+}
+
+func softToInt(x *softfloat, signed bool, n uint, rm uint64) (uint64, uint64) {
+	var lo, hi *big.Int
+	if signed {
+		hi = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), n-1), big.NewInt(1))
+		lo = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), n-1))
+	} else {
+		hi = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), n), big.NewInt(1))
+		lo = new(big.Int)
+	}
+	sext := func(v *big.Int) uint64 {
+		u := new(big.Int).And(v, new(big.Int).SetUint64(1<<64-1)).Uint64()
+		if v.Sign() < 0 {
+			u = uint64(v.Int64())
+		}
+		return SignExtend(u, uint64(n-1))
+	}
+	if x.nan || x.inf && !x.neg {
+		return sext(hi), FFlagsNV
+	}
+	if x.inf {
+		return sext(lo), FFlagsNV
+	}
+	m, inexact := roundShift(x.m, -x.e, rm, x.neg)
+	if x.neg {
+		m.Neg(m)
+	}
+	if m.Cmp(hi) > 0 {
+		return sext(hi), FFlagsNV
+	}
+	if m.Cmp(lo) < 0 {
+		return sext(lo), FFlagsNV
+	}
+	if inexact {
+		return sext(m), FFlagsNX
+	}
+	return sext(m), 0
+}
+
+// softCompare returns -1, 0 or +1 as a is less than, equal to or greater than b, neither of which may be a NaN.
+func softCompare(a *softfloat, b *softfloat) int {
+	sign := func(x *softfloat) int {
+		switch {
+		case x.zero():
+			return 0
+		case x.neg:
+			return -1
+		}
+		return 1
+	}
+	sa, sb := sign(a), sign(b)
+	if sa != sb || sa == 0 {
+		if sa < sb {
+			return -1
+		} else if sa > sb {
+			return 1
+		}
+		return 0
+	}
+	var c int
+	switch {
+	case a.inf && b.inf:
+		c = 0
+	case a.inf:
+		c = 1
+	case b.inf:
+		c = -1
+	default:
+		e := a.e
+		if b.e < e {
+			e = b.e
+		}
+		c = new(big.Int).Lsh(a.m, uint(a.e-e)).Cmp(new(big.Int).Lsh(b.m, uint(b.e-e)))
+	}
+	return c * sa
+}
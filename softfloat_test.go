@@ -0,0 +1,220 @@
+package rv64
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// Go's native arithmetic rounds to nearest, ties to even, so it serves as a reference for RNE.
+func TestSoftFloatNative(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	f32 := func() uint64 { return uint64(r.Uint32()) }
+	f64 := func() uint64 { return r.Uint64() }
+	for n := 0; n < 20000; n++ {
+		a, b := f32(), f32()
+		x, y := math.Float32frombits(uint32(a)), math.Float32frombits(uint32(b))
+		for _, e := range []struct {
+			name string
+			f    func(FloatFormat, uint64, uint64, uint64) (uint64, uint64)
+			r    float32
+		}{
+			{"add", SoftAdd, x + y},
+			{"sub", SoftSub, x - y},
+			{"mul", SoftMul, x * y},
+			{"div", SoftDiv, x / y},
+		} {
+			u, _ := e.f(FloatFormatS, a, b, FRoundRNE)
+			if math.IsNaN(float64(e.r)) {
+				if uint32(u) != NaN32 {
+					t.Fatalf("%s.s %#08x %#08x: %#08x", e.name, a, b, u)
+				}
+			} else if uint32(u) != math.Float32bits(e.r) {
+				t.Fatalf("%s.s %#08x %#08x: %#08x != %#08x", e.name, a, b, u, math.Float32bits(e.r))
+			}
+		}
+		a, b, c := f64(), f64(), f64()
+		x64, y64, z64 := math.Float64frombits(a), math.Float64frombits(b), math.Float64frombits(c)
+		for _, e := range []struct {
+			name string
+			u    uint64
+			r    float64
+		}{
+			{"add", first(SoftAdd(FloatFormatD, a, b, FRoundRNE)), x64 + y64},
+			{"mul", first(SoftMul(FloatFormatD, a, b, FRoundRNE)), x64 * y64},
+			{"div", first(SoftDiv(FloatFormatD, a, b, FRoundRNE)), x64 / y64},
+			{"sqrt", first(SoftSqrt(FloatFormatD, a&^(1<<63), FRoundRNE)), math.Sqrt(math.Abs(x64))},
+			{"fma", first(SoftMulAdd(FloatFormatD, a, b, c, FRoundRNE)), math.FMA(x64, y64, z64)},
+		} {
+			if math.IsNaN(e.r) {
+				if e.u != NaN64 {
+					t.Fatalf("%s.d %#016x %#016x: %#016x", e.name, a, b, e.u)
+				}
+			} else if e.u != math.Float64bits(e.r) {
+				t.Fatalf("%s.d %#016x %#016x %#016x: %#016x != %#016x", e.name, a, b, c, e.u, math.Float64bits(e.r))
+			}
+		}
+	}
+}
+
+func first(u uint64, _ uint64) uint64 {
+	return u
+}
+
+// math/big rounds to any precision under every rounding mode and reports whether the result is exact. Its exponent is
+// unbounded, so operands are kept in a range where the results are normal numbers.
+func TestSoftFloatRoundingMode(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	mode := map[uint64]big.RoundingMode{
+		FRoundRNE: big.ToNearestEven,
+		FRoundRTZ: big.ToZero,
+		FRoundRDN: big.ToNegativeInf,
+		FRoundRUP: big.ToPositiveInf,
+		FRoundRMM: big.ToNearestAway,
+	}
+	rnd := func() float64 {
+		return math.Ldexp(r.Float64()+0.5, r.Intn(200)-100) * float64(1-2*r.Intn(2))
+	}
+	for n := 0; n < 20000; n++ {
+		x, y := rnd(), rnd()
+		a, b := math.Float64bits(x), math.Float64bits(y)
+		for rm, m := range mode {
+			for _, e := range []struct {
+				name string
+				f    func(FloatFormat, uint64, uint64, uint64) (uint64, uint64)
+				g    func(z *big.Float, x *big.Float, y *big.Float) *big.Float
+			}{
+				{"add", SoftAdd, (*big.Float).Add},
+				{"sub", SoftSub, (*big.Float).Sub},
+				{"mul", SoftMul, (*big.Float).Mul},
+				{"div", SoftDiv, (*big.Float).Quo},
+			} {
+				u, flags := e.f(FloatFormatD, a, b, rm)
+				z := new(big.Float).SetPrec(53).SetMode(m)
+				e.g(z, big.NewFloat(x), big.NewFloat(y))
+				v, _ := z.Float64()
+				if u != math.Float64bits(v) {
+					t.Fatalf("%s %v %v rm=%d: %v != %v", e.name, x, y, rm, math.Float64frombits(u), v)
+				}
+				if (flags == FFlagsNX) != (z.Acc() != big.Exact) || flags&^FFlagsNX != 0 {
+					t.Fatalf("%s %v %v rm=%d: flags %#x", e.name, x, y, rm, flags)
+				}
+			}
+			// Sqrt is not correctly rounded under directed rounding modes, round a much more precise result instead.
+			u, _ := SoftSqrt(FloatFormatD, a&^(1<<63), rm)
+			z := new(big.Float).SetPrec(400).Sqrt(big.NewFloat(math.Abs(x)))
+			z = new(big.Float).SetPrec(53).SetMode(m).Set(z)
+			if v, _ := z.Float64(); u != math.Float64bits(v) {
+				t.Fatalf("sqrt %v rm=%d: %v != %v", x, rm, math.Float64frombits(u), v)
+			}
+		}
+	}
+}
+
+func TestSoftFloatEdge(t *testing.T) {
+	s := func(f float32) uint64 { return uint64(math.Float32bits(f)) }
+	for _, e := range []struct {
+		name  string
+		f     func() (uint64, uint64)
+		r     uint64
+		flags uint64
+	}{
+		// Overflow gives infinity or the largest finite number, depending on the rounding mode.
+		{"max+max rne", func() (uint64, uint64) {
+			return SoftAdd(FloatFormatS, s(math.MaxFloat32), s(math.MaxFloat32), FRoundRNE)
+		}, 0x7f800000, FFlagsOF | FFlagsNX},
+		{"max+max rtz", func() (uint64, uint64) {
+			return SoftAdd(FloatFormatS, s(math.MaxFloat32), s(math.MaxFloat32), FRoundRTZ)
+		}, 0x7f7fffff, FFlagsOF | FFlagsNX},
+		{"-max-max rup", func() (uint64, uint64) {
+			return SoftAdd(FloatFormatS, s(-math.MaxFloat32), s(-math.MaxFloat32), FRoundRUP)
+		}, 0xff7fffff, FFlagsOF | FFlagsNX},
+		// x - x is -0 only under RDN.
+		{"1-1 rdn", func() (uint64, uint64) { return SoftSub(FloatFormatS, s(1), s(1), FRoundRDN) }, 0x80000000, 0},
+		{"1-1 rup", func() (uint64, uint64) { return SoftSub(FloatFormatS, s(1), s(1), FRoundRUP) }, 0x00000000, 0},
+		// The smallest subnormal halved.
+		{"min/2 rne", func() (uint64, uint64) { return SoftMul(FloatFormatS, 0x00000001, s(0.5), FRoundRNE) }, 0x00000000, FFlagsUF | FFlagsNX},
+		{"min/2 rup", func() (uint64, uint64) { return SoftMul(FloatFormatS, 0x00000001, s(0.5), FRoundRUP) }, 0x00000001, FFlagsUF | FFlagsNX},
+		{"min/2 rmm", func() (uint64, uint64) { return SoftMul(FloatFormatS, 0x00000001, s(0.5), FRoundRMM) }, 0x00000001, FFlagsUF | FFlagsNX},
+		// Exact subnormal results don't underflow.
+		{"min*1", func() (uint64, uint64) { return SoftMul(FloatFormatS, 0x00000001, s(1), FRoundRNE) }, 0x00000001, 0},
+		// Rounded up to the smallest normal number, which is not tiny after rounding.
+		{"tiny rup", func() (uint64, uint64) { return SoftMul(FloatFormatS, 0x007fffff, s(1.0000001), FRoundRUP) }, 0x00800000, FFlagsNX},
+		{"0/0", func() (uint64, uint64) { return SoftDiv(FloatFormatS, 0, 0, FRoundRNE) }, uint64(NaN32), FFlagsNV},
+		{"1/0", func() (uint64, uint64) { return SoftDiv(FloatFormatS, s(1), 0, FRoundRNE) }, 0x7f800000, FFlagsDZ},
+		{"sqrt(-1)", func() (uint64, uint64) { return SoftSqrt(FloatFormatS, s(-1), FRoundRNE) }, uint64(NaN32), FFlagsNV},
+		{"sqrt(-0)", func() (uint64, uint64) { return SoftSqrt(FloatFormatS, s(float32(math.Copysign(0, -1))), FRoundRNE) }, 0x80000000, 0},
+		{"snan+1", func() (uint64, uint64) { return SoftAdd(FloatFormatS, 0x7f800001, s(1), FRoundRNE) }, uint64(NaN32), FFlagsNV},
+		{"inf*0+qnan", func() (uint64, uint64) { return SoftMulAdd(FloatFormatS, 0x7f800000, 0, uint64(NaN32), FRoundRNE) }, uint64(NaN32), FFlagsNV},
+		// Conversions to integer.
+		{"2.5 rne", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(2.5), true, 32, FRoundRNE) }, 2, FFlagsNX},
+		{"2.5 rmm", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(2.5), true, 32, FRoundRMM) }, 3, FFlagsNX},
+		{"-2.5 rdn", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(-2.5), true, 32, FRoundRDN) }, 0xfffffffffffffffd, FFlagsNX},
+		{"-0.5 wu", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(-0.5), false, 32, FRoundRTZ) }, 0, FFlagsNX},
+		{"-0.5 wu rdn", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(-0.5), false, 32, FRoundRDN) }, 0, FFlagsNV},
+		{"2^31 w", func() (uint64, uint64) { return SoftToInt(FloatFormatS, s(1<<31), true, 32, FRoundRNE) }, 0x7fffffff, FFlagsNV},
+		{"nan wu", func() (uint64, uint64) { return SoftToInt(FloatFormatS, uint64(NaN32), false, 32, FRoundRNE) }, 0xffffffffffffffff, FFlagsNV},
+		{"-inf l", func() (uint64, uint64) { return SoftToInt(FloatFormatD, 0xfff0000000000000, true, 64, FRoundRNE) }, 0x8000000000000000, FFlagsNV},
+		// Conversions from integer.
+		{"2^24+1 rne", func() (uint64, uint64) { return SoftFromInt(FloatFormatS, 1<<24+1, true, 32, FRoundRNE) }, s(1 << 24), FFlagsNX},
+		{"2^24+1 rup", func() (uint64, uint64) { return SoftFromInt(FloatFormatS, 1<<24+1, true, 32, FRoundRUP) }, s(1<<24 + 2), FFlagsNX},
+		{"-1 w", func() (uint64, uint64) { return SoftFromInt(FloatFormatS, 0xffffffff, true, 32, FRoundRNE) }, s(-1), 0},
+		{"max lu", func() (uint64, uint64) { return SoftFromInt(FloatFormatD, 0xffffffffffffffff, false, 64, FRoundRTZ) }, 0x43efffffffffffff, FFlagsNX},
+		// Narrowing.
+		{"d->s rtz", func() (uint64, uint64) {
+			return SoftConvert(FloatFormatD, FloatFormatS, math.Float64bits(1.0/3), FRoundRTZ)
+		}, 0x3eaaaaaa, FFlagsNX},
+		{"d->s rup", func() (uint64, uint64) {
+			return SoftConvert(FloatFormatD, FloatFormatS, math.Float64bits(1.0/3), FRoundRUP)
+		}, 0x3eaaaaab, FFlagsNX},
+		{"snan d->s", func() (uint64, uint64) { return SoftConvert(FloatFormatD, FloatFormatS, 0x7ff0000000000001, FRoundRNE) }, uint64(NaN32), FFlagsNV},
+	} {
+		u, flags := e.f()
+		if u != e.r || flags != e.flags {
+			t.Fatalf("%s: %#x %#x != %#x %#x", e.name, u, flags, e.r, e.flags)
+		}
+	}
+}
+
+func TestRoundingMode(t *testing.T) {
+	c := newVectorCPU()
+	// fadd.s a0, a1, a2, rm
+	fadds := func(rm uint64) uint64 { return encodeR(0b1010011, rm, 0b0000000, Ra0, Ra1, Ra2) }
+	c.SetRegisterFloatAsFloat32(Ra1, 1)
+	c.SetRegisterFloatAsFloat32(Ra2, float32(math.Ldexp(1, -30)))
+	for _, e := range []struct {
+		rm  uint64
+		frm uint64
+		r   float32
+	}{
+		{FRoundRNE, FRoundRUP, 1},
+		{FRoundRUP, FRoundRNE, math.Nextafter32(1, 2)},
+		{FRoundDYN, FRoundRTZ, 1},
+		{FRoundDYN, FRoundRUP, math.Nextafter32(1, 2)},
+	} {
+		c.GetCSR().Set(CSRfcsr, e.frm<<5)
+		if err := execute(c, fadds(e.rm)); err != nil {
+			t.Fatal(err)
+		}
+		if c.GetRegisterFloatAsFloat32(Ra0) != e.r || c.GetCSR().Get(CSRfflags) != FFlagsNX {
+			t.Fatal(e)
+		}
+	}
+	// Reserved rounding modes, in the instruction or in frm.
+	for _, e := range [][2]uint64{{0b101, 0}, {0b110, 0}, {FRoundDYN, 0b101}, {FRoundDYN, 0b111}} {
+		c.GetCSR().Set(CSRfrm, e[1])
+		if err := execute(c, fadds(e[0])); err != ErrAbnormalInstruction {
+			t.Fatal(e, err)
+		}
+	}
+	// fflags are accrued.
+	c.GetCSR().Set(CSRfcsr, FFlagsDZ)
+	c.SetRegisterFloatAsFloat32(Ra2, 0.5)
+	if err := execute(c, fadds(FRoundRNE)); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetCSR().Get(CSRfflags) != FFlagsDZ {
+		t.FailNow()
+	}
+}
@@ -5,22 +5,267 @@ type System interface {
 	Code() uint8
 }
 
+// Linux system call numbers of the generic syscall table used by RISC-V.
+const (
+	sysExit           = 93
+	sysExitGroup      = 94
+	sysSetTidAddress  = 96
+	sysFutex          = 98
+	sysSchedYield     = 124
+	sysGetpid         = 172
+	sysGettid         = 178
+	sysClone          = 220
+	sysFutexTime64    = 422
+	sysCloneVM        = 0x00000100
+	sysCloneThread    = 0x00010000
+	sysCloneSettls    = 0x00080000
+	sysCloneParentTid = 0x00100000
+	sysCloneChildTid  = 0x01000000
+	sysCloneClearTid  = 0x00200000
+	sysFutexWait      = 0
+	sysFutexWake      = 1
+	sysFutexWaitBits  = 9
+	sysFutexWakeBits  = 10
+)
+
+// Negated errno values returned in a0.
+const (
+	sysErrAgain    uint64 = 0xfffffffffffffff5 // -EAGAIN
+	sysErrInval    uint64 = 0xffffffffffffffea // -EINVAL
+	sysErrNosys    uint64 = 0xffffffffffffffda // -ENOSYS
+	sysErrTimedout uint64 = 0xffffffffffffff92 // -ETIMEDOUT
+)
+
+// SystemStandard implements the Linux system calls a statically linked user-mode program needs. Threads created by
+// clone run on harts of the Machine hosting the CPU, the thread ID of a hart is its mhartid plus one, so the main
+// thread has the ID of the process.
+//
+// A thread waiting on a futex stays on its ecall and its hart is parked with StatusWait, the ecall is executed again
+// once the thread is woken or the timeout expires. Timeouts are measured with the time CSR of the waiting hart, which
+// is taken to count nanoseconds. A standalone CPU has no other thread to wake it, its wait times out at once, or fails
+// with ErrDeadlock without a timeout.
 type SystemStandard struct {
 	ExitCode uint8
+	tidClear map[uint64]uint64     // By mhartid
+	futex    map[uint64]*futexWait // By mhartid
+}
+
+type futexWait struct {
+	addr     uint64
+	bits     uint32
+	deadline uint64
+	woken    bool
 }
 
 func (s *SystemStandard) HandleCall(c *CPU) (uint64, error) {
+	// The zero value is usable, the maps are made on the first call.
+	if s.tidClear == nil {
+		s.tidClear = map[uint64]uint64{}
+	}
+	if s.futex == nil {
+		s.futex = map[uint64]*futexWait{}
+	}
 	code := c.GetRegister(Ra7)
+	// The embedded ABIs have no a7, the syscall number is passed in t0.
+	if c.GetISA()&ISAE != 0 {
+		code = c.GetRegister(Rt0)
+	}
 	switch code {
-	case 0x005d:
+	case sysExit:
+		if c.GetMachine() != nil && s.live(c) > 1 {
+			if a := s.tidClear[c.GetHartID()]; a != 0 {
+				c.GetMemory().SetUint32(a, 0)
+				s.wake(c, a, 1, 0xffffffff)
+			}
+			delete(s.tidClear, c.GetHartID())
+			c.SetStatus(StatusHalt)
+			c.SetPC(c.GetPC() + 4)
+			return 1, nil
+		}
 		s.ExitCode = uint8(c.GetRegister(Ra0))
-		c.SetStatus(1)
+		c.SetStatus(StatusExit)
 		c.SetPC(c.GetPC() + 4)
 		return 1, nil
+	case sysExitGroup:
+		s.ExitCode = uint8(c.GetRegister(Ra0))
+		c.SetStatus(StatusExit)
+		c.SetPC(c.GetPC() + 4)
+		return 1, nil
+	case sysSetTidAddress:
+		s.tidClear[c.GetHartID()] = c.GetRegister(Ra0)
+		return s.ret(c, c.GetHartID()+1)
+	case sysGettid:
+		return s.ret(c, c.GetHartID()+1)
+	case sysGetpid:
+		return s.ret(c, 1)
+	case sysSchedYield:
+		return s.ret(c, 0)
+	case sysClone:
+		return s.clone(c)
+	case sysFutex, sysFutexTime64:
+		return s.futexCall(c)
 	}
 	return 0, ErrAbnormalEcall
 }
 
+// ret completes a system call with the result r.
+func (s *SystemStandard) ret(c *CPU, r uint64) (uint64, error) {
+	c.SetRegister(Ra0, r)
+	c.SetPC(c.GetPC() + 4)
+	return 1, nil
+}
+
+// live returns the number of threads which have not exited.
+func (s *SystemStandard) live(c *CPU) int {
+	n := 0
+	for _, e := range c.GetMachine().GetHarts() {
+		if e.GetStatus() != StatusHalt {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *SystemStandard) clone(c *CPU) (uint64, error) {
+	flags := c.GetRegister(Ra0)
+	if c.GetMachine() == nil || flags&(sysCloneVM|sysCloneThread) != sysCloneVM|sysCloneThread {
+		return s.ret(c, sysErrNosys)
+	}
+	var (
+		sp   = c.GetRegister(Ra1)
+		ptid = c.GetRegister(Ra2)
+		tls  = c.GetRegister(Ra3)
+		ctid = c.GetRegister(Ra4)
+	)
+	n := c.GetMachine().Clone(c)
+	tid := n.GetHartID() + 1
+	if sp != 0 {
+		n.SetRegister(Rsp, sp)
+	}
+	if flags&sysCloneSettls != 0 {
+		n.SetRegister(Rtp, tls)
+	}
+	if flags&sysCloneParentTid != 0 {
+		c.GetMemory().SetUint32(ptid, uint32(tid))
+	}
+	if flags&sysCloneChildTid != 0 {
+		c.GetMemory().SetUint32(ctid, uint32(tid))
+	}
+	if flags&sysCloneClearTid != 0 {
+		s.tidClear[n.GetHartID()] = ctid
+	}
+	n.SetRegister(Ra0, 0)
+	n.SetPC(n.GetPC() + 4)
+	return s.ret(c, tid)
+}
+
+func (s *SystemStandard) futexCall(c *CPU) (uint64, error) {
+	var (
+		addr = c.GetRegister(Ra0)
+		op   = c.GetRegister(Ra1) & 0x7f
+		val  = c.GetRegister(Ra2)
+		tp   = c.GetRegister(Ra3)
+		bits = uint32(c.GetRegister(Ra5))
+	)
+	switch op {
+	case sysFutexWait, sysFutexWaitBits:
+		if op == sysFutexWait {
+			bits = 0xffffffff
+		}
+		if bits == 0 {
+			return s.ret(c, sysErrInval)
+		}
+		now := c.GetCSR().Get(CSRtime)
+		if w := s.futex[c.GetHartID()]; w != nil {
+			// The thread is already waiting, it was scheduled again.
+			switch {
+			case w.woken:
+				delete(s.futex, c.GetHartID())
+				return s.ret(c, 0)
+			case w.deadline != 0 && now >= w.deadline:
+				delete(s.futex, c.GetHartID())
+				return s.ret(c, sysErrTimedout)
+			}
+			return 1, nil
+		}
+		v, err := c.GetMemory().GetUint32(addr)
+		if err != nil {
+			return 0, err
+		}
+		if v != uint32(val) {
+			return s.ret(c, sysErrAgain)
+		}
+		w := &futexWait{addr: addr, bits: bits}
+		if tp != 0 {
+			sec, err := c.GetMemory().GetUint64(tp)
+			if err != nil {
+				return 0, err
+			}
+			nsec, err := c.GetMemory().GetUint64(tp + 8)
+			if err != nil {
+				return 0, err
+			}
+			// FUTEX_WAIT takes a relative timeout, FUTEX_WAIT_BITSET an absolute one.
+			w.deadline = sec*1000000000 + nsec
+			if op == sysFutexWait {
+				w.deadline += now
+			}
+			if w.deadline == 0 {
+				w.deadline = 1
+			}
+		}
+		if c.GetMachine() == nil {
+			if w.deadline == 0 {
+				return 0, ErrDeadlock
+			}
+			if now < w.deadline {
+				c.GetCSR().Set(CSRtime, w.deadline)
+			}
+			return s.ret(c, sysErrTimedout)
+		}
+		s.futex[c.GetHartID()] = w
+		c.SetStatus(StatusWait)
+		return 1, nil
+	case sysFutexWake, sysFutexWakeBits:
+		if op == sysFutexWake {
+			bits = 0xffffffff
+		}
+		if bits == 0 {
+			return s.ret(c, sysErrInval)
+		}
+		return s.ret(c, s.wake(c, addr, val, bits))
+	}
+	return s.ret(c, sysErrNosys)
+}
+
+// wake wakes up to n threads waiting on the futex at addr, in the order of their mhartid, and returns how many were
+// woken.
+func (s *SystemStandard) wake(c *CPU, addr uint64, n uint64, bits uint32) uint64 {
+	if c.GetMachine() == nil {
+		return 0
+	}
+	var r uint64
+	for _, e := range c.GetMachine().GetHarts() {
+		if r >= n {
+			break
+		}
+		if w := s.futex[e.GetHartID()]; w != nil && !w.woken && w.addr == addr && w.bits&bits != 0 {
+			w.woken = true
+			e.SetStatus(StatusRunning)
+			r++
+		}
+	}
+	return r
+}
+
+// deadline returns the deadline of the futex wait of the hart c, 0 when it has none or was woken.
+func (s *SystemStandard) deadline(c *CPU) uint64 {
+	if w := s.futex[c.GetHartID()]; w != nil && !w.woken {
+		return w.deadline
+	}
+	return 0
+}
+
 func (s *SystemStandard) Code() uint8 {
 	return s.ExitCode
 }
@@ -28,5 +273,7 @@ func (s *SystemStandard) Code() uint8 {
 func NewSystemStandard() *SystemStandard {
 	return &SystemStandard{
 		ExitCode: 0,
+		tidClear: map[uint64]uint64{},
+		futex:    map[uint64]*futexWait{},
 	}
 }
@@ -0,0 +1,169 @@
+package rv64
+
+import (
+	"testing"
+)
+
+func encodeS(funct3 uint64, rs1 uint64, rs2 uint64, imm uint64) uint64 {
+	return InstructionPart(imm, 5, 11)<<25 | rs2<<20 | rs1<<15 | funct3<<12 | InstructionPart(imm, 0, 4)<<7 | 0b0100011
+}
+
+func encodeJ(rd uint64, imm int64) uint64 {
+	u := uint64(imm)
+	return InstructionPart(u, 20, 20)<<31 | InstructionPart(u, 1, 10)<<21 | InstructionPart(u, 11, 11)<<20 |
+		InstructionPart(u, 12, 19)<<12 | rd<<7 | 0b1101111
+}
+
+func addi(rd uint64, rs1 uint64, imm uint64) uint64 { return encodeI(0b0010011, 0b000, imm, rd, rs1) }
+
+// newThreadMachine returns a single hart machine running prog from address 0.
+func newThreadMachine(prog []uint64) *Machine {
+	m := NewMachine(1)
+	m.SetFasten(NewLinear(0x1000))
+	m.SetSystem(NewSystemStandard())
+	for i, e := range prog {
+		m.GetHart(0).GetMemory().SetUint32(uint64(4*i), uint32(e))
+	}
+	return m
+}
+
+func TestSystemThread(t *testing.T) {
+	for _, p := range []bool{false, true} {
+		testSystemThread(t, p)
+	}
+}
+
+func testSystemThread(t *testing.T, parallel bool) {
+	const flags = sysCloneVM | sysCloneThread | sysCloneSettls | sysCloneChildTid | sysCloneClearTid
+	ecall := encodeI(0b1110011, 0b000, 0, Rzero, Rzero)
+	m := newThreadMachine([]uint64{
+		addi(Ra0, Rs2, 0),                      // 0x00 mv a0, s2
+		addi(Ra1, Rzero, 0x700),                // 0x04 li a1, 0x700
+		addi(Ra3, Rzero, 0x123),                // 0x08 li a3, 0x123
+		addi(Ra4, Rs1, 8),                      // 0x0c addi a4, s1, 8
+		addi(Ra7, Rzero, sysClone),             // 0x10 li a7, clone
+		ecall,                                  // 0x14 ecall
+		encodeB(0b000, Ra0, Rzero, 0x3c),       // 0x18 beqz a0, 0x54
+		addi(Rs3, Ra0, 0),                      // 0x1c mv s3, a0
+		encodeI(0b0000011, 0b010, 8, Ra2, Rs1), // 0x20 lw a2, 8(s1)
+		encodeB(0b000, Ra2, Rzero, 0x1c),       // 0x24 beqz a2, 0x40
+		addi(Ra0, Rs1, 8),                      // 0x28 addi a0, s1, 8
+		addi(Ra1, Rzero, 128),                  // 0x2c li a1, FUTEX_WAIT_PRIVATE
+		addi(Ra3, Rzero, 0),                    // 0x30 li a3, 0
+		addi(Ra7, Rzero, sysFutex),             // 0x34 li a7, futex
+		ecall,                                  // 0x38 ecall
+		encodeJ(Rzero, -0x1c),                  // 0x3c j 0x20
+		encodeI(0b0000011, 0b010, 0, Ra0, Rs1), // 0x40 lw a0, 0(s1)
+		addi(Ra7, Rzero, sysExitGroup),         // 0x44 li a7, exit_group
+		ecall,                                  // 0x48 ecall
+		0,                                      // 0x4c
+		0,                                      // 0x50
+		addi(Rt1, Rzero, 100),                  // 0x54 li t1, 100
+		addi(Rt1, Rt1, 0xfff),                  // 0x58 addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -4),         // 0x5c bnez t1, 0x58
+		addi(Rt0, Rzero, 42),                   // 0x60 li t0, 42
+		encodeS(0b010, Rs1, Rt0, 0),            // 0x64 sw t0, 0(s1)
+		addi(Ra7, Rzero, sysGettid),            // 0x68 li a7, gettid
+		ecall,                                  // 0x6c ecall
+		encodeS(0b010, Rs1, Ra0, 4),            // 0x70 sw a0, 4(s1)
+		addi(Ra7, Rzero, sysExit),              // 0x74 li a7, exit
+		ecall,                                  // 0x78 ecall
+	})
+	m.SetParallel(parallel)
+	c := m.GetHart(0)
+	c.SetRegister(Rs1, 0x800)
+	c.SetRegister(Rs2, flags)
+	if r, err := m.Run(); r != 42 || err != nil {
+		t.Fatal(r, err)
+	}
+	if len(m.GetHarts()) != 2 {
+		t.FailNow()
+	}
+	d := m.GetHart(1)
+	if d.GetStatus() != StatusHalt || d.GetRegister(Rtp) != 0x123 || d.GetRegister(Rsp) != 0x700 {
+		t.FailNow()
+	}
+	if c.GetRegister(Rs3) != 2 {
+		t.FailNow()
+	}
+	// The child wrote its tid, and cleared the tid address on exit.
+	mem := c.GetMemory()
+	if v, _ := mem.GetUint32(0x804); v != 2 {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint32(0x808); v != 0 {
+		t.FailNow()
+	}
+}
+
+func TestSystemFutex(t *testing.T) {
+	ecall := encodeI(0b1110011, 0b000, 0, Rzero, Rzero)
+	prog := []uint64{
+		addi(Ra0, Rzero, 0x400),        // li a0, 0x400
+		addi(Ra1, Rzero, 128),          // li a1, FUTEX_WAIT_PRIVATE
+		addi(Ra7, Rzero, sysFutex),     // li a7, futex
+		ecall,                          // ecall
+		addi(Ra7, Rzero, sysExitGroup), // li a7, exit_group
+		ecall,                          // ecall
+	}
+	// Waiting with a value which does not match fails at once.
+	m := newThreadMachine(prog)
+	m.GetHart(0).SetRegister(Ra2, 1)
+	if r, err := m.Run(); r != 0xf5 || err != nil {
+		t.Fatal(r, err)
+	}
+	// The timeout is relative to the time CSR.
+	m = newThreadMachine(prog)
+	c := m.GetHart(0)
+	c.GetMemory().SetUint64(0x900, 0)
+	c.GetMemory().SetUint64(0x908, 1000)
+	c.SetRegister(Ra3, 0x900)
+	if r, err := m.Run(); r != 0x92 || err != nil {
+		t.Fatal(r, err)
+	}
+	if n := c.GetCSR().Get(CSRtime); n < 1000 || n > 1010 {
+		t.Fatal(n)
+	}
+	// A long timeout does not execute the time it waits, the time jumps to the deadline.
+	m = newThreadMachine(prog)
+	c = m.GetHart(0)
+	c.GetMemory().SetUint64(0x900, 1)
+	c.SetRegister(Ra3, 0x900)
+	if r, err := m.Run(); r != 0x92 || err != nil {
+		t.Fatal(r, err)
+	}
+	if n := c.GetCSR().Get(CSRinstret); n > 10 {
+		t.Fatal(n)
+	}
+	// Waiting forever with no other thread running is a deadlock.
+	for _, p := range []bool{false, true} {
+		m = newThreadMachine(prog)
+		m.SetParallel(p)
+		if _, err := m.Run(); err != ErrDeadlock {
+			t.Fatal(err)
+		}
+		if m.GetHart(0).GetStatus() != StatusWait {
+			t.FailNow()
+		}
+	}
+	// Exiting the last thread ends the program.
+	m = newThreadMachine([]uint64{addi(Ra0, Rzero, 7), addi(Ra7, Rzero, sysExit), ecall})
+	if r, err := m.Run(); r != 7 || err != nil {
+		t.Fatal(r, err)
+	}
+}
+
+func TestSystemZero(t *testing.T) {
+	ecall := encodeI(0b1110011, 0b000, 0, Rzero, Rzero)
+	c := newCostCPU([]uint64{
+		addi(Ra0, Rzero, 0x800),            // 0x00 li a0, 0x800
+		addi(Ra7, Rzero, sysSetTidAddress), // 0x04 li a7, set_tid_address
+		ecall,                              // 0x08 ecall
+		addi(Ra7, Rzero, sysExitGroup),     // 0x0c li a7, exit_group
+		ecall,                              // 0x10 ecall
+	}, []uint64{4, 4, 4, 4, 4})
+	c.SetSystem(&SystemStandard{})
+	if r, err := c.Run(); r != 1 || err != nil {
+		t.Fatal(r, err)
+	}
+}
@@ -0,0 +1,320 @@
+package rv64
+
+// Timing is a CostModel estimating the cycles an in-order 5-stage pipeline, as pictured in cpu_pipeline.go, would
+// take to execute the program. One instruction issues per cycle, unless:
+//
+//   - One of its source registers is not ready. Results are forwarded, an instruction can issue as soon as the result
+//     it depends on is produced, that is latency cycles after its producer issued. Loads have a latency of 2 by
+//     default, which gives the classic load-use stall of one cycle. The functional units are fully pipelined.
+//   - The previous instruction was a mispredicted branch or an indirect jump. The branch is resolved in EX and the
+//     instructions fetched behind it are squashed, which costs MispredictPenalty cycles. Direct jumps are predicted
+//     correctly, jalr is never predicted.
+//
+// A Timing holds the state of the pipeline of a single hart, it is a CostCloner. Harts cloned by a Machine and forked
+// CPUs run on their own copy.
+type Timing struct {
+	Predictor         uint64 // One of the Predictor constants
+	TableBits         uint64 // Log2 of the number of counters of the dynamic predictors, and length of the gshare history
+	MispredictPenalty uint64
+	LoadLatency       uint64 // Integer and floating-point loads, and atomic memory operations
+	MulLatency        uint64
+	DivLatency        uint64 // Divisions and remainders
+	FAddLatency       uint64 // Additions, and every floating-point operation not listed below
+	FMulLatency       uint64
+	FDivLatency       uint64 // Divisions and square roots
+	FMALatency        uint64 // Fused multiply-add
+
+	Branches    uint64 // Conditional branches executed
+	Mispredicts uint64 // Conditional branches mispredicted
+	Jumps       uint64 // Indirect jumps executed
+	Stalls      uint64 // Cycles lost waiting for source registers
+
+	cycle   uint64
+	ready   [64]uint64
+	table   []uint8
+	history uint64
+}
+
+// Clone returns a copy of the Timing, with the same settings, counters and pipeline state.
+func (t *Timing) Clone() CostModel {
+	n := *t
+	n.table = append([]uint8(nil), t.table...)
+	return &n
+}
+
+// timingOp describes the operands of an instruction. Floating-point registers are numbered from 32, 0 stands for x0
+// and for no register.
+type timingOp struct {
+	src    [3]uint64
+	dst    uint64
+	lat    uint64
+	branch bool
+	taken  bool
+	back   bool
+	jalr   bool
+}
+
+func (t *Timing) Cost(c *CPU, i uint64, l uint64) uint64 {
+	if t.table == nil {
+		t.table = make([]uint8, 1<<t.TableBits)
+		for j := range t.table {
+			t.table[j] = 1
+		}
+	}
+	var op timingOp
+	if l == 2 {
+		op = t.decodeCompressed(c, i)
+	} else {
+		op = t.decode(c, i)
+	}
+	issue := t.cycle + 1
+	for _, r := range op.src {
+		if r != 0 && t.ready[r] > issue {
+			issue = t.ready[r]
+		}
+	}
+	t.Stalls += issue - t.cycle - 1
+	if op.dst != 0 {
+		t.ready[op.dst] = issue + op.lat
+	}
+	next := issue
+	if op.branch {
+		t.Branches++
+		if t.predict(c.GetPC(), op) != op.taken {
+			t.Mispredicts++
+			next += t.MispredictPenalty
+		}
+	}
+	if op.jalr {
+		t.Jumps++
+		next += t.MispredictPenalty
+	}
+	r := next - t.cycle
+	t.cycle = next
+	return r
+}
+
+// predict returns the predicted direction of the branch at pc, and trains the predictor with its outcome.
+func (t *Timing) predict(pc uint64, op timingOp) bool {
+	mask := uint64(len(t.table) - 1)
+	var j uint64
+	switch t.Predictor {
+	case PredictorStatic:
+		return op.back
+	case PredictorBimodal:
+		j = pc >> 1 & mask
+	case PredictorGshare:
+		j = (pc>>1 ^ t.history) & mask
+		t.history = t.history << 1 & mask
+		if op.taken {
+			t.history |= 1
+		}
+	}
+	r := t.table[j] >= 2
+	if op.taken && t.table[j] < 3 {
+		t.table[j]++
+	}
+	if !op.taken && t.table[j] > 0 {
+		t.table[j]--
+	}
+	return r
+}
+
+// taken reports whether the branch of kind funct3 comparing a with b is taken.
+func (t *Timing) taken(funct3 uint64, a uint64, b uint64) bool {
+	switch funct3 {
+	case 0b000:
+		return a == b
+	case 0b001:
+		return a != b
+	case 0b100:
+		return int64(a) < int64(b)
+	case 0b101:
+		return int64(a) >= int64(b)
+	case 0b110:
+		return a < b
+	case 0b111:
+		return a >= b
+	}
+	return false
+}
+
+func (t *Timing) decode(c *CPU, i uint64) timingOp {
+	var (
+		rd     = InstructionPart(i, 7, 11)
+		rs1    = InstructionPart(i, 15, 19)
+		rs2    = InstructionPart(i, 20, 24)
+		rs3    = InstructionPart(i, 27, 31)
+		funct3 = InstructionPart(i, 12, 14)
+		op     = timingOp{lat: 1}
+	)
+	switch InstructionPart(i, 0, 6) {
+	case 0b0110111, 0b0010111, 0b1101111:
+		op.dst = rd
+	case 0b1100111:
+		op.src[0], op.dst, op.jalr = rs1, rd, true
+	case 0b1100011:
+		op.src[0], op.src[1], op.branch = rs1, rs2, true
+		op.taken = t.taken(funct3, c.GetRegister(rs1), c.GetRegister(rs2))
+		op.back = InstructionPart(i, 31, 31) == 1
+	case 0b0000011:
+		op.src[0], op.dst, op.lat = rs1, rd, t.LoadLatency
+	case 0b0100011:
+		op.src[0], op.src[1] = rs1, rs2
+	case 0b0010011, 0b0011011:
+		op.src[0], op.dst = rs1, rd
+	case 0b0110011, 0b0111011:
+		op.src[0], op.src[1], op.dst = rs1, rs2, rd
+		if InstructionPart(i, 25, 31) == 0b0000001 {
+			op.lat = t.MulLatency
+			if funct3 >= 0b100 {
+				op.lat = t.DivLatency
+			}
+		}
+	case 0b0101111:
+		op.src[0], op.src[1], op.dst, op.lat = rs1, rs2, rd, t.LoadLatency
+	case 0b0000111:
+		op.src[0] = rs1
+		if funct3 >= 0b001 && funct3 <= 0b100 {
+			op.dst, op.lat = 32+rd, t.LoadLatency
+		}
+	case 0b0100111:
+		op.src[0] = rs1
+		if funct3 >= 0b001 && funct3 <= 0b100 {
+			op.src[1] = 32 + rs2
+		}
+	case 0b1000011, 0b1000111, 0b1001011, 0b1001111:
+		op.src, op.dst, op.lat = [3]uint64{32 + rs1, 32 + rs2, 32 + rs3}, 32+rd, t.FMALatency
+	case 0b1010011:
+		op.src[0], op.src[1], op.dst, op.lat = 32+rs1, 32+rs2, 32+rd, t.FAddLatency
+		switch InstructionPart(i, 27, 31) {
+		case 0b00010:
+			op.lat = t.FMulLatency
+		case 0b00011:
+			op.lat = t.FDivLatency
+		case 0b01011:
+			op.src[1], op.lat = 0, t.FDivLatency
+		case 0b01000:
+			op.src[1] = 0
+		case 0b10100:
+			op.dst = rd
+		case 0b11000, 0b11100:
+			op.src[1], op.dst = 0, rd
+		case 0b11010, 0b11110:
+			op.src[0], op.src[1] = rs1, 0
+		}
+	case 0b1110011:
+		if funct3 != 0b000 {
+			op.dst = rd
+			if funct3 < 0b100 {
+				op.src[0] = rs1
+			}
+		}
+	}
+	return op
+}
+
+func (t *Timing) decodeCompressed(c *CPU, i uint64) timingOp {
+	var (
+		rd     = InstructionPart(i, 7, 11)
+		rs2    = InstructionPart(i, 2, 6)
+		rdp    = InstructionPart(i, 2, 4) + 8
+		rs1p   = InstructionPart(i, 7, 9) + 8
+		funct3 = InstructionPart(i, 13, 15)
+		rv32   = c.GetXLEN() == 32
+		op     = timingOp{lat: 1}
+	)
+	switch InstructionPart(i, 0, 1)<<3 | funct3 {
+	case 0b00_000:
+		op.src[0], op.dst = Rsp, rdp
+	case 0b00_001:
+		op.src[0], op.dst, op.lat = rs1p, 32+rdp, t.LoadLatency
+	case 0b00_010:
+		op.src[0], op.dst, op.lat = rs1p, rdp, t.LoadLatency
+	case 0b00_011:
+		op.src[0], op.dst, op.lat = rs1p, rdp, t.LoadLatency
+		if rv32 {
+			op.dst = 32 + rdp
+		}
+	case 0b00_101:
+		op.src[0], op.src[1] = rs1p, 32+rdp
+	case 0b00_110:
+		op.src[0], op.src[1] = rs1p, rdp
+	case 0b00_111:
+		op.src[0], op.src[1] = rs1p, rdp
+		if rv32 {
+			op.src[1] = 32 + rdp
+		}
+	case 0b01_000, 0b10_000:
+		op.src[0], op.dst = rd, rd
+	case 0b01_001:
+		op.src[0], op.dst = rd, rd
+		if rv32 {
+			op.src[0], op.dst = 0, Rra
+		}
+	case 0b01_010:
+		op.dst = rd
+	case 0b01_011:
+		op.dst = rd
+		if rd == Rsp {
+			op.src[0] = Rsp
+		}
+	case 0b01_100:
+		op.src[0], op.dst = rs1p, rs1p
+		if InstructionPart(i, 10, 11) == 0b11 {
+			op.src[1] = rdp
+		}
+	case 0b01_110, 0b01_111:
+		op.src[0], op.branch = rs1p, true
+		op.taken = t.taken(funct3&1, c.GetRegister(rs1p), 0)
+		op.back = InstructionPart(i, 12, 12) == 1
+	case 0b10_001:
+		op.src[0], op.dst, op.lat = Rsp, 32+rd, t.LoadLatency
+	case 0b10_010:
+		op.src[0], op.dst, op.lat = Rsp, rd, t.LoadLatency
+	case 0b10_011:
+		op.src[0], op.dst, op.lat = Rsp, rd, t.LoadLatency
+		if rv32 {
+			op.dst = 32 + rd
+		}
+	case 0b10_100:
+		switch {
+		case rs2 != 0 && InstructionPart(i, 12, 12) == 0:
+			op.src[0], op.dst = rs2, rd
+		case rs2 != 0:
+			op.src[0], op.src[1], op.dst = rd, rs2, rd
+		case rd != 0:
+			op.src[0], op.jalr = rd, true
+			if InstructionPart(i, 12, 12) == 1 {
+				op.dst = Rra
+			}
+		}
+	case 0b10_101:
+		op.src[0], op.src[1] = Rsp, 32+rs2
+	case 0b10_110:
+		op.src[0], op.src[1] = Rsp, rs2
+	case 0b10_111:
+		op.src[0], op.src[1] = Rsp, rs2
+		if rv32 {
+			op.src[1] = 32 + rs2
+		}
+	}
+	return op
+}
+
+// NewTiming returns a timing model with a bimodal predictor of 1024 counters and the latencies of a simple in-order
+// core.
+func NewTiming() *Timing {
+	return &Timing{
+		Predictor:         PredictorBimodal,
+		TableBits:         10,
+		MispredictPenalty: 2,
+		LoadLatency:       2,
+		MulLatency:        3,
+		DivLatency:        20,
+		FAddLatency:       4,
+		FMulLatency:       4,
+		FDivLatency:       20,
+		FMALatency:        5,
+	}
+}
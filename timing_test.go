@@ -0,0 +1,134 @@
+package rv64
+
+import (
+	"testing"
+)
+
+// runTiming runs prog, followed by an exit, with the timing model t and returns the CPU. Every cycle is accounted for
+// by an instruction, a stall or a misprediction.
+func runTiming(tb testing.TB, prog []uint64, t *Timing) *CPU {
+	ecall := encodeI(0b1110011, 0b000, 0, Rzero, Rzero)
+	prog = append(prog, addi(Ra7, Rzero, sysExitGroup), ecall)
+	size := make([]uint64, len(prog))
+	for i := range size {
+		size[i] = 4
+	}
+	c := newCostCPU(prog, size)
+	c.SetCostModel(t)
+	c.Run()
+	n := c.GetCSR().Get(CSRinstret) + t.Stalls + (t.Mispredicts+t.Jumps)*t.MispredictPenalty
+	if c.GetCSR().Get(CSRcycle) != n {
+		tb.Fatal(c.GetCSR().Get(CSRcycle), n)
+	}
+	return c
+}
+
+func TestTimingHazard(t *testing.T) {
+	var (
+		ld    = encodeI(0b0000011, 0b011, 0, Ra0, Ra1)        // ld a0, 0(a1)
+		mul   = encodeR(0b0110011, 0b000, 1, Ra0, Ra1, Ra1)   // mul a0, a1, a1
+		div   = encodeR(0b0110011, 0b100, 1, Ra0, Ra1, Ra1)   // div a0, a1, a1
+		fmul  = encodeR(0b1010011, 0b111, 0b0001001, 1, 2, 3) // fmul.d f1, f2, f3
+		fadd  = encodeR(0b1010011, 0b111, 0b0000001, 4, 1, 1) // fadd.d f4, f1, f1
+		use   = addi(Ra2, Ra0, 1)                             // addi a2, a0, 1
+		other = addi(Ra3, Ra3, 1)                             // addi a3, a3, 1
+	)
+	for _, e := range []struct {
+		prog   []uint64
+		stalls uint64
+	}{
+		{[]uint64{ld, use}, 1},
+		{[]uint64{ld, other}, 0},
+		{[]uint64{ld, other, use}, 0},
+		{[]uint64{mul, use}, 2},
+		{[]uint64{div, use}, 19},
+		{[]uint64{div, other, encodeR(0b0110011, 0b000, 1, Ra2, Ra1, Ra1)}, 0},
+		{[]uint64{fmul, fadd}, 3},
+	} {
+		c := runTiming(t, e.prog, NewTiming())
+		if s := c.GetCostModel().(*Timing).Stalls; s != e.stalls {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestTimingPredictor(t *testing.T) {
+	// A loop of 100 iterations.
+	loop := []uint64{
+		addi(Rt1, Rzero, 100),          // li t1, 100
+		addi(Rt1, Rt1, 0xfff),          // addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -4), // bnez t1, -4
+	}
+	for _, e := range []struct {
+		predictor   uint64
+		mispredicts uint64
+	}{
+		{PredictorStatic, 1},
+		{PredictorBimodal, 2},
+	} {
+		m := NewTiming()
+		m.Predictor = e.predictor
+		runTiming(t, loop, m)
+		if m.Branches != 100 || m.Mispredicts != e.mispredicts {
+			t.Fatal(m.Branches, m.Mispredicts)
+		}
+	}
+	// A branch alternating between taken and not taken defeats the bimodal predictor, not gshare.
+	alternate := []uint64{
+		addi(Rt1, Rzero, 200),                  // li t1, 200
+		encodeI(0b0010011, 0b111, 1, Rt2, Rt1), // andi t2, t1, 1
+		encodeB(0b000, Rt2, Rzero, 8),          // beqz t2, 8
+		addi(Rt3, Rt3, 1),                      // addi t3, t3, 1
+		addi(Rt1, Rt1, 0xfff),                  // addi t1, t1, -1
+		encodeB(0b001, Rt1, Rzero, -16),        // bnez t1, -16
+	}
+	bimodal := NewTiming()
+	runTiming(t, alternate, bimodal)
+	gshare := NewTiming()
+	gshare.Predictor = PredictorGshare
+	runTiming(t, alternate, gshare)
+	if bimodal.Mispredicts < 100 || gshare.Mispredicts > 20 {
+		t.Fatal(bimodal.Mispredicts, gshare.Mispredicts)
+	}
+}
+
+func TestTimingJalr(t *testing.T) {
+	m := NewTiming()
+	// jal ra, 8; j 8; ret
+	runTiming(t, []uint64{encodeJ(Rra, 8), encodeJ(Rzero, 8), encodeI(0b1100111, 0b000, 0, Rzero, Rra)}, m)
+	if n := m.cycle; n != 5+m.MispredictPenalty {
+		t.Fatal(n)
+	}
+}
+
+func TestTimingClone(t *testing.T) {
+	c := newForkCPU()
+	m := NewTiming()
+	c.SetCostModel(m)
+	for i := 0; i < 150; i++ {
+		c.Step()
+	}
+	// Each fork runs on its own copy of the model, taken at the fork, and restored by Reset.
+	f, err := c.Fork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.GetCostModel() == c.GetCostModel() {
+		t.FailNow()
+	}
+	cycles := []uint64{}
+	for i := 0; i < 2; i++ {
+		f.Run()
+		cycles = append(cycles, f.GetCSR().Get(CSRcycle))
+		f.Reset()
+	}
+	if cycles[0] != cycles[1] || m.Branches != f.GetCostModel().(*Timing).Branches {
+		t.Fatal(cycles)
+	}
+	// The threads of a Machine too.
+	n := NewMachine(1)
+	n.GetHart(0).SetCostModel(m)
+	if n.Clone(n.GetHart(0)).GetCostModel() == CostModel(m) {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,248 @@
+package rv64
+
+import (
+	"io"
+	"os"
+)
+
+// NS16550A compatible UART, as found on QEMU virt and used by OpenSBI and most bare-metal programs for the console.
+//
+// Offset  DLAB  Read  Write  Description
+// 0       0     RBR   THR    Receiver buffer / Transmitter holding register.
+// 1       0     IER   IER    Interrupt enable register.
+// 0       1     DLL   DLL    Divisor latch, least significant byte.
+// 1       1     DLM   DLM    Divisor latch, most significant byte.
+// 2       x     IIR   FCR    Interrupt identification register / FIFO control register.
+// 3       x     LCR   LCR    Line control register, bit 7 is DLAB.
+// 4       x     MCR   MCR    Modem control register.
+// 5       x     LSR   -      Line status register.
+// 6       x     MSR   -      Modem status register.
+// 7       x     SCR   SCR    Scratch register.
+//
+// Transmission is immediate, the transmitter holding register is always empty. Received bytes are queued in a 16-byte
+// FIFO.
+
+const (
+	UartBase = 0x10000000
+	UartSize = 0x100
+	UartIrq  = 10
+)
+
+const (
+	UartIerRdi  = 0x01 // Enable received data available interrupt
+	UartIerThri = 0x02 // Enable transmitter holding register empty interrupt
+	UartIirNone = 0x01 // No interrupt pending
+	UartIirThri = 0x02 // Transmitter holding register empty
+	UartIirRdi  = 0x04 // Received data available
+	UartLsrDr   = 0x01 // Data ready
+	UartLsrThre = 0x20 // Transmitter holding register empty
+	UartLsrTemt = 0x40 // Transmitter empty
+	UartLcrDlab = 0x80 // Divisor latch access bit
+)
+
+// Uart is a NS16550A UART device. Output is written to w, input is read from r once the guest starts using the UART.
+// Input from a terminal is read in the background, as the user types it. Any other input is read whenever the FIFO
+// has room, so that runs with the same input receive it at the same instructions.
+type Uart struct {
+	w    io.Writer
+	r    io.Reader
+	c    chan byte
+	sync bool // The input is not a terminal, it is read on the emulator goroutine
+	eof  bool
+	irq  Interrupter
+	line uint64
+	fifo []byte
+	ier  uint8
+	lcr  uint8
+	mcr  uint8
+	scr  uint8
+	dll  uint8
+	dlm  uint8
+	fcr  uint8
+	thri bool
+}
+
+// uartTerminal reports whether the reader is a terminal.
+func uartTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	s, err := f.Stat()
+	return err == nil && s.Mode()&os.ModeCharDevice != 0
+}
+
+func (u *Uart) start() {
+	if u.c != nil || u.sync || u.r == nil {
+		return
+	}
+	if !uartTerminal(u.r) {
+		u.sync = true
+		u.receive()
+		return
+	}
+	u.c = make(chan byte, 64)
+	go func() {
+		b := make([]byte, 1)
+		for {
+			n, err := u.r.Read(b)
+			if n == 1 {
+				u.c <- b[0]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// receive moves the bytes read from the host into the FIFO.
+func (u *Uart) receive() {
+	if u.sync {
+		if u.eof || len(u.fifo) >= 16 {
+			return
+		}
+		b := make([]byte, 16-len(u.fifo))
+		n, err := u.r.Read(b)
+		u.fifo = append(u.fifo, b[:n]...)
+		if err != nil {
+			u.eof = true
+		}
+		return
+	}
+	for len(u.fifo) < 16 {
+		select {
+		case b := <-u.c:
+			u.fifo = append(u.fifo, b)
+		default:
+			return
+		}
+	}
+}
+
+func (u *Uart) iir() uint8 {
+	switch {
+	case u.ier&UartIerRdi != 0 && len(u.fifo) != 0:
+		return UartIirRdi
+	case u.ier&UartIerThri != 0 && u.thri:
+		return UartIirThri
+	}
+	return UartIirNone
+}
+
+// Update drives the interrupt line of the UART.
+func (u *Uart) Update() {
+	if u.irq == nil {
+		return
+	}
+	if u.iir() != UartIirNone {
+		u.irq.Assert(u.line)
+	} else {
+		u.irq.Deassert(u.line)
+	}
+}
+
+func (u *Uart) Read(offset uint64, size uint64) (uint64, error) {
+	u.start()
+	var r uint8
+	switch offset {
+	case 0:
+		if u.lcr&UartLcrDlab != 0 {
+			r = u.dll
+		} else if len(u.fifo) != 0 {
+			r = u.fifo[0]
+			u.fifo = u.fifo[1:]
+			u.receive()
+		}
+	case 1:
+		if u.lcr&UartLcrDlab != 0 {
+			r = u.dlm
+		} else {
+			r = u.ier
+		}
+	case 2:
+		r = u.iir()
+		if u.fcr&0x01 != 0 {
+			r |= 0xc0
+		}
+		// Reading IIR clears a transmitter holding register empty interrupt it reports.
+		if r&0x0f == UartIirThri {
+			u.thri = false
+		}
+	case 3:
+		r = u.lcr
+	case 4:
+		r = u.mcr
+	case 5:
+		r = UartLsrThre | UartLsrTemt
+		if len(u.fifo) != 0 {
+			r |= UartLsrDr
+		}
+	case 6:
+		// Carrier detect, ring indicator, data set ready and clear to send.
+		r = 0xb0
+	case 7:
+		r = u.scr
+	}
+	u.Update()
+	return uint64(r), nil
+}
+
+func (u *Uart) Write(offset uint64, size uint64, value uint64) error {
+	u.start()
+	v := uint8(value)
+	switch offset {
+	case 0:
+		if u.lcr&UartLcrDlab != 0 {
+			u.dll = v
+			break
+		}
+		if _, err := u.w.Write([]byte{v}); err != nil {
+			return err
+		}
+		u.thri = true
+	case 1:
+		if u.lcr&UartLcrDlab != 0 {
+			u.dlm = v
+			break
+		}
+		// Enabling the interrupt while the transmitter holding register is empty raises it.
+		if u.ier&UartIerThri == 0 && v&UartIerThri != 0 {
+			u.thri = true
+		}
+		u.ier = v & 0x0f
+	case 2:
+		u.fcr = v
+		// Clear receive FIFO.
+		if v&0x02 != 0 {
+			u.fifo = u.fifo[:0]
+		}
+	case 3:
+		u.lcr = v
+	case 4:
+		u.mcr = v
+	case 7:
+		u.scr = v
+	}
+	u.Update()
+	return nil
+}
+
+func (u *Uart) Tick(n uint64) {
+	if u.c == nil && !u.sync {
+		return
+	}
+	u.receive()
+	u.Update()
+}
+
+// NewUart returns a UART writing its output to w and reading its input from r. The interrupt line is raised through
+// irq, which may be nil.
+func NewUart(r io.Reader, w io.Writer, irq Interrupter, line uint64) *Uart {
+	return &Uart{
+		w:    w,
+		r:    r,
+		irq:  irq,
+		line: line,
+	}
+}
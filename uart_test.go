@@ -0,0 +1,111 @@
+package rv64
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestUartTransmit(t *testing.T) {
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	plic := NewPlic(c)
+	out := &bytes.Buffer{}
+	bus := NewBus()
+	bus.Attach(PlicBase, PlicSize, plic)
+	bus.Attach(UartBase, UartSize, NewUart(nil, out, plic, UartIrq))
+	c.SetFasten(bus)
+	mem := c.GetMemory()
+	for _, b := range []byte("Hello") {
+		mem.SetUint8(UartBase, b)
+	}
+	if out.String() != "Hello" {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint8(UartBase + 5); v != UartLsrThre|UartLsrTemt {
+		t.FailNow()
+	}
+	// Divisor latch.
+	mem.SetUint8(UartBase+3, UartLcrDlab)
+	mem.SetUint8(UartBase+0, 0x03)
+	mem.SetUint8(UartBase+1, 0x00)
+	mem.SetUint8(UartBase+3, 0x03)
+	if v, _ := mem.GetUint8(UartBase + 1); v != 0 {
+		t.FailNow()
+	}
+	if out.String() != "Hello" {
+		t.FailNow()
+	}
+	// Transmitter holding register empty interrupt.
+	mem.SetUint32(PlicBase+PlicPriority+4*UartIrq, 1)
+	mem.SetUint32(PlicBase+PlicEnable, 1<<UartIrq)
+	mem.SetUint8(UartBase+1, UartIerThri)
+	if c.GetCSR().Get(CSRmip) != 1<<InterruptMEI {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint8(UartBase + 2); v != UartIirThri {
+		t.FailNow()
+	}
+	if v, _ := mem.GetUint8(UartBase + 2); v != UartIirNone {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 0 {
+		t.FailNow()
+	}
+}
+
+func TestUartReceive(t *testing.T) {
+	c := NewCPU()
+	c.SetCSR(NewCSRStandard())
+	plic := NewPlic(c)
+	u := NewUart(bytes.NewReader([]byte("ok")), &bytes.Buffer{}, plic, UartIrq)
+	bus := NewBus()
+	bus.Attach(PlicBase, PlicSize, plic)
+	bus.Attach(UartBase, UartSize, u)
+	c.SetFasten(bus)
+	mem := c.GetMemory()
+	mem.SetUint32(PlicBase+PlicPriority+4*UartIrq, 1)
+	mem.SetUint32(PlicBase+PlicEnable, 1<<UartIrq)
+	mem.SetUint8(UartBase+1, UartIerRdi)
+	r := []byte{}
+	for i := 0; i < 1000 && len(r) < 2; i++ {
+		u.Tick(1)
+		if v, _ := mem.GetUint8(UartBase + 5); v&UartLsrDr == 0 {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		if c.GetCSR().Get(CSRmip) != 1<<InterruptMEI {
+			t.FailNow()
+		}
+		b, _ := mem.GetUint8(UartBase)
+		r = append(r, b)
+	}
+	if string(r) != "ok" {
+		t.FailNow()
+	}
+	if c.GetCSR().Get(CSRmip) != 0 {
+		t.FailNow()
+	}
+}
+
+func TestUartReceiveScripted(t *testing.T) {
+	u := NewUart(bytes.NewReader([]byte("abcdefghijklmnopqrst")), &bytes.Buffer{}, nil, UartIrq)
+	bus := NewBus()
+	bus.Attach(UartBase, UartSize, u)
+	mem := &Memory{Fasten: bus}
+	// Input which is not a terminal is available right away, without the background reader.
+	r := []byte{}
+	for {
+		if v, _ := mem.GetUint8(UartBase + 5); v&UartLsrDr == 0 {
+			break
+		}
+		if len(u.fifo) > 16 {
+			t.Fatal(len(u.fifo))
+		}
+		b, _ := mem.GetUint8(UartBase)
+		r = append(r, b)
+	}
+	if string(r) != "abcdefghijklmnopqrst" || u.c != nil {
+		t.Fatal(string(r))
+	}
+}
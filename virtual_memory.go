@@ -0,0 +1,187 @@
+package rv64
+
+// https://github.com/riscv/riscv-isa-manual/releases/download/Priv-v1.12/riscv-privileged-20211203.pdf
+// Chapter 4.3, 4.4, 4.5, 4.6
+//
+// The satp register controls supervisor-mode address translation and protection. It holds the physical page number
+// (PPN) of the root page table, an address space identifier (ASID) and the MODE field, which selects the current
+// address-translation scheme.
+//
+// |63    60|59                 44|43                                                                       0|
+// | MODE   | ASID                | PPN                                                                      |
+//
+// Value  Name  Description
+// 0      Bare  No translation or protection.
+// 8      Sv39  Page-based 39-bit virtual addressing.
+// 9      Sv48  Page-based 48-bit virtual addressing.
+// 10     Sv57  Page-based 57-bit virtual addressing.
+//
+// A page table entry has the following layout, only PPN[0] to PPN[LEVELS-1] are meaningful for a given mode.
+//
+// |63|62 61|60      54|53    28|27    19|18    10|9 8|7|6|5|4|3|2|1|0|
+// | N| PBMT| Reserved | PPN[2] | PPN[1] | PPN[0] |RSW|D|A|G|U|X|W|R|V|
+//
+// The emulator does not model privilege levels, so translation is active whenever satp selects a paging mode.
+
+const (
+	SatpModeBare = 0
+	SatpModeSv39 = 8
+	SatpModeSv48 = 9
+	SatpModeSv57 = 10
+)
+
+const (
+	PteV = 1 << 0 // Valid
+	PteR = 1 << 1 // Readable
+	PteW = 1 << 2 // Writable
+	PteX = 1 << 3 // Executable
+	PteU = 1 << 4 // User
+	PteG = 1 << 5 // Global
+	PteA = 1 << 6 // Accessed
+	PteD = 1 << 7 // Dirty
+)
+
+const (
+	AccessFetch = 0
+	AccessLoad  = 1
+	AccessStore = 2
+)
+
+// SatpLevels returns the number of page table levels used by a satp MODE, or 0 if the mode does no translation.
+func SatpLevels(mode uint64) uint64 {
+	switch mode {
+	case SatpModeSv39:
+		return 3
+	case SatpModeSv48:
+		return 4
+	case SatpModeSv57:
+		return 5
+	}
+	return 0
+}
+
+func pageFault(access uint64) error {
+	switch access {
+	case AccessFetch:
+		return ErrInstructionPageFault
+	case AccessLoad:
+		return ErrLoadPageFault
+	}
+	return ErrStorePageFault
+}
+
+// Translate converts a virtual address to a physical address following the algorithm described in chapter 4.3.2 of
+// the privileged specification.
+func (c *CPU) Translate(va uint64, access uint64) (uint64, error) {
+	satp := c.GetCSR().Get(CSRsatp)
+	levels := SatpLevels(InstructionPart(satp, 60, 63))
+	if levels == 0 {
+		return va, nil
+	}
+	// Instruction fetch addresses and load and store effective addresses, which are 64 bits, must have bits 63–VALEN
+	// all equal to bit VALEN-1, or else a page-fault exception will occur.
+	valen := 12 + 9*levels
+	if SignExtend(va, valen-1) != va {
+		return 0, pageFault(access)
+	}
+	mem := &Memory{Fasten: c.fasten}
+	a := InstructionPart(satp, 0, 43) << 12
+	for i := int(levels) - 1; i >= 0; i-- {
+		vpn := InstructionPart(va, 12+9*uint64(i), 20+9*uint64(i))
+		pte, err := mem.GetUint64(a + vpn*8)
+		if err != nil {
+			return 0, err
+		}
+		if pte&PteV == 0 || (pte&PteR == 0 && pte&PteW != 0) || InstructionPart(pte, 54, 63) != 0 {
+			return 0, pageFault(access)
+		}
+		ppn := InstructionPart(pte, 10, 53)
+		if pte&(PteR|PteX) == 0 {
+			a = ppn << 12
+			continue
+		}
+		switch access {
+		case AccessFetch:
+			if pte&PteX == 0 {
+				return 0, pageFault(access)
+			}
+		case AccessLoad:
+			if pte&PteR == 0 {
+				return 0, pageFault(access)
+			}
+		case AccessStore:
+			if pte&PteW == 0 {
+				return 0, pageFault(access)
+			}
+		}
+		// A misaligned superpage.
+		mask := uint64(1)<<(9*uint64(i)) - 1
+		if ppn&mask != 0 {
+			return 0, pageFault(access)
+		}
+		// The accessed and dirty bits are not updated by the emulator, software must set them in advance.
+		if pte&PteA == 0 || (access == AccessStore && pte&PteD == 0) {
+			return 0, pageFault(access)
+		}
+		return (ppn|InstructionPart(va, 12, 11+9*uint64(i)))<<12 | InstructionPart(va, 0, 11), nil
+	}
+	return 0, pageFault(access)
+}
+
+// Paging is a Fasten that translates every virtual address through the page table selected by satp before accessing
+// the physical memory of the CPU.
+type Paging struct {
+	cpu   *CPU
+	fetch bool
+}
+
+func (p *Paging) Get(a uint64) (byte, error) {
+	access := uint64(AccessLoad)
+	if p.fetch {
+		access = AccessFetch
+	}
+	r, err := p.cpu.Translate(a, access)
+	if err != nil {
+		return 0x00, err
+	}
+	return p.cpu.physical(p.fetch).Get(r)
+}
+
+func (p *Paging) Set(a uint64, v byte) error {
+	r, err := p.cpu.Translate(a, AccessStore)
+	if err != nil {
+		return err
+	}
+	return p.cpu.physical(false).Set(r, v)
+}
+
+func (p *Paging) GetSized(a uint64, l uint64) (uint64, error) {
+	// An access crossing a page boundary is translated byte by byte.
+	if a&0xfff+l > 0x1000 {
+		return fastenGetBytes(p, a, l)
+	}
+	access := uint64(AccessLoad)
+	if p.fetch {
+		access = AccessFetch
+	}
+	r, err := p.cpu.Translate(a, access)
+	if err != nil {
+		return 0, err
+	}
+	return fastenGet(p.cpu.physical(p.fetch), r, l)
+}
+
+func (p *Paging) SetSized(a uint64, l uint64, v uint64) error {
+	if a&0xfff+l > 0x1000 {
+		return fastenSetBytes(p, a, l, v)
+	}
+	r, err := p.cpu.Translate(a, AccessStore)
+	if err != nil {
+		return err
+	}
+	return fastenSet(p.cpu.physical(false), r, l, v)
+}
+
+func (p *Paging) Len() uint64 {
+	return p.cpu.fasten.Len()
+}
@@ -0,0 +1,140 @@
+package rv64
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func newPagingCPU() *CPU {
+	c := NewCPU()
+	c.SetFasten(NewLinear(1024 * 1024))
+	c.SetCSR(NewCSRStandard())
+	return c
+}
+
+// pagingMap builds the page table for va in memory. Page tables are allocated from next, which is advanced. The leaf
+// is placed at the given level, a level greater than 0 makes a superpage.
+func pagingMap(c *CPU, root uint64, levels uint64, next *uint64, va uint64, pa uint64, level uint64, flags uint64) {
+	mem := &Memory{Fasten: c.fasten}
+	a := root
+	for i := levels - 1; i > level; i-- {
+		vpn := InstructionPart(va, 12+9*i, 20+9*i)
+		pte, _ := mem.GetUint64(a + vpn*8)
+		if pte&PteV == 0 {
+			pte = (*next>>12)<<10 | PteV
+			*next += 4096
+			mem.SetUint64(a+vpn*8, pte)
+		}
+		a = InstructionPart(pte, 10, 53) << 12
+	}
+	vpn := InstructionPart(va, 12+9*level, 20+9*level)
+	mem.SetUint64(a+vpn*8, (pa>>12)<<10|flags|PteV)
+}
+
+func TestPaging(t *testing.T) {
+	for _, mode := range []uint64{SatpModeSv39, SatpModeSv48, SatpModeSv57} {
+		c := newPagingCPU()
+		levels := SatpLevels(mode)
+		root := uint64(0x10000)
+		next := root + 4096
+		va := uint64(0x12345000) | 1<<(12+9*levels-2)
+		pagingMap(c, root, levels, &next, va, 0x80000, 0, PteR|PteW|PteA|PteD)
+		pagingMap(c, root, levels, &next, va+0x1000, 0x81000, 0, PteR|PteA)
+		pagingMap(c, root, levels, &next, va+0x2000, 0x82000, 0, PteX|PteA)
+		c.GetCSR().Set(CSRsatp, mode<<60|root>>12)
+
+		if err := c.GetMemory().SetUint64(va+8, 0x0102030405060708); err != nil {
+			t.Fatal(err)
+		}
+		v, _ := (&Memory{Fasten: c.fasten}).GetUint64(0x80008)
+		if v != 0x0102030405060708 {
+			t.Fatal(mode, v)
+		}
+		if v, err := c.GetMemory().GetUint64(va + 8); err != nil || v != 0x0102030405060708 {
+			t.Fatal(mode, v, err)
+		}
+		// A store to a read-only page.
+		if err := c.GetMemory().SetUint8(va+0x1000, 0); err != ErrStorePageFault {
+			t.Fatal(mode, err)
+		}
+		// A load from an execute-only page.
+		if _, err := c.GetMemory().GetUint8(va + 0x2000); err != ErrLoadPageFault {
+			t.Fatal(mode, err)
+		}
+		if _, err := c.GetMemoryFetch().GetUint8(va + 0x2000); err != nil {
+			t.Fatal(mode, err)
+		}
+		if _, err := c.GetMemoryFetch().GetUint8(va); err != ErrInstructionPageFault {
+			t.Fatal(mode, err)
+		}
+		// An unmapped page.
+		if _, err := c.GetMemory().GetUint8(va + 0x3000); err != ErrLoadPageFault {
+			t.Fatal(mode, err)
+		}
+		// A non-canonical address.
+		if _, err := c.GetMemory().GetUint8(va | 1<<(12+9*levels)); err != ErrLoadPageFault {
+			t.Fatal(mode, err)
+		}
+	}
+}
+
+func TestPagingSuperpage(t *testing.T) {
+	for _, mode := range []uint64{SatpModeSv39, SatpModeSv48, SatpModeSv57} {
+		c := newPagingCPU()
+		levels := SatpLevels(mode)
+		root := uint64(0x10000)
+		next := root + 4096
+		// A 2 MiB megapage.
+		pagingMap(c, root, levels, &next, 0x40000000, 0x00000000, 1, PteR|PteW|PteA|PteD)
+		// A misaligned megapage.
+		pagingMap(c, root, levels, &next, 0x40200000, 0x00201000, 1, PteR|PteW|PteA|PteD)
+		c.GetCSR().Set(CSRsatp, mode<<60|root>>12)
+		(&Memory{Fasten: c.fasten}).SetUint32(0x54320, 0xdeadbeef)
+		if v, err := c.GetMemory().GetUint32(0x40054320); err != nil || v != 0xdeadbeef {
+			t.Fatal(mode, v, err)
+		}
+		if _, err := c.GetMemory().GetUint32(0x40200000); err != ErrLoadPageFault {
+			t.Fatal(mode, err)
+		}
+	}
+}
+
+func TestPagingAccessedDirty(t *testing.T) {
+	c := newPagingCPU()
+	root := uint64(0x10000)
+	next := root + 4096
+	pagingMap(c, root, 3, &next, 0x1000, 0x80000, 0, PteR|PteW)
+	pagingMap(c, root, 3, &next, 0x2000, 0x81000, 0, PteR|PteW|PteA)
+	c.GetCSR().Set(CSRsatp, SatpModeSv39<<60|root>>12)
+	if _, err := c.GetMemory().GetUint8(0x1000); err != ErrLoadPageFault {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMemory().GetUint8(0x2000); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GetMemory().SetUint8(0x2000, 0); err != ErrStorePageFault {
+		t.Fatal(err)
+	}
+}
+
+func TestPagingWARL(t *testing.T) {
+	c := newPagingCPU()
+	c.SetPagingModes(1 << SatpModeSv39)
+	// csrrw zero, satp, a0
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, CSRsatp<<20|Ra0<<15|0b001<<12|Rzero<<7|0b1110011)
+	for _, e := range [][]uint64{
+		{SatpModeSv48<<60 | 0x10, 0},
+		{SatpModeSv39<<60 | 0x10, SatpModeSv39<<60 | 0x10},
+		{SatpModeSv57<<60 | 0x20, SatpModeSv39<<60 | 0x10},
+		{SatpModeBare << 60, SatpModeBare << 60},
+	} {
+		c.SetRegister(Ra0, e[0])
+		if _, err := c.PipelineExecute(data); err != nil {
+			t.Fatal(err)
+		}
+		if c.GetCSR().Get(CSRsatp) != e[1] {
+			t.Fatal(e, c.GetCSR().Get(CSRsatp))
+		}
+	}
+}
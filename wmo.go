@@ -0,0 +1,257 @@
+package rv64
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+// RVWMO memory model checker.
+//
+// Every hart of the machine gets a store buffer. A store is performed in the buffer of its hart, and becomes visible
+// to the other harts later, when it drains to the shared memory. Loads read the youngest buffered store to the same
+// bytes, and the shared memory otherwise. Stores drain in any order, except that stores to overlapping bytes drain in
+// program order. This models the store-to-load and store-to-store reorderings of RVWMO. Loads are performed in
+// program order, so load reorderings are not explored.
+//
+// Ordering instructions constrain the drains:
+//
+//   - A fence whose predecessor set contains W and whose successor set contains R drains the buffer. When the
+//     successor set contains only W, as for fence w,w and fence.tso, the stores before the fence drain before the
+//     stores after it.
+//   - An AMO, LR or SC with the rl bit drains the buffer before it is performed. Without rl, only the buffered stores
+//     up to the last one overlapping the accessed bytes drain. The atomic instruction itself accesses the shared memory
+//     directly. The aq bit needs nothing more, since the loads and stores that follow are not performed early.
+
+type wmoStore struct {
+	a     uint64
+	l     uint64
+	v     uint64
+	epoch uint64
+}
+
+// wmoFasten is the view of the shared memory of a hart with a store buffer.
+type wmoFasten struct {
+	Fasten
+	buffer []wmoStore
+	epoch  uint64
+	direct bool
+}
+
+func (w *wmoFasten) overlap(a uint64, l uint64) int {
+	for i := len(w.buffer) - 1; i >= 0; i-- {
+		e := w.buffer[i]
+		if a < e.a+e.l && e.a < a+l {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *wmoFasten) Get(a uint64) (byte, error) {
+	if i := w.overlap(a, 1); i >= 0 {
+		e := w.buffer[i]
+		return byte(e.v >> (8 * (a - e.a))), nil
+	}
+	return w.Fasten.Get(a)
+}
+
+func (w *wmoFasten) Set(a uint64, v byte) error {
+	return w.SetSized(a, 1, uint64(v))
+}
+
+func (w *wmoFasten) GetSized(a uint64, l uint64) (uint64, error) {
+	if w.overlap(a, l) < 0 {
+		return fastenGet(w.Fasten, a, l)
+	}
+	return fastenGetBytes(w, a, l)
+}
+
+func (w *wmoFasten) SetSized(a uint64, l uint64, v uint64) error {
+	if w.direct {
+		return fastenSet(w.Fasten, a, l, v)
+	}
+	// The access faults now if the address is invalid, not when it drains.
+	if _, err := fastenGetBytes(w.Fasten, a, l); err != nil {
+		return err
+	}
+	w.buffer = append(w.buffer, wmoStore{a: a, l: l, v: v, epoch: w.epoch})
+	return nil
+}
+
+// drainable reports whether the i-th buffered store may drain.
+func (w *wmoFasten) drainable(i int) bool {
+	e := w.buffer[i]
+	for _, f := range w.buffer[:i] {
+		if f.epoch < e.epoch || e.a < f.a+f.l && f.a < e.a+e.l {
+			return false
+		}
+	}
+	return true
+}
+
+// drain writes the i-th buffered store to the shared memory.
+func (w *wmoFasten) drain(i int) {
+	e := w.buffer[i]
+	w.buffer = append(w.buffer[:i:i], w.buffer[i+1:]...)
+	if err := fastenSet(w.Fasten, e.a, e.l, e.v); err != nil {
+		Panicln(err)
+	}
+}
+
+// drainTo drains the first n buffered stores in program order.
+func (w *wmoFasten) drainTo(n int) {
+	for ; n > 0; n-- {
+		w.drain(0)
+	}
+}
+
+// wmoEvent either steps a hart, or drains one of its buffered stores when drain is not negative.
+type wmoEvent struct {
+	hart  int
+	drain int
+}
+
+// wmoStep executes an instruction of the hart c, after draining the stores it is ordered after.
+func wmoStep(c *CPU) {
+	w := c.fasten.(*wmoFasten)
+	mem := c.GetMemoryFetch()
+	i, err := mem.GetUint16(c.GetPC())
+	if err != nil {
+		Panicln(err)
+	}
+	if i&0b11 == 0b11 {
+		n, err := mem.GetUint32(c.GetPC())
+		if err != nil {
+			Panicln(err)
+		}
+		inst := uint64(n)
+		switch InstructionPart(inst, 0, 6) {
+		case 0b0001111:
+			if InstructionPart(inst, 12, 14) != 0b000 {
+				break
+			}
+			pred := InstructionPart(inst, 24, 27)
+			succ := InstructionPart(inst, 20, 23)
+			switch {
+			case pred&0b0001 == 0:
+			case succ&0b0010 != 0 && InstructionPart(inst, 28, 31) != 0b1000:
+				w.drainTo(len(w.buffer))
+			case succ != 0:
+				w.epoch++
+			}
+		case 0b0101111:
+			if InstructionPart(inst, 25, 25) != 0 {
+				w.drainTo(len(w.buffer))
+			} else {
+				a := c.GetRegister(InstructionPart(inst, 15, 19))
+				if c.GetXLEN() == 32 {
+					a &= 0xffffffff
+				}
+				w.drainTo(w.overlap(a, 1<<InstructionPart(inst, 12, 14)) + 1)
+			}
+			w.direct = true
+			defer func() { w.direct = false }()
+		}
+	}
+	c.Step()
+}
+
+func wmoEvents(m *Machine) []wmoEvent {
+	r := []wmoEvent{}
+	for i, c := range m.GetHarts() {
+		if c.GetStatus() == StatusRunning {
+			r = append(r, wmoEvent{hart: i, drain: -1})
+		}
+		w := c.fasten.(*wmoFasten)
+		for j := range w.buffer {
+			if w.drainable(j) {
+				r = append(r, wmoEvent{hart: i, drain: j})
+			}
+		}
+	}
+	return r
+}
+
+// wmoKey summarizes the state of the machine, ignoring the counters.
+func wmoKey(m *Machine) [32]byte {
+	b := []byte{}
+	for _, c := range m.GetHarts() {
+		b = binary.LittleEndian.AppendUint64(b, c.pc)
+		b = binary.LittleEndian.AppendUint64(b, c.status)
+		b = binary.LittleEndian.AppendUint64(b, c.lraddr)
+		for i := 0; i < 32; i++ {
+			b = binary.LittleEndian.AppendUint64(b, c.reg0[i])
+			b = binary.LittleEndian.AppendUint64(b, c.reg1[i])
+			b = binary.LittleEndian.AppendUint64(b, c.reg1h[i])
+		}
+		w := c.fasten.(*wmoFasten)
+		b = binary.LittleEndian.AppendUint64(b, uint64(len(w.buffer)))
+		for _, e := range w.buffer {
+			b = binary.LittleEndian.AppendUint64(b, e.a)
+			b = binary.LittleEndian.AppendUint64(b, e.l)
+			b = binary.LittleEndian.AppendUint64(b, e.v)
+			b = binary.LittleEndian.AppendUint64(b, w.epoch-e.epoch)
+		}
+	}
+	for a := uint64(0); a < m.fasten.Len(); a++ {
+		v, _ := m.fasten.Get(a)
+		b = append(b, v)
+	}
+	return sha256.Sum256(b)
+}
+
+// ExploreRVWMO runs every execution of a litmus test allowed by the store buffer model described above, and returns
+// the sorted distinct outcomes. The function f builds the machine of the test in its initial state, it is called
+// again for every execution explored, and must always build the same machine. The harts run until they leave
+// StatusRunning, typically with an exit system call. The outcome of a finished execution, when every store has
+// drained, is described by the function outcome. An execution is abandoned after limit events, this bounds the
+// exploration of spin loops which do not return to an already visited state.
+//
+// The shared memory of the machine is part of the state compared between executions, keep it small.
+func ExploreRVWMO(f func() *Machine, outcome func(*Machine) string, limit int) []string {
+	seen := map[[32]byte]bool{}
+	outs := map[string]bool{}
+	build := func(path []wmoEvent) *Machine {
+		m := f()
+		for _, c := range m.GetHarts() {
+			c.SetFasten(&wmoFasten{Fasten: c.fasten})
+		}
+		for _, e := range path {
+			c := m.GetHart(uint64(e.hart))
+			if e.drain < 0 {
+				wmoStep(c)
+			} else {
+				c.fasten.(*wmoFasten).drain(e.drain)
+			}
+		}
+		return m
+	}
+	var walk func(path []wmoEvent)
+	walk = func(path []wmoEvent) {
+		m := build(path)
+		k := wmoKey(m)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		events := wmoEvents(m)
+		if len(events) == 0 {
+			outs[outcome(m)] = true
+			return
+		}
+		if len(path) >= limit {
+			return
+		}
+		for _, e := range events {
+			walk(append(path[:len(path):len(path)], e))
+		}
+	}
+	walk(nil)
+	r := []string{}
+	for k := range outs {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
@@ -0,0 +1,148 @@
+package rv64
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func encodeFence(fm uint64, pred uint64, succ uint64) uint64 {
+	return fm<<28 | pred<<24 | succ<<20 | 0b0001111
+}
+
+// newLitmusMachine returns a machine whose hart i runs progs[i] from address 0x100*i, followed by an exit. Every hart
+// has x = 0x300 in a1 and y = 0x308 in a2, and 1 in t1.
+func newLitmusMachine(progs ...[]uint64) *Machine {
+	m := NewMachine(uint64(len(progs)))
+	m.SetFasten(NewLinear(0x400))
+	m.SetSystem(NewSystemStandard())
+	for i, prog := range progs {
+		prog = append(prog, addi(Ra7, Rzero, sysExit), encodeI(0b1110011, 0b000, 0, Rzero, Rzero))
+		c := m.GetHart(uint64(i))
+		for j, e := range prog {
+			c.GetMemory().SetUint32(uint64(0x100*i+4*j), uint32(e))
+		}
+		c.SetPC(uint64(0x100 * i))
+		c.SetRegister(Ra1, 0x300)
+		c.SetRegister(Ra2, 0x308)
+		c.SetRegister(Rt1, 1)
+	}
+	return m
+}
+
+// registers describes an outcome by the register r of the harts h.
+func registers(h []uint64, r []uint64) func(*Machine) string {
+	return func(m *Machine) string {
+		s := fmt.Sprint(m.GetHart(h[0]).GetRegister(r[0]))
+		for i := 1; i < len(h); i++ {
+			s += fmt.Sprint(" ", m.GetHart(h[i]).GetRegister(r[i]))
+		}
+		return s
+	}
+}
+
+func TestRVWMOStoreBuffering(t *testing.T) {
+	for _, e := range []struct {
+		fence []uint64
+		outs  []string
+	}{
+		{nil, []string{"0 0", "0 1", "1 0", "1 1"}},
+		{[]uint64{encodeFence(0, 0b0011, 0b0011)}, []string{"0 1", "1 0", "1 1"}},
+		// fence.tso does not order a store before a later load.
+		{[]uint64{encodeFence(0b1000, 0b0011, 0b0011)}, []string{"0 0", "0 1", "1 0", "1 1"}},
+	} {
+		f := func() *Machine {
+			return newLitmusMachine(
+				append(append([]uint64{encodeS(0b010, Ra1, Rt1, 0)}, e.fence...),
+					encodeI(0b0000011, 0b010, 0, Ra0, Ra2)), // sw t1, 0(a1); lw a0, 0(a2)
+				append(append([]uint64{encodeS(0b010, Ra2, Rt1, 0)}, e.fence...),
+					encodeI(0b0000011, 0b010, 0, Ra0, Ra1)), // sw t1, 0(a2); lw a0, 0(a1)
+			)
+		}
+		if r := ExploreRVWMO(f, registers([]uint64{0, 1}, []uint64{Ra0, Ra0}), 64); !reflect.DeepEqual(r, e.outs) {
+			t.Fatal(r)
+		}
+	}
+}
+
+func TestRVWMOMessagePassing(t *testing.T) {
+	const amo = 0b0101111
+	for _, e := range []struct {
+		flag []uint64
+		outs []string
+	}{
+		{
+			[]uint64{encodeS(0b010, Ra2, Rt1, 0)},
+			[]string{"0 0", "0 1", "1 0", "1 1"},
+		},
+		{
+			[]uint64{encodeFence(0, 0b0001, 0b0001), encodeS(0b010, Ra2, Rt1, 0)},
+			[]string{"0 0", "0 1", "1 1"},
+		},
+		{
+			[]uint64{encodeFence(0b1000, 0b0011, 0b0011), encodeS(0b010, Ra2, Rt1, 0)},
+			[]string{"0 0", "0 1", "1 1"},
+		},
+		{
+			[]uint64{encodeR(amo, 0b010, 0b00001<<2|1, Rzero, Ra2, Rt1)}, // amoswap.w.rl zero, t1, (a2)
+			[]string{"0 0", "0 1", "1 1"},
+		},
+		{
+			[]uint64{encodeR(amo, 0b010, 0b00001<<2|2, Rzero, Ra2, Rt1)}, // amoswap.w.aq zero, t1, (a2)
+			[]string{"0 0", "0 1", "1 0", "1 1"},
+		},
+	} {
+		f := func() *Machine {
+			return newLitmusMachine(
+				append([]uint64{encodeS(0b010, Ra1, Rt1, 0)}, e.flag...), // sw t1, 0(a1); flag
+				[]uint64{
+					encodeI(0b0000011, 0b010, 0, Ra0, Ra2), // lw a0, 0(a2)
+					encodeI(0b0000011, 0b010, 0, Ra1, Ra1), // lw a1, 0(a1)
+				},
+			)
+		}
+		if r := ExploreRVWMO(f, registers([]uint64{1, 1}, []uint64{Ra0, Ra1}), 64); !reflect.DeepEqual(r, e.outs) {
+			t.Fatal(r)
+		}
+	}
+}
+
+func TestRVWMOSpinlock(t *testing.T) {
+	const amo = 0b0101111
+	counter := func(m *Machine) string {
+		v, _ := fastenGet(m.GetFasten(), 0x308, 4)
+		return fmt.Sprint(v)
+	}
+	for _, e := range []struct {
+		unlock uint64
+		outs   []string
+	}{
+		{encodeR(amo, 0b010, 0b00001<<2|1, Rzero, Ra1, Rzero), []string{"2"}}, // amoswap.w.rl zero, zero, (a1)
+		{encodeS(0b010, Ra1, Rzero, 0), []string{"1", "2"}},                   // sw zero, 0(a1)
+	} {
+		prog := []uint64{
+			encodeR(amo, 0b010, 0b00001<<2|2, Rt0, Ra1, Rt1), // amoswap.w.aq t0, t1, (a1)
+			encodeB(0b001, Rt0, Rzero, -4),                   // bnez t0, lock
+			encodeI(0b0000011, 0b010, 0, Rt2, Ra2),           // lw t2, 0(a2)
+			addi(Rt2, Rt2, 1),                                // addi t2, t2, 1
+			encodeS(0b010, Ra2, Rt2, 0),                      // sw t2, 0(a2)
+			e.unlock,
+		}
+		f := func() *Machine { return newLitmusMachine(prog, prog) }
+		if r := ExploreRVWMO(f, counter, 128); !reflect.DeepEqual(r, e.outs) {
+			t.Fatal(r)
+		}
+	}
+}
+
+func TestRVWMOKey(t *testing.T) {
+	m := newLitmusMachine(nil)
+	c := m.GetHart(0)
+	c.SetFasten(&wmoFasten{Fasten: c.fasten})
+	k := wmoKey(m)
+	// The upper half of a Q register is part of the state.
+	c.reg1h[1] ^= 1
+	if wmoKey(m) == k {
+		t.FailNow()
+	}
+}